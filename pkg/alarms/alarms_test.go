@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alarms_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/alarms"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+type testBuffer struct {
+	bytes.Buffer
+}
+
+func (b *testBuffer) Close() error {
+	return nil
+}
+
+type testOutput struct {
+	state  string
+	terror int64
+}
+
+func (t *testOutput) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{{
+		ID: "dpll/time-error",
+		Data: map[string]any{
+			"timestamp": "1700000000.0",
+			"eecstate":  t.state,
+			"terror":    t.terror,
+		},
+	}}, nil
+}
+
+type writtenEvent struct {
+	ID   string       `json:"id"`
+	Data alarms.Event `json:"data"`
+}
+
+func readEvents(buffer *testBuffer) []writtenEvent {
+	events := make([]writtenEvent, 0)
+
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		event := writtenEvent{}
+		Expect(json.Unmarshal([]byte(line), &event)).To(Succeed())
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func newEngine(buffer *testBuffer, expressions ...string) *alarms.Engine {
+	rules, err := alarms.ParseRules(expressions)
+	Expect(err).NotTo(HaveOccurred())
+
+	return alarms.NewEngine(rules, callbacks.NewFileCallback(buffer, callbacks.AnalyserJSON))
+}
+
+var _ = Describe("ParseRule", func() {
+	It("should accept valid rules", func() {
+		for _, expression := range []string{
+			"dpll/time-error.terror abs> 50 for 3",
+			"dpll/time-error.eecstate != locked",
+			"gnss/time-error.state < 3",
+			"phc/gm-settings.clock_class changes",
+		} {
+			rule, err := alarms.ParseRule(expression)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rule.String()).To(Equal(expression))
+		}
+	})
+
+	It("should reject invalid rules", func() {
+		for _, expression := range []string{
+			"terror > 5",
+			"dpll/time-error.terror ~ 5",
+			"dpll/time-error.terror >",
+			"dpll/time-error.terror abs> locked",
+			"dpll/time-error.terror > 5 for 0",
+			"dpll/time-error.terror > 5 until 3",
+		} {
+			_, err := alarms.ParseRule(expression)
+			Expect(err).To(HaveOccurred(), expression)
+		}
+	})
+})
+
+var _ = Describe("Engine", func() {
+	var buffer *testBuffer
+
+	BeforeEach(func() {
+		buffer = &testBuffer{}
+	})
+
+	When("a value is out of bounds for enough samples", func() {
+		It("should fire once and clear when it recovers", func() {
+			engine := newEngine(buffer, "dpll/time-error.terror abs> 50 for 2")
+
+			for _, terror := range []int64{10, -60, 20, -60, 70, 80, 5} {
+				engine.Observe(&testOutput{state: "locked", terror: terror}, "test")
+			}
+
+			events := readEvents(buffer)
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].ID).To(Equal("alarm/firing"))
+			Expect(events[0].Data.Value).To(Equal("70"))
+			Expect(events[0].Data.SourceTimestamp).To(Equal("1700000000.0"))
+			Expect(events[1].ID).To(Equal("alarm/cleared"))
+			Expect(events[1].Data.Value).To(Equal("5"))
+			Expect(engine.HasFired()).To(BeTrue())
+		})
+	})
+
+	When("a string value is compared", func() {
+		It("should fire when it leaves the expected state", func() {
+			engine := newEngine(buffer, "dpll/time-error.eecstate != locked")
+
+			engine.Observe(&testOutput{state: "locked"}, "test")
+			Expect(engine.HasFired()).To(BeFalse())
+
+			engine.Observe(&testOutput{state: "holdover"}, "test")
+			events := readEvents(buffer)
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Data.Value).To(Equal("holdover"))
+		})
+	})
+
+	When("a rule watches for changes", func() {
+		It("should not fire on the first sample", func() {
+			engine := newEngine(buffer, "dpll/time-error.eecstate changes")
+
+			engine.Observe(&testOutput{state: "locked"}, "test")
+			engine.Observe(&testOutput{state: "locked"}, "test")
+			Expect(engine.HasFired()).To(BeFalse())
+
+			engine.Observe(&testOutput{state: "holdover"}, "test")
+			engine.Observe(&testOutput{state: "holdover"}, "test")
+
+			events := readEvents(buffer)
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].ID).To(Equal("alarm/firing"))
+			Expect(events[1].ID).To(Equal("alarm/cleared"))
+		})
+	})
+})
+
+func TestAlarms(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Alarms Suite")
+}
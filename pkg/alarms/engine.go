@@ -0,0 +1,241 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Alarms evaluates rules against collected values while a collection is running
+package alarms
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+const (
+	FiringState  = "firing"
+	ClearedState = "cleared"
+
+	alarmTag = "alarm"
+)
+
+// Event is emitted when an alarm starts firing or is cleared
+type Event struct {
+	Timestamp       string `json:"timestamp"`
+	SourceTimestamp string `json:"sourceTimestamp,omitempty"`
+	State           string `json:"state"`
+	Rule            string `json:"rule"`
+	RecordID        string `json:"recordId"`
+	Field           string `json:"field"`
+	Value           string `json:"value"`
+	Node            string `json:"node,omitempty"`
+	Interface       string `json:"interface,omitempty"`
+	Instance        int    `json:"instance"`
+	Samples         int    `json:"samples"`
+}
+
+// GetAnalyserFormat returns the json expected by the analysers
+func (event *Event) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted := callbacks.AnalyserFormatType{
+		ID:   "alarm/" + event.State,
+		Data: event,
+	}
+
+	return []*callbacks.AnalyserFormatType{&formatted}, nil
+}
+
+type ruleState struct {
+	lastValue string
+	hasLast   bool
+	breaches  int
+	firing    bool
+}
+
+// Engine evaluates rules against every output it observes and writes
+// an Event to its callback whenever an alarm starts firing or clears.
+type Engine struct {
+	callback callbacks.Callback
+	states   map[string]*ruleState
+	rules    []*Rule
+	mu       sync.Mutex
+	fired    bool
+}
+
+// ParseRules parses a list of rule expressions
+func ParseRules(expressions []string) ([]*Rule, error) {
+	rules := make([]*Rule, 0, len(expressions))
+
+	for _, expression := range expressions {
+		rule, err := ParseRule(expression)
+		if err != nil {
+			return rules, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func NewEngine(rules []*Rule, callback callbacks.Callback) *Engine {
+	return &Engine{
+		callback: callback,
+		rules:    rules,
+		states:   make(map[string]*ruleState),
+	}
+}
+
+// HasFired returns true if any alarm has fired since the engine was created
+func (engine *Engine) HasFired() bool {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+
+	return engine.fired
+}
+
+// recordFields returns the fields of the record data as they appear in the json output
+func recordFields(data any) (map[string]any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	fields := make(map[string]any)
+
+	err = json.Unmarshal(raw, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal record: %w", err)
+	}
+
+	return fields, nil
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (engine *Engine) evaluate(
+	rule *Rule,
+	record *callbacks.AnalyserFormatType,
+	instance int,
+	fields map[string]any,
+) *Event {
+	rawValue, ok := fields[rule.field]
+	if !ok {
+		return nil
+	}
+
+	value := formatValue(rawValue)
+	key := fmt.Sprintf("%s#%s/%s#%d", rule.expression, record.Node, record.Interface, instance)
+
+	state, ok := engine.states[key]
+	if !ok {
+		state = &ruleState{}
+		engine.states[key] = state
+	}
+
+	breached := rule.breached(value, state.lastValue, state.hasLast)
+	state.lastValue = value
+	state.hasLast = true
+
+	var newState string
+
+	if breached {
+		state.breaches++
+		if !state.firing && state.breaches >= rule.consecutive {
+			state.firing = true
+			engine.fired = true
+			newState = FiringState
+		}
+	} else {
+		if state.firing {
+			state.firing = false
+			newState = ClearedState
+		}
+
+		state.breaches = 0
+	}
+
+	if newState == "" {
+		return nil
+	}
+
+	event := &Event{
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		State:     newState,
+		Rule:      rule.expression,
+		RecordID:  rule.recordID,
+		Field:     rule.field,
+		Value:     value,
+		Node:      record.Node,
+		Interface: record.Interface,
+		Instance:  instance,
+		Samples:   rule.consecutive,
+	}
+
+	if ts, ok := fields["timestamp"].(string); ok {
+		event.SourceTimestamp = ts
+	}
+
+	return event
+}
+
+// Observe evaluates the rules against the AnalyserJSON records of output
+func (engine *Engine) Observe(output callbacks.OutputType, _ string) {
+	records, err := output.GetAnalyserFormat()
+	if err != nil {
+		log.Debugf("alarms could not get records from %T: %s", output, err.Error())
+		return
+	}
+
+	events := make([]*Event, 0)
+	instances := make(map[string]int)
+
+	engine.mu.Lock()
+
+	for _, record := range records {
+		instance := instances[record.ID]
+		instances[record.ID]++
+
+		var fields map[string]any
+
+		for _, rule := range engine.rules {
+			if rule.recordID != record.ID {
+				continue
+			}
+
+			if fields == nil {
+				fields, err = recordFields(record.Data)
+				if err != nil {
+					log.Warning(err)
+					break
+				}
+			}
+
+			if event := engine.evaluate(rule, record, instance, fields); event != nil {
+				events = append(events, event)
+			}
+		}
+	}
+
+	engine.mu.Unlock()
+
+	for _, event := range events {
+		log.Warnf("alarm %s: %s (value %s)", event.State, event.Rule, event.Value)
+
+		err := engine.callback.Call(event, alarmTag)
+		if err != nil {
+			log.Errorf("failed to write alarm event: %s", err.Error())
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alarms
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type operator string
+
+const (
+	opGreater        operator = ">"
+	opGreaterOrEqual operator = ">="
+	opLess           operator = "<"
+	opLessOrEqual    operator = "<="
+	opEqual          operator = "=="
+	opNotEqual       operator = "!="
+	opAbsGreater     operator = "abs>"
+	opChanges        operator = "changes"
+
+	forKeyword = "for"
+)
+
+var operators = map[operator]bool{
+	opGreater:        true,
+	opGreaterOrEqual: true,
+	opLess:           true,
+	opLessOrEqual:    true,
+	opEqual:          true,
+	opNotEqual:       true,
+	opAbsGreater:     true,
+	opChanges:        true,
+}
+
+// Rule describes when an alarm fires. It is written as
+//
+//	<record id>.<field> <operator> [<value>] [for <samples>]
+//
+// for example "dpll/time-error.terror abs> 50 for 3" fires once the
+// absolute DPLL time error has been over 50 for three consecutive samples.
+// The record id and field are those in the AnalyserJSON output.
+type Rule struct {
+	expression  string
+	recordID    string
+	field       string
+	op          operator
+	value       string
+	consecutive int
+}
+
+// ParseRule parses an alarm rule expression
+//
+//nolint:cyclop // allow this to be a little complicated
+func ParseRule(expression string) (*Rule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) < 2 { //nolint:mnd // a target and an operator
+		return nil, fmt.Errorf("alarm rule '%s' must have at least a target and an operator", expression)
+	}
+
+	dot := strings.LastIndex(parts[0], ".")
+	if dot <= 0 || dot == len(parts[0])-1 {
+		return nil, fmt.Errorf("alarm rule '%s' target must be in the form <record id>.<field>", expression)
+	}
+
+	rule := &Rule{
+		expression:  expression,
+		recordID:    parts[0][:dot],
+		field:       parts[0][dot+1:],
+		op:          operator(parts[1]),
+		consecutive: 1,
+	}
+
+	if !operators[rule.op] {
+		return nil, fmt.Errorf("alarm rule '%s' has unknown operator %s", expression, parts[1])
+	}
+
+	rest := parts[2:]
+
+	if rule.op != opChanges {
+		if len(rest) == 0 {
+			return nil, fmt.Errorf("alarm rule '%s' is missing a value to compare against", expression)
+		}
+
+		rule.value = rest[0]
+		rest = rest[1:]
+	}
+
+	if rule.op == opAbsGreater {
+		if _, err := strconv.ParseFloat(rule.value, 64); err != nil {
+			return nil, fmt.Errorf("alarm rule '%s' must compare against a number: %w", expression, err)
+		}
+	}
+
+	switch {
+	case len(rest) == 0:
+	case len(rest) == 2 && rest[0] == forKeyword: //nolint:mnd // for and a count
+		count, err := strconv.Atoi(rest[1])
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("alarm rule '%s' must have a positive number of samples", expression)
+		}
+
+		rule.consecutive = count
+	default:
+		return nil, fmt.Errorf("alarm rule '%s' has unexpected trailing values %v", expression, rest)
+	}
+
+	return rule, nil
+}
+
+func (rule *Rule) String() string {
+	return rule.expression
+}
+
+// compare returns the result of comparing two values, numerically when both are numbers
+func compare(op operator, actual, expected string) bool {
+	actualNum, actualErr := strconv.ParseFloat(actual, 64)
+	expectedNum, expectedErr := strconv.ParseFloat(expected, 64)
+
+	if actualErr != nil || expectedErr != nil {
+		switch op { //nolint:exhaustive // only equality makes sense for strings
+		case opEqual:
+			return actual == expected
+		case opNotEqual:
+			return actual != expected
+		default:
+			return false
+		}
+	}
+
+	switch op {
+	case opGreater:
+		return actualNum > expectedNum
+	case opGreaterOrEqual:
+		return actualNum >= expectedNum
+	case opLess:
+		return actualNum < expectedNum
+	case opLessOrEqual:
+		return actualNum <= expectedNum
+	case opEqual:
+		return actualNum == expectedNum
+	case opNotEqual:
+		return actualNum != expectedNum
+	case opAbsGreater:
+		return math.Abs(actualNum) > expectedNum
+	case opChanges:
+		return false
+	default:
+		return false
+	}
+}
+
+// breached returns true if the value breaks the rule, previous is the last value seen
+func (rule *Rule) breached(value, previous string, hasPrevious bool) bool {
+	if rule.op == opChanges {
+		return hasPrevious && value != previous
+	}
+
+	return compare(rule.op, value, rule.value)
+}
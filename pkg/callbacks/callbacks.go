@@ -8,6 +8,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
 const (
@@ -27,9 +32,17 @@ const (
 	AnalyserJSON
 )
 
+var outputFormatNames = map[string]OutputFormat{
+	"raw":      Raw,
+	"analyser": AnalyserJSON,
+}
+
 type AnalyserFormatType struct {
-	Data any    `json:"data"`
-	ID   string `json:"id"`
+	Data      any           `json:"data"`
+	Timing    *SampleTiming `json:"timing,omitempty"`
+	ID        string        `json:"id"`
+	Node      string        `json:"node,omitempty"`
+	Interface string        `json:"interface,omitempty"`
 }
 
 type OutputType interface {
@@ -45,9 +58,20 @@ func getFormattedOutput(c Callback, output OutputType, tag string) ([]byte, erro
 			return []byte{}, fmt.Errorf("failed to marshal %T %w", output, err)
 		}
 
-		return fmt.Appendf(nil, "%T:%s, %s", output, tag, line), nil
+		line, err = withSampleTiming(output, line)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		// Keep the type of the collected value rather than the wrapper
+		var outputType any = output
+		if targeted, ok := output.(*TargetedOutput); ok {
+			outputType = targeted.Output
+		}
+
+		return fmt.Appendf(nil, "%T:%s, %s", outputType, tag, line), nil
 	case AnalyserJSON:
-		outputs, err := output.GetAnalyserFormat()
+		outputs, err := getAnalyserFormat(output)
 		if err != nil {
 			return []byte{}, fmt.Errorf("failed to get AnalyserFormat %w", err)
 		}
@@ -76,19 +100,30 @@ func getFormattedOutput(c Callback, output OutputType, tag string) ([]byte, erro
 
 // Returns the filehandle for callback
 // if filename is empty or "-" it will output to stdout otherwise it will
-// write to a file of the given name
-func GetFileHandle(filename string) (io.WriteCloser, error) {
+// write to a file of the given name, rotating and compressing it as set in options
+func GetFileHandle(filename string, options FileOptions) (io.WriteCloser, error) {
 	var (
 		fileHandle io.WriteCloser
 		err        error
 	)
-	if filename == "-" || filename == "" {
+
+	switch {
+	case filename == "-" || filename == "":
+		if !options.isPlain() {
+			log.Warning("output to stdout is not rotated or compressed")
+		}
+
 		fileHandle = os.Stdout
-	} else {
+	case options.isPlain():
 		fileHandle, err = os.OpenFile(filename, os.O_CREATE|os.O_WRONLY, logFilePermissions)
 		if err != nil {
 			return fileHandle, fmt.Errorf("failed to open file: %w", err)
 		}
+	default:
+		fileHandle, err = NewRotatingFile(filename, options)
+		if err != nil {
+			return fileHandle, err
+		}
 	}
 
 	return fileHandle, nil
@@ -98,16 +133,55 @@ func NewFileCallback(fileHandle io.WriteCloser, format OutputFormat) FileCallBac
 	return FileCallBack{fileHandle: fileHandle, format: format}
 }
 
-// SetupCallback returns a FileCallback
-// if filename is empty or "-" it will output to stdout otherwise it will
-// write to a file of the given name
-func SetupCallback(filename string, format OutputFormat) (FileCallBack, error) {
-	fileHandle, err := GetFileHandle(filename)
-	if err != nil {
-		return FileCallBack{}, err
+// Sink is a destination for output in a given format
+type Sink struct {
+	Filename string
+	Format   OutputFormat
+}
+
+// ParseSink parses a sink in the form "<format>:<filename>"
+// where format is either raw or analyser
+func ParseSink(value string) (Sink, error) {
+	formatName, filename, found := strings.Cut(value, ":")
+	if !found || filename == "" {
+		return Sink{}, fmt.Errorf("output %s must be in the form <format>:<filename>", value)
+	}
+
+	format, ok := outputFormatNames[strings.ToLower(formatName)]
+	if !ok {
+		return Sink{}, fmt.Errorf("unknown output format %s, must be one of raw or analyser", formatName)
 	}
 
-	return NewFileCallback(fileHandle, format), nil
+	return Sink{Filename: filename, Format: format}, nil
+}
+
+// SetupCallback returns a callback which writes to every sink.
+// For each sink if filename is empty or "-" it will output to stdout
+// otherwise it will write to a file of the given name
+func SetupCallback(sinks []Sink, options FileOptions) (Callback, error) {
+	fileCallbacks := make([]Callback, 0, len(sinks))
+
+	for _, sink := range sinks {
+		fileHandle, err := GetFileHandle(sink.Filename, options)
+		if err != nil {
+			for _, c := range fileCallbacks {
+				c.CleanUp() //nolint:errcheck // we are already returning an error
+			}
+
+			return FileCallBack{}, err
+		}
+
+		fileCallbacks = append(fileCallbacks, NewFileCallback(fileHandle, sink.Format))
+	}
+
+	switch len(fileCallbacks) {
+	case 0:
+		return FileCallBack{}, errors.New("no outputs were given")
+	case 1:
+		return fileCallbacks[0], nil
+	default:
+		return NewFanOutCallback(fileCallbacks...), nil
+	}
 }
 
 type FileCallBack struct {
@@ -143,3 +217,133 @@ func (c FileCallBack) CleanUp() error {
 
 	return nil
 }
+
+// Observer is given every output passed to an ObservedCallback
+type Observer interface {
+	Observe(OutputType, string)
+}
+
+// ObservedCallback passes outputs on to another callback
+// and lets an observer see them once they have been handled
+type ObservedCallback struct {
+	callback Callback
+	observer Observer
+}
+
+func NewObservedCallback(callback Callback, observer Observer) ObservedCallback {
+	return ObservedCallback{callback: callback, observer: observer}
+}
+
+func (c ObservedCallback) Call(output OutputType, tag string) error {
+	err := c.callback.Call(output, tag)
+	c.observer.Observe(output, tag)
+
+	return err //nolint:wrapcheck // the error is already wrapped by the inner callback
+}
+
+func (c ObservedCallback) getFormat() OutputFormat {
+	return c.callback.getFormat()
+}
+
+func (c ObservedCallback) CleanUp() error {
+	return c.callback.CleanUp() //nolint:wrapcheck // the error is already wrapped by the inner callback
+}
+
+// FanOutCallback passes every output on to each of its callbacks
+// so that one run can write to several destinations in different formats
+type FanOutCallback struct {
+	callbacks []Callback
+}
+
+func NewFanOutCallback(callbacks ...Callback) FanOutCallback {
+	return FanOutCallback{callbacks: callbacks}
+}
+
+func (c FanOutCallback) Call(output OutputType, tag string) error {
+	errs := make([]error, 0)
+
+	for _, callback := range c.callbacks {
+		err := callback.Call(output, tag)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utils.MakeCompositeError("fan out callback failed", errs) //nolint:wrapcheck // this just combines errors
+}
+
+// getFormat returns the format of the first callback,
+// each callback formats its own output so this is not used for writing
+func (c FanOutCallback) getFormat() OutputFormat {
+	if len(c.callbacks) == 0 {
+		return Raw
+	}
+
+	return c.callbacks[0].getFormat()
+}
+
+func (c FanOutCallback) CleanUp() error {
+	errs := make([]error, 0)
+
+	for _, callback := range c.callbacks {
+		err := callback.CleanUp()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utils.MakeCompositeError("fan out callback failed to clean up", errs) //nolint:wrapcheck // this just combines errors
+}
+
+// TargetedOutput labels an output with the node and interface it was collected from
+type TargetedOutput struct {
+	Output    OutputType `json:"data"`
+	Node      string     `json:"node"`
+	Interface string     `json:"interface"`
+}
+
+func (t *TargetedOutput) GetAnalyserFormat() ([]*AnalyserFormatType, error) {
+	outputs, err := getAnalyserFormat(t.Output)
+	if err != nil {
+		return outputs, err //nolint:wrapcheck // the error is wrapped by the caller
+	}
+
+	labelled := make([]*AnalyserFormatType, 0, len(outputs))
+
+	for _, output := range outputs {
+		labelledOutput := *output
+		labelledOutput.Node = t.Node
+		labelledOutput.Interface = t.Interface
+		labelled = append(labelled, &labelledOutput)
+	}
+
+	return labelled, nil
+}
+
+// TargetedCallback labels every output with a node and interface
+// so that outputs from several targets can share a callback
+type TargetedCallback struct {
+	callback Callback
+	node     string
+	iface    string
+}
+
+func NewTargetedCallback(callback Callback, node, iface string) TargetedCallback {
+	return TargetedCallback{callback: callback, node: node, iface: iface}
+}
+
+func (c TargetedCallback) Call(output OutputType, tag string) error {
+	return c.callback.Call( //nolint:wrapcheck // the error is already wrapped by the inner callback
+		&TargetedOutput{Output: output, Node: c.node, Interface: c.iface},
+		tag,
+	)
+}
+
+func (c TargetedCallback) getFormat() OutputFormat {
+	return c.callback.getFormat()
+}
+
+// CleanUp does nothing as the inner callback is shared with other targets
+func (c TargetedCallback) CleanUp() error {
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"errors"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -46,6 +47,23 @@ func (t *testOutputType) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, e
 	return []*callbacks.AnalyserFormatType{&fomatted}, nil
 }
 
+type timedOutputType struct {
+	callbacks.Timed
+
+	testOutputType
+}
+
+// timedSelfOutputType uses itself as the data of its analyser format
+type timedSelfOutputType struct {
+	callbacks.Timed
+
+	Msg string `json:"msg"`
+}
+
+func (t *timedSelfOutputType) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{{ID: "testOutput", Data: t}}, nil
+}
+
 var _ = Describe("Callbacks", func() {
 	var mockedFile *testFile
 
@@ -83,6 +101,77 @@ var _ = Describe("Callbacks", func() {
 			Expect(mockedFile.open).To(BeFalse())
 		})
 	})
+	When("A TargetedCallback is called", func() {
+		It("should label JSON output with the node and interface", func() {
+			callback := callbacks.NewTargetedCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.AnalyserJSON), "node-1", "ens1f0",
+			)
+			err := callback.Call(&testOutputType{Msg: "This is a test line"}, "testOut")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockedFile.ReadString('\n')).To(Equal(
+				"{\"data\":[\"Hello\"],\"id\":\"testOutput\",\"node\":\"node-1\",\"interface\":\"ens1f0\"}\n",
+			))
+		})
+		It("should label raw output with the node and interface", func() {
+			callback := callbacks.NewTargetedCallback(callbacks.NewFileCallback(mockedFile, callbacks.Raw), "node-1", "ens1f0")
+			err := callback.Call(&testOutputType{Msg: "This is a test line"}, "testOut")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockedFile.ReadString('\n')).To(Equal(
+				"*callbacks_test.testOutputType:testOut, " +
+					"{\"data\":{\"msg\":\"This is a test line\"},\"node\":\"node-1\",\"interface\":\"ens1f0\"}\n",
+			))
+		})
+		It("should not close the shared callback", func() {
+			callback := callbacks.NewTargetedCallback(callbacks.NewFileCallback(mockedFile, callbacks.Raw), "node-1", "ens1f0")
+			Expect(callback.CleanUp()).To(Succeed())
+			Expect(mockedFile.open).To(BeTrue())
+		})
+	})
+	When("A FileCallback is called with a timed output", func() {
+		It("should add the sample timing to every record", func() {
+			skew := -3 * time.Microsecond
+			out := &timedOutputType{testOutputType: testOutputType{Msg: "This is a test line"}}
+			out.SetSampleTiming(&callbacks.SampleTiming{
+				ClockSkew:   &skew,
+				ExecLatency: 2 * time.Millisecond,
+				PollDelay:   time.Millisecond,
+				Unreliable:  true,
+			})
+			callback := callbacks.NewTargetedCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.AnalyserJSON), "node-1", "ens1f0",
+			)
+			err := callback.Call(out, "testOut")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockedFile.ReadString('\n')).To(Equal(
+				"{\"data\":[\"Hello\"]," +
+					"\"timing\":{\"clockSkew\":-3000,\"execLatency\":2000000,\"pollDelay\":1000000,\"unreliable\":true}," +
+					"\"id\":\"testOutput\",\"node\":\"node-1\",\"interface\":\"ens1f0\"}\n",
+			))
+		})
+		It("should add the sample timing once to the analyser format using the output as its data", func() {
+			out := &timedSelfOutputType{Msg: "This is a test line"}
+			out.SetSampleTiming(&callbacks.SampleTiming{ExecLatency: 2 * time.Millisecond})
+			callback := callbacks.NewFileCallback(mockedFile, callbacks.AnalyserJSON)
+			err := callback.Call(out, "testOut")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockedFile.ReadString('\n')).To(Equal(
+				"{\"data\":{\"msg\":\"This is a test line\"}," +
+					"\"timing\":{\"execLatency\":2000000,\"pollDelay\":0},\"id\":\"testOutput\"}\n",
+			))
+		})
+		It("should add the sample timing once to the raw output", func() {
+			out := &timedOutputType{testOutputType: testOutputType{Msg: "This is a test line"}}
+			out.SetSampleTiming(&callbacks.SampleTiming{ExecLatency: 2 * time.Millisecond})
+			callback := callbacks.NewTargetedCallback(callbacks.NewFileCallback(mockedFile, callbacks.Raw), "node-1", "ens1f0")
+			err := callback.Call(out, "testOut")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockedFile.ReadString('\n')).To(Equal(
+				"*callbacks_test.timedOutputType:testOut, " +
+					"{\"data\":{\"msg\":\"This is a test line\"},\"node\":\"node-1\",\"interface\":\"ens1f0\"," +
+					"\"timing\":{\"execLatency\":2000000,\"pollDelay\":0}}\n",
+			))
+		})
+	})
 
 })
 
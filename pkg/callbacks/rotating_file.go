@@ -0,0 +1,265 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	log "github.com/sirupsen/logrus"
+)
+
+type Compression int
+
+const (
+	NoCompression Compression = iota
+	Gzip
+	Zstd
+)
+
+var compressionNames = map[string]Compression{
+	"":     NoCompression,
+	"none": NoCompression,
+	"gzip": Gzip,
+	"zstd": Zstd,
+}
+
+// ParseCompression returns the Compression for a name (none, gzip or zstd)
+func ParseCompression(name string) (Compression, error) {
+	compression, ok := compressionNames[strings.ToLower(name)]
+	if !ok {
+		return NoCompression, fmt.Errorf("unknown compression %s, must be one of none, gzip or zstd", name)
+	}
+
+	return compression, nil
+}
+
+// extension is appended to the file name of a compressed file
+func (c Compression) extension() string {
+	switch c {
+	case Gzip:
+		return ".gz"
+	case Zstd:
+		return ".zst"
+	case NoCompression:
+		return ""
+	default:
+		return ""
+	}
+}
+
+// FileOptions controls how output files are written.
+// A MaxSize or MaxAge of zero means files will not be rotated on that basis.
+type FileOptions struct {
+	Compression Compression
+	MaxSize     int64
+	MaxAge      time.Duration
+}
+
+func (opts FileOptions) rotates() bool {
+	return opts.MaxSize > 0 || opts.MaxAge > 0
+}
+
+func (opts FileOptions) isPlain() bool {
+	return !opts.rotates() && opts.Compression == NoCompression
+}
+
+// segment is a single file being written, possibly through a compressor
+type segment struct {
+	file   *os.File
+	writer io.WriteCloser
+}
+
+func (seg *segment) Write(p []byte) (int, error) {
+	return seg.writer.Write(p) //nolint:wrapcheck // the error is wrapped by the caller
+}
+
+func (seg *segment) Close() error {
+	err := seg.writer.Close()
+	if err != nil {
+		seg.file.Close()
+		return fmt.Errorf("failed to flush %s: %w", seg.file.Name(), err)
+	}
+
+	if seg.writer != seg.file {
+		err = seg.file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to close %s: %w", seg.file.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+func openSegment(filename string, compression Compression) (*segment, error) {
+	fileHandle, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, logFilePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	seg := &segment{file: fileHandle, writer: fileHandle}
+
+	switch compression {
+	case Gzip:
+		seg.writer = gzip.NewWriter(fileHandle)
+	case Zstd:
+		encoder, err := zstd.NewWriter(fileHandle)
+		if err != nil {
+			fileHandle.Close()
+			return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
+
+		seg.writer = encoder
+	case NoCompression:
+	}
+
+	return seg, nil
+}
+
+// RotatingFile writes to a series of files, starting a new one when the
+// current file has grown past MaxSize or has been open for longer than MaxAge.
+// Each write is kept whole in a single file so lines are never split.
+// MaxSize is counted in uncompressed bytes.
+type RotatingFile struct {
+	opened  time.Time
+	current *segment
+	path    string
+	options FileOptions
+	written int64
+	index   int
+	mu      sync.Mutex
+}
+
+// NewRotatingFile opens the first file for path, when rotating the numbering
+// continues after any files left by a previous run so they are not overwritten
+func NewRotatingFile(path string, options FileOptions) (*RotatingFile, error) {
+	rotating := &RotatingFile{path: path, options: options}
+
+	if options.rotates() {
+		index, err := nextSegmentIndex(path)
+		if err != nil {
+			return nil, err
+		}
+
+		rotating.index = index
+	}
+
+	err := rotating.openNext()
+	if err != nil {
+		return nil, err
+	}
+
+	return rotating, nil
+}
+
+// nextSegmentIndex returns the number after the highest numbered file for path
+// with any compression, or zero if there are none
+func nextSegmentIndex(path string) (int, error) {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to list the existing files for %s: %w", path, err)
+	}
+
+	prefix := filepath.Base(path) + "."
+	next := 0
+
+	for _, entry := range entries {
+		suffix, found := strings.CutPrefix(entry.Name(), prefix)
+		if !found {
+			continue
+		}
+
+		for _, compression := range []Compression{Gzip, Zstd} {
+			suffix = strings.TrimSuffix(suffix, compression.extension())
+		}
+
+		index, err := strconv.Atoi(suffix)
+		if err == nil && index >= next {
+			next = index + 1
+		}
+	}
+
+	return next, nil
+}
+
+// segmentName returns the name of the current file, when rotating each file is numbered
+func (rf *RotatingFile) segmentName() string {
+	if !rf.options.rotates() {
+		return rf.path + rf.options.Compression.extension()
+	}
+
+	return fmt.Sprintf("%s.%d%s", rf.path, rf.index, rf.options.Compression.extension())
+}
+
+func (rf *RotatingFile) openNext() error {
+	seg, err := openSegment(rf.segmentName(), rf.options.Compression)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("writing output to %s", seg.file.Name())
+
+	rf.current = seg
+	rf.opened = time.Now()
+	rf.written = 0
+	rf.index++
+
+	return nil
+}
+
+func (rf *RotatingFile) shouldRotate(size int) bool {
+	if rf.written == 0 {
+		return false
+	}
+
+	if rf.options.MaxSize > 0 && rf.written+int64(size) > rf.options.MaxSize {
+		return true
+	}
+
+	return rf.options.MaxAge > 0 && time.Since(rf.opened) >= rf.options.MaxAge
+}
+
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.shouldRotate(len(p)) {
+		err := rf.current.Close()
+		if err != nil {
+			return 0, err
+		}
+
+		err = rf.openNext()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.current.Write(p)
+	rf.written += int64(n)
+
+	if err != nil {
+		return n, fmt.Errorf("failed to write to %s: %w", rf.current.file.Name(), err)
+	}
+
+	return n, nil
+}
+
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	return rf.current.Close()
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+func readGzip(filename string) string {
+	fileHandle, err := os.Open(filename)
+	Expect(err).NotTo(HaveOccurred())
+	defer fileHandle.Close()
+
+	reader, err := gzip.NewReader(fileHandle)
+	Expect(err).NotTo(HaveOccurred())
+
+	content, err := io.ReadAll(reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	return string(content)
+}
+
+var _ = Describe("RotatingFile", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	When("the size limit is reached", func() {
+		It("should start a new file without splitting writes", func() {
+			path := filepath.Join(dir, "out.log")
+			rotating, err := callbacks.NewRotatingFile(path, callbacks.FileOptions{
+				MaxSize:     10,
+				Compression: callbacks.Gzip,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, line := range []string{"line one\n", "line two\n", "a much longer line three\n"} {
+				_, err = rotating.Write([]byte(line))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(rotating.Close()).To(Succeed())
+
+			Expect(readGzip(path + ".0.gz")).To(Equal("line one\n"))
+			Expect(readGzip(path + ".1.gz")).To(Equal("line two\n"))
+			Expect(readGzip(path + ".2.gz")).To(Equal("a much longer line three\n"))
+		})
+	})
+
+	When("files remain from a previous run", func() {
+		It("should continue numbering after them", func() {
+			path := filepath.Join(dir, "out.log")
+			for _, name := range []string{"out.log.0", "out.log.3.gz", "out.log.backup", "out.log.7.old"} {
+				Expect(os.WriteFile(filepath.Join(dir, name), []byte("previous\n"), 0o600)).To(Succeed())
+			}
+
+			rotating, err := callbacks.NewRotatingFile(path, callbacks.FileOptions{MaxSize: 10})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = rotating.Write([]byte("new line\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rotating.Close()).To(Succeed())
+
+			Expect(os.ReadFile(filepath.Join(dir, "out.log.0"))).To(BeEquivalentTo("previous\n"))
+			Expect(os.ReadFile(filepath.Join(dir, "out.log.3.gz"))).To(BeEquivalentTo("previous\n"))
+			Expect(os.ReadFile(filepath.Join(dir, "out.log.4"))).To(BeEquivalentTo("new line\n"))
+		})
+	})
+
+	When("only compression is requested", func() {
+		It("should write a single compressed file", func() {
+			path := filepath.Join(dir, "out.log")
+			rotating, err := callbacks.NewRotatingFile(path, callbacks.FileOptions{Compression: callbacks.Zstd})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = rotating.Write([]byte("hello\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rotating.Close()).To(Succeed())
+
+			fileHandle, err := os.Open(path + ".zst")
+			Expect(err).NotTo(HaveOccurred())
+			defer fileHandle.Close()
+
+			decoder, err := zstd.NewReader(fileHandle)
+			Expect(err).NotTo(HaveOccurred())
+			defer decoder.Close()
+
+			content, err := io.ReadAll(decoder)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("hello\n"))
+		})
+	})
+})
+
+var _ = Describe("FanOutCallback", func() {
+	When("called", func() {
+		It("should write each format to its own destination", func() {
+			rawFile := NewTestFile()
+			jsonFile := NewTestFile()
+			callback := callbacks.NewFanOutCallback(
+				callbacks.NewFileCallback(rawFile, callbacks.Raw),
+				callbacks.NewFileCallback(jsonFile, callbacks.AnalyserJSON),
+			)
+
+			err := callback.Call(&testOutputType{Msg: "This is a test line"}, "testOut")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rawFile.ReadString('\n')).To(ContainSubstring("This is a test line"))
+			Expect(jsonFile.ReadString('\n')).To(Equal("{\"data\":[\"Hello\"],\"id\":\"testOutput\"}\n"))
+
+			Expect(callback.CleanUp()).To(Succeed())
+			Expect(rawFile.open).To(BeFalse())
+			Expect(jsonFile.open).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("ParseSink", func() {
+	It("should parse the format and filename", func() {
+		sink, err := callbacks.ParseSink("analyser:/tmp/out:1.json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sink.Format).To(Equal(callbacks.AnalyserJSON))
+		Expect(sink.Filename).To(Equal("/tmp/out:1.json"))
+
+		_, err = callbacks.ParseSink("xml:/tmp/out")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SampleTiming describes how a sample was taken so analysers can discount unreliable points
+type SampleTiming struct {
+	// SentAt is the local time the commands for the sample were sent
+	SentAt time.Time `json:"-"`
+	// ClockSkew is the remote clock minus the local clock at the middle of the round trip,
+	// it is only known when the sample includes a remote timestamp
+	ClockSkew *time.Duration `json:"clockSkew,omitempty"`
+	// ExecLatency is the round trip time of the commands
+	ExecLatency time.Duration `json:"execLatency"`
+	// PollDelay is how long after the poll was requested the sample was taken
+	PollDelay time.Duration `json:"pollDelay"`
+	// Unreliable is set when the round trip exceeded the configured bound
+	Unreliable bool `json:"unreliable,omitempty"`
+}
+
+// TimedSample is implemented by outputs which carry the timing of the sample they came from
+type TimedSample interface {
+	GetSampleTiming() *SampleTiming
+	SetSampleTiming(timing *SampleTiming)
+}
+
+// Timed can be embedded in an output to make it a TimedSample. The timing is not marshalled with
+// the output as that is often the data of its analyser format, it is added next to it instead.
+type Timed struct {
+	Timing *SampleTiming `json:"-"`
+}
+
+func (t *Timed) GetSampleTiming() *SampleTiming {
+	return t.Timing
+}
+
+func (t *Timed) SetSampleTiming(timing *SampleTiming) {
+	t.Timing = timing
+}
+
+// getAnalyserFormat returns the analyser format of output with the sample timing added to every record
+func getAnalyserFormat(output OutputType) ([]*AnalyserFormatType, error) {
+	outputs, err := output.GetAnalyserFormat()
+	if err != nil {
+		return outputs, err //nolint:wrapcheck // the error is wrapped by the caller
+	}
+
+	timing := getSampleTiming(output)
+	if timing == nil {
+		return outputs, nil
+	}
+
+	for _, formatted := range outputs {
+		formatted.Timing = timing
+	}
+
+	return outputs, nil
+}
+
+// getSampleTiming returns the timing of the sample output came from, or nil if it has none
+func getSampleTiming(output OutputType) *SampleTiming {
+	if targeted, ok := output.(*TargetedOutput); ok {
+		output = targeted.Output
+	}
+
+	timed, ok := output.(TimedSample)
+	if !ok {
+		return nil
+	}
+
+	return timed.GetSampleTiming()
+}
+
+// withSampleTiming adds the timing of the sample output came from to its raw JSON object
+func withSampleTiming(output OutputType, line []byte) ([]byte, error) {
+	timing := getSampleTiming(output)
+	if timing == nil || len(line) < 2 || line[0] != '{' {
+		return line, nil
+	}
+
+	timingJSON, err := json.Marshal(timing)
+	if err != nil {
+		return line, fmt.Errorf("failed to marshal the sample timing %w", err)
+	}
+
+	timed := append([]byte{}, line[:len(line)-1]...)
+	if len(line) > len("{}") {
+		timed = append(timed, ',')
+	}
+
+	timed = append(timed, `"timing":`...)
+	timed = append(timed, timingJSON...)
+
+	return append(timed, '}'), nil
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -86,6 +87,30 @@ func (c *ContainerExecContext) GetContainerName() string {
 	return c.containerName
 }
 
+// newExecutor returns an executor which runs command in the container
+func (c *ContainerExecContext) newExecutor(command []string, useStdin bool) (remotecommand.Executor, *url.URL, error) {
+	req := c.clientset.K8sRestClient.Post().
+		Namespace(c.GetNamespace()).
+		Resource("pods").
+		Name(c.GetPodName()).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: c.GetContainerName(),
+			Command:   command,
+			Stdin:     useStdin,
+			Stdout:    true,
+			Stderr:    true,
+			TTY:       false,
+		}, scheme.ParameterCodec)
+
+	exec, err := NewSPDYExecutor(c.clientset.RestConfig, "POST", req.URL())
+	if err != nil {
+		return exec, req.URL(), fmt.Errorf("error setting up remote command: %w", err)
+	}
+
+	return exec, req.URL(), nil
+}
+
 //nolint:lll,funlen // allow slightly long function definition and function length
 func (c *ContainerExecContext) execCommand(command []string, buffInPtr *bytes.Buffer) (stdout, stderr string, err error) {
 	commandStr := command
@@ -104,24 +129,10 @@ func (c *ContainerExecContext) execCommand(command []string, buffInPtr *bytes.Bu
 		c.GetContainerName(),
 		strings.Join(commandStr, " "),
 	)
-	req := c.clientset.K8sRestClient.Post().
-		Namespace(c.GetNamespace()).
-		Resource("pods").
-		Name(c.GetPodName()).
-		SubResource("exec").
-		VersionedParams(&corev1.PodExecOptions{
-			Container: c.GetContainerName(),
-			Command:   command,
-			Stdin:     useBuffIn,
-			Stdout:    true,
-			Stderr:    true,
-			TTY:       false,
-		}, scheme.ParameterCodec)
-
-	exec, err := NewSPDYExecutor(c.clientset.RestConfig, "POST", req.URL())
+	exec, execURL, err := c.newExecutor(command, useBuffIn)
 	if err != nil {
 		log.Debug(err)
-		return stdout, stderr, fmt.Errorf("error setting up remote command: %w", err)
+		return stdout, stderr, err
 	}
 
 	var streamOptions remotecommand.StreamOptions
@@ -153,7 +164,7 @@ func (c *ContainerExecContext) execCommand(command []string, buffInPtr *bytes.Bu
 		}
 
 		log.Debug(err)
-		log.Debug(req.URL())
+		log.Debug(execURL)
 		log.Debug("command: ", command)
 
 		if useBuffIn {
@@ -3,6 +3,8 @@
 package clients_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"net/url"
 
@@ -135,3 +137,38 @@ var _ = Describe("ExecCommandContainer", func() {
 		})
 	})
 })
+
+var _ = Describe("StreamCommand", func() {
+	var clientset *clients.Clientset
+	BeforeEach(func() {
+		clientset = testutils.GetMockedClientSet(testPod)
+	})
+
+	When("the command writes to stdout", func() {
+		It("should pass the output to the writer", func() {
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte("{\"class\":\"TPV\"}\n"), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNode")
+
+			var stdout bytes.Buffer
+			err := ctx.StreamCommand(context.Background(), []string{"gpspipe", "-w"}, &stdout)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal("{\"class\":\"TPV\"}\n"))
+		})
+	})
+	When("the stream fails", func() {
+		It("should return an error including stderr", func() {
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte(""), []byte("gpspipe: could not connect to gpsd"), errors.New("command terminated")
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNode")
+
+			var stdout bytes.Buffer
+			err := ctx.StreamCommand(context.Background(), []string{"gpspipe", "-w"}, &stdout)
+			Expect(err).To(MatchError(ContainSubstring("could not connect to gpsd")))
+		})
+	})
+})
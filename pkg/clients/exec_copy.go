@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+var ErrCopyNotSupported = errors.New("exec context can not copy files into its container")
+
+// FileCopier is implemented by exec contexts which can write a file into their container
+type FileCopier interface {
+	CopyFile(src io.Reader, path string) error
+}
+
+// CopyFile writes src to path in the container as an executable, replacing it in one step
+// so that a command already running from path is not disturbed
+func (c *ContainerExecContext) CopyFile(src io.Reader, path string) error {
+	log.Debugf(
+		"copying file to ns=%s, pod=%s container=%s, path: %s",
+		c.GetNamespace(), c.GetPodName(), c.GetContainerName(), path,
+	)
+
+	exec, _, err := c.newExecutor([]string{
+		"sh", "-c", `mkdir -p "$(dirname "$1")" && cat > "$1.tmp" && chmod 755 "$1.tmp" && mv "$1.tmp" "$1"`,
+		"sh", path,
+	}, true)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+
+	err = exec.StreamWithContext(context.TODO(), remotecommand.StreamOptions{
+		Stdin:  src,
+		Stdout: io.Discard,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy file to %s: %w: %s", path, err, stderr.String())
+	}
+
+	return nil
+}
+
+// CopyFile copies with its own exec as the shell's stdin carries the commands
+func (c *PersistentShellExecContext) CopyFile(src io.Reader, path string) error {
+	return c.container.CopyFile(src, path)
+}
+
+// CopyFile passes through to the wrapped context without recording the copy,
+// which is only needed to prepare the container and would bloat the archive
+func (c *RecordingExecContext) CopyFile(src io.Reader, path string) error {
+	return CopyToContainer(c.inner, src, path)
+}
+
+// CopyFile does nothing as there is no container when replaying
+func (c *ReplayExecContext) CopyFile(_ io.Reader, _ string) error {
+	return nil
+}
+
+// CopyToContainer writes src to path in the container of ctx
+func CopyToContainer(ctx ExecContext, src io.Reader, path string) error {
+	copier, ok := ctx.(FileCopier)
+	if !ok {
+		return ErrCopyNotSupported
+	}
+
+	return copier.CopyFile(src, path) //nolint:wrapcheck // the error is returned as is to the caller
+}
@@ -0,0 +1,377 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	persistentShell          = "/usr/bin/sh"
+	persistentFrameName      = "vse-sync-exec"
+	persistentCommandTimeout = 30 * time.Second
+	persistentLineBuffer     = 256
+)
+
+var (
+	errShellClosed    = errors.New("persistent shell closed")
+	errCommandTimeout = errors.New("timed out waiting for command to finish")
+)
+
+// shellSession is a single long-lived shell running in a container
+type shellSession struct {
+	err    error
+	stdin  *io.PipeWriter
+	stdout chan string
+	stderr chan string
+	done   chan struct{}
+	cancel context.CancelFunc
+}
+
+func readLines(reader io.Reader, lines chan<- string) {
+	buffered := bufio.NewReader(reader)
+
+	for {
+		line, err := buffered.ReadString('\n')
+		if line != "" {
+			lines <- line
+		}
+
+		if err != nil {
+			close(lines)
+			return
+		}
+	}
+}
+
+func startShellSession(exec remotecommand.Executor) *shellSession {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	stderrReader, stderrWriter := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	session := &shellSession{
+		stdin:  stdinWriter,
+		stdout: make(chan string, persistentLineBuffer),
+		stderr: make(chan string, persistentLineBuffer),
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	go readLines(stdoutReader, session.stdout)
+	go readLines(stderrReader, session.stderr)
+
+	go func() {
+		session.err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+			Stdin:  stdinReader,
+			Stdout: stdoutWriter,
+			Stderr: stderrWriter,
+		})
+
+		stdoutWriter.Close()
+		stderrWriter.Close()
+		stdinReader.Close()
+		close(session.done)
+	}()
+
+	return session
+}
+
+func (session *shellSession) close() {
+	session.cancel()
+	session.stdin.Close()
+}
+
+// closedError returns the reason the shell closed
+func (session *shellSession) closedError() error {
+	select {
+	case <-session.done:
+		if session.err != nil {
+			return fmt.Errorf("%w: %w", errShellClosed, session.err)
+		}
+	default:
+	}
+
+	return errShellClosed
+}
+
+// frameResult is the output of one command read from a stream of the shell
+type frameResult struct {
+	err     error
+	output  string
+	endLine string
+	started bool
+}
+
+// readFrame reads the output between the start and end markers of a command until ctx is done
+func readFrame(ctx context.Context, lines <-chan string, marker string) frameResult {
+	result := frameResult{}
+	startLine := "<" + marker + ">\n"
+	endPrefix := "</" + marker
+
+	var output strings.Builder
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				result.err = errShellClosed
+				return result
+			}
+
+			switch {
+			case !result.started:
+				// Anything before the start marker was left over from an earlier command
+				result.started = line == startLine
+			case strings.HasPrefix(line, endPrefix):
+				// The end marker is preceded by a newline which was not part of the output
+				result.output = strings.TrimSuffix(output.String(), "\n")
+				result.endLine = line
+
+				return result
+			default:
+				output.WriteString(line)
+			}
+		case <-ctx.Done():
+			result.err = errCommandTimeout
+			return result
+		}
+	}
+}
+
+// frameScript wraps script so that its output can be picked out of the shell's streams.
+// It runs in a subshell so it can not change or exit the persistent shell.
+func frameScript(marker, script string) string {
+	return fmt.Sprintf(
+		"printf '<%[1]s>\\n'; printf '<%[1]s>\\n' >&2\n"+
+			"(\n%[2]s\n) </dev/null\n"+
+			"__vse_sync_status=$?\n"+
+			"printf '\\n</%[1]s %%d>\\n' \"$__vse_sync_status\"; printf '\\n</%[1]s>\\n' >&2\n",
+		marker, script,
+	)
+}
+
+// exitStatus returns the exit status written in the end marker
+func exitStatus(endLine, marker string) (int, error) {
+	status := strings.TrimSuffix(strings.TrimPrefix(endLine, "</"+marker+" "), ">\n")
+
+	value, err := strconv.Atoi(status)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read exit status from %q: %w", endLine, err)
+	}
+
+	return value, nil
+}
+
+// PersistentShellExecContext keeps one shell running in a container and
+// sends every command to it rather than starting a new exec for each one.
+// Commands are framed with markers in the same way as Cmd so their output
+// can be separated, and the shell is restarted if it exits, for example
+// because the pod was restarted.
+type PersistentShellExecContext struct {
+	container *ContainerExecContext
+	session   *shellSession
+	timeout   time.Duration
+	sequence  uint64
+	mu        sync.Mutex
+}
+
+func NewPersistentShellExecContext(container *ContainerExecContext) (*PersistentShellExecContext, error) {
+	timeout, err := fetchDurationEnv("COLLECTOR_EXEC_TIMEOUT", persistentCommandTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PersistentShellExecContext{container: container, timeout: timeout}, nil
+}
+
+func (c *PersistentShellExecContext) startSession() error {
+	exec, _, err := c.container.newExecutor([]string{persistentShell}, true)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf(
+		"starting persistent shell on ns=%s, pod=%s container=%s",
+		c.container.GetNamespace(), c.container.GetPodName(), c.container.GetContainerName(),
+	)
+	c.session = startShellSession(exec)
+
+	return nil
+}
+
+func (c *PersistentShellExecContext) closeSession() {
+	if c.session != nil {
+		c.session.close()
+		c.session = nil
+	}
+}
+
+// resetSession closes the shell and looks the pod up again in case it has been replaced
+func (c *PersistentShellExecContext) resetSession() {
+	c.closeSession()
+
+	err := c.container.refresh()
+	if err != nil {
+		log.Debug("Failed to refresh container context", err)
+	}
+}
+
+// runOnce runs script in the current shell, the returned bool is true if
+// the shell had gone before the command started so it is safe to retry
+func (c *PersistentShellExecContext) runOnce(script string) (stdout, stderr string, retry bool, err error) {
+	if c.session == nil {
+		err = c.startSession()
+		if err != nil {
+			return "", "", false, err
+		}
+	}
+
+	c.sequence++
+	marker := fmt.Sprintf("%s-%d", persistentFrameName, c.sequence)
+	session := c.session
+
+	_, err = io.WriteString(session.stdin, frameScript(marker, script))
+	if err != nil {
+		return "", "", true, session.closedError()
+	}
+
+	// Both readers must see the timeout, otherwise one of them is left waiting on a hung command
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	stderrResult := make(chan frameResult, 1)
+
+	go func() {
+		stderrResult <- readFrame(ctx, session.stderr, marker)
+	}()
+
+	outResult := readFrame(ctx, session.stdout, marker)
+	errResult := <-stderrResult
+
+	if outResult.err != nil || errResult.err != nil {
+		err = outResult.err
+		if err == nil {
+			err = errResult.err
+		}
+
+		// A command which timed out may still be running so it is not retried, run resets the shell instead
+		if !errors.Is(err, errShellClosed) {
+			return outResult.output, errResult.output, false, err
+		}
+
+		return outResult.output, errResult.output, !outResult.started, session.closedError()
+	}
+
+	status, err := exitStatus(outResult.endLine, marker)
+	if err != nil {
+		return outResult.output, errResult.output, false, err
+	}
+
+	if status != 0 {
+		return outResult.output, errResult.output, false, fmt.Errorf("command terminated with exit code %d", status)
+	}
+
+	return outResult.output, errResult.output, false, nil
+}
+
+// run sends script to the shell, restarting the shell once if it had gone away
+func (c *PersistentShellExecContext) run(script string) (stdout, stderr string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stdout, stderr, retry, err := c.runOnce(script)
+	if err != nil && retry {
+		log.Debugf("persistent shell closed, restarting: %s", err.Error())
+		c.resetSession()
+
+		stdout, stderr, _, err = c.runOnce(script)
+	}
+
+	if err != nil {
+		// Anything left in the shell could be mistaken for the output of the next command
+		c.resetSession()
+
+		return stdout, stderr, fmt.Errorf("error running command in persistent shell: %w", err)
+	}
+
+	return stdout, stderr, nil
+}
+
+func shellQuote(command []string) string {
+	quoted := make([]string, 0, len(command))
+	for _, arg := range command {
+		quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// ExecCommand runs command in the persistent shell
+func (c *PersistentShellExecContext) ExecCommand(command []string) (stdout, stderr string, err error) {
+	return c.run(shellQuote(command))
+}
+
+// ExecCommandStdIn runs the script in buffIn in the persistent shell when command is a shell,
+// any other command is run with its own exec as it may need stdin to itself
+//
+//nolint:lll // allow slightly long function definition
+func (c *PersistentShellExecContext) ExecCommandStdIn(command []string, buffIn bytes.Buffer) (stdout, stderr string, err error) {
+	if len(command) != 1 || (command[0] != persistentShell && command[0] != "sh") {
+		return c.container.ExecCommandStdIn(command, buffIn)
+	}
+
+	return c.run(buffIn.String())
+}
+
+// Close stops the shell
+func (c *PersistentShellExecContext) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closeSession()
+
+	return nil
+}
+
+// PersistentShellPodExecContext is a PersistentShellExecContext for a pod which it manages
+type PersistentShellPodExecContext struct {
+	*PersistentShellExecContext
+
+	pod *ContainerCreationExecContext
+}
+
+func NewPersistentShellPodExecContext(pod *ContainerCreationExecContext) (*PersistentShellPodExecContext, error) {
+	shell, err := NewPersistentShellExecContext(pod.ContainerExecContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PersistentShellPodExecContext{PersistentShellExecContext: shell, pod: pod}, nil
+}
+
+func (c *PersistentShellPodExecContext) CreatePodAndWait() error {
+	return c.pod.CreatePodAndWait()
+}
+
+// DeletePodAndWait stops the shell before deleting the pod
+func (c *PersistentShellPodExecContext) DeletePodAndWait() error {
+	err := c.Close()
+	if err != nil {
+		return err
+	}
+
+	return c.pod.DeletePodAndWait()
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients_test
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+// localShellResponder runs a local shell wired up to the streams of each exec and counts the shells started
+func localShellResponder(
+	shellsStarted *int,
+) func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+	return func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+		*shellsStarted++
+		cmd := exec.Command("sh")
+		cmd.Stdout = options.Stdout
+		cmd.Stderr = options.Stderr
+		// Copy stdin ourselves so the exec ends when the shell does, as it would in a cluster
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return []byte{}, []byte{}, err
+		}
+		if err = cmd.Start(); err != nil {
+			return []byte{}, []byte{}, err
+		}
+		go io.Copy(stdin, options.Stdin) //nolint:errcheck // the copy ends with an error when the shell exits
+		return []byte{}, []byte{}, cmd.Wait()
+	}
+}
+
+var _ = Describe("PersistentShellExecContext", func() {
+	var shell *clients.PersistentShellExecContext
+	var container *clients.ContainerExecContext
+	var shellsStarted int
+
+	BeforeEach(func() {
+		shellsStarted = 0
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(localShellResponder(&shellsStarted), nil)
+
+		clientset := testutils.GetMockedClientSet(testPod)
+		var err error
+		container, err = clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNode")
+		Expect(err).NotTo(HaveOccurred())
+		shell, err = clients.NewPersistentShellExecContext(container)
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(shell.Close()).To(Succeed())
+	})
+
+	When("several commands are run", func() {
+		It("should run them all in one shell", func() {
+			stdout, stderr, err := shell.ExecCommand([]string{"sh", "-c", "echo out; echo err >&2"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(Equal("out\n"))
+			Expect(stderr).To(Equal("err\n"))
+
+			stdout, _, err = shell.ExecCommand([]string{"printf", "%s", "it's quoted"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(Equal("it's quoted"))
+
+			Expect(shellsStarted).To(Equal(1))
+		})
+	})
+	When("a script is passed to a shell on stdin", func() {
+		It("should run the script in the persistent shell", func() {
+			var script bytes.Buffer
+			script.WriteString("echo one;\necho two;")
+			stdout, _, err := shell.ExecCommandStdIn([]string{"/usr/bin/sh"}, script)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(Equal("one\ntwo\n"))
+			Expect(shellsStarted).To(Equal(1))
+		})
+	})
+	When("a command fails", func() {
+		It("should return an error and keep the output", func() {
+			stdout, _, err := shell.ExecCommand([]string{"sh", "-c", "echo partial; exit 3"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exit code 3"))
+			Expect(stdout).To(Equal("partial\n"))
+		})
+	})
+	When("a command hangs", func() {
+		It("should time out, then start a new shell for the next command", func() {
+			GinkgoT().Setenv("COLLECTOR_EXEC_TIMEOUT", "200ms")
+			hungShell, err := clients.NewPersistentShellExecContext(container)
+			Expect(err).NotTo(HaveOccurred())
+			defer hungShell.Close()
+
+			// The commands are run in the background so a hang fails the spec rather than the suite
+			type result struct {
+				err    error
+				stdout string
+			}
+			results := make(chan result, 1)
+			execInBackground := func(command ...string) {
+				go func() {
+					stdout, _, execErr := hungShell.ExecCommand(command)
+					results <- result{stdout: stdout, err: execErr}
+				}()
+			}
+
+			var hung result
+			execInBackground("sleep", "5")
+			Eventually(results, "2s").Should(Receive(&hung))
+			Expect(hung.err).To(MatchError(ContainSubstring("timed out")))
+
+			var recovered result
+			execInBackground("echo", "recovered")
+			Eventually(results, "2s").Should(Receive(&recovered))
+			Expect(recovered.err).NotTo(HaveOccurred())
+			Expect(recovered.stdout).To(Equal("recovered\n"))
+			Expect(shellsStarted).To(Equal(2))
+		})
+	})
+	When("the shell dies", func() {
+		It("should start a new shell for the next command", func() {
+			_, _, err := shell.ExecCommand([]string{"sh", "-c", "kill -9 $PPID"})
+			Expect(err).To(HaveOccurred())
+
+			stdout, _, err := shell.ExecCommand([]string{"echo", "recovered"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(Equal("recovered\n"))
+			Expect(shellsStarted).To(Equal(2))
+		})
+	})
+})
+
+var _ = Describe("GetSessionExecContext", func() {
+	var clientset *clients.Clientset
+	var shellsStarted int
+
+	BeforeEach(func() {
+		shellsStarted = 0
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(localShellResponder(&shellsStarted), nil)
+		clientset = testutils.GetMockedClientSet(testPod)
+		clients.EnablePersistentShells()
+	})
+	AfterEach(func() {
+		Expect(clients.StopExecSession()).To(Succeed())
+	})
+
+	When("two collectors use the same container", func() {
+		It("should share one persistent shell", func() {
+			contextsCreated := 0
+			newCtx := func() (clients.ExecContext, error) {
+				contextsCreated++
+				return clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNode")
+			}
+			name := clients.ExecContextName("TestNamespace", "Test", "TestContainer", "TestNode")
+
+			first, err := clients.GetSessionExecContext(name, newCtx)
+			Expect(err).NotTo(HaveOccurred())
+			second, err := clients.GetSessionExecContext(name, newCtx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(BeIdenticalTo(first))
+			Expect(contextsCreated).To(Equal(1))
+
+			for _, ctx := range []clients.ExecContext{first, second} {
+				stdout, _, err := ctx.ExecCommand([]string{"echo", "shared"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stdout).To(Equal("shared\n"))
+			}
+			Expect(shellsStarted).To(Equal(1))
+		})
+	})
+})
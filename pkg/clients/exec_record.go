@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const archivePermissions = 0666
+
+// PodExecContext is an ExecContext whose pod is created and removed by the tool itself
+type PodExecContext interface {
+	ExecContext
+	CreatePodAndWait() error
+	DeletePodAndWait() error
+}
+
+// ExecRecord holds everything needed to serve a single command again without a cluster
+type ExecRecord struct {
+	Start    time.Time     `json:"start"`
+	Context  string        `json:"context"`
+	Stdin    string        `json:"stdin,omitempty"`
+	Stdout   string        `json:"stdout"`
+	Stderr   string        `json:"stderr"`
+	Error    string        `json:"error,omitempty"`
+	Command  []string      `json:"command"`
+	Duration time.Duration `json:"duration"`
+	UseStdin bool          `json:"useStdin"`
+}
+
+// key identifies the request part of a record, recorded responses are matched on it
+func (rec *ExecRecord) key() string {
+	return execRecordKey(rec.Context, rec.Command, rec.Stdin, rec.UseStdin)
+}
+
+func execRecordKey(contextName string, command []string, stdin string, useStdin bool) string {
+	return fmt.Sprintf("%s\x00%s\x00%t\x00%s", contextName, strings.Join(command, "\x1f"), useStdin, stdin)
+}
+
+// ExecContextName returns the name used to identify a container in an archive
+func ExecContextName(namespace, podNamePrefix, containerName, nodeName string) string {
+	return fmt.Sprintf("%s/%s/%s@%s", namespace, podNamePrefix, containerName, nodeName)
+}
+
+// Recorder writes ExecRecords to an archive as JSON lines.
+// Each record is written as soon as the command returns so that
+// nothing is lost if the process exits unexpectedly.
+type Recorder struct {
+	writer io.WriteCloser
+	mu     sync.Mutex
+}
+
+func NewRecorder(writer io.WriteCloser) *Recorder {
+	return &Recorder{writer: writer}
+}
+
+// NewFileRecorder returns a Recorder which writes to the archive at path, truncating any existing archive
+func NewFileRecorder(path string) (*Recorder, error) {
+	fileHandle, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, archivePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open exec archive %s: %w", path, err)
+	}
+
+	return NewRecorder(fileHandle), nil
+}
+
+func (r *Recorder) Write(rec *ExecRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal exec record: %w", err)
+	}
+
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err = r.writer.Write(line)
+	if err != nil {
+		return fmt.Errorf("failed to write exec record: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.writer.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close exec archive: %w", err)
+	}
+
+	return nil
+}
+
+// RecordingExecContext passes commands through to another ExecContext
+// and records every call and its result with the Recorder
+type RecordingExecContext struct {
+	inner    ExecContext
+	recorder *Recorder
+	name     string
+}
+
+func NewRecordingExecContext(inner ExecContext, recorder *Recorder, name string) *RecordingExecContext {
+	return &RecordingExecContext{
+		inner:    inner,
+		recorder: recorder,
+		name:     name,
+	}
+}
+
+func (c *RecordingExecContext) record(
+	command []string,
+	buffInPtr *bytes.Buffer,
+	run func() (string, string, error),
+) (stdout, stderr string, err error) {
+	rec := ExecRecord{
+		Context:  c.name,
+		Command:  command,
+		UseStdin: buffInPtr != nil,
+		Start:    time.Now().UTC(),
+	}
+	if buffInPtr != nil {
+		rec.Stdin = buffInPtr.String()
+	}
+
+	stdout, stderr, err = run()
+
+	rec.Duration = time.Since(rec.Start)
+	rec.Stdout = stdout
+	rec.Stderr = stderr
+
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	writeErr := c.recorder.Write(&rec)
+	if writeErr != nil {
+		log.Errorf("failed to record command for %s: %s", c.name, writeErr.Error())
+	}
+
+	return stdout, stderr, err
+}
+
+// ExecCommand runs the command on the wrapped context and records the result
+func (c *RecordingExecContext) ExecCommand(command []string) (stdout, stderr string, err error) {
+	return c.record(command, nil, func() (string, string, error) {
+		return c.inner.ExecCommand(command) //nolint:wrapcheck // the error is returned as is to the caller
+	})
+}
+
+// ExecCommandStdIn runs the command on the wrapped context and records the result
+//
+//nolint:lll // allow slightly long function definition
+func (c *RecordingExecContext) ExecCommandStdIn(command []string, buffIn bytes.Buffer) (stdout, stderr string, err error) {
+	stdin := bytes.NewBufferString(buffIn.String())
+
+	return c.record(command, stdin, func() (string, string, error) {
+		return c.inner.ExecCommandStdIn(command, buffIn) //nolint:wrapcheck // the error is returned as is to the caller
+	})
+}
+
+// CreatePodAndWait passes through to the wrapped context if it manages its own pod
+func (c *RecordingExecContext) CreatePodAndWait() error {
+	if podCtx, ok := c.inner.(PodExecContext); ok {
+		return podCtx.CreatePodAndWait() //nolint:wrapcheck // the error is returned as is to the caller
+	}
+
+	return nil
+}
+
+// DeletePodAndWait passes through to the wrapped context if it manages its own pod
+func (c *RecordingExecContext) DeletePodAndWait() error {
+	if podCtx, ok := c.inner.(PodExecContext); ok {
+		return podCtx.DeletePodAndWait() //nolint:wrapcheck // the error is returned as is to the caller
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+)
+
+type archiveBuffer struct {
+	bytes.Buffer
+}
+
+func (a *archiveBuffer) Close() error {
+	return nil
+}
+
+type countingExecContext struct {
+	calls int
+}
+
+func (c *countingExecContext) ExecCommand(command []string) (stdout, stderr string, err error) {
+	c.calls++
+	if command[0] == "fail" {
+		return "partial", "oops", errors.New("command failed")
+	}
+
+	return "out", "", nil
+}
+
+func (c *countingExecContext) ExecCommandStdIn(command []string, buffIn bytes.Buffer) (stdout, stderr string, err error) {
+	c.calls++
+
+	return fmt.Sprintf("%s-%d", buffIn.String(), c.calls), "", nil
+}
+
+var _ = Describe("Record and replay", func() {
+	var archive *archiveBuffer
+	var inner *countingExecContext
+	var recorder *clients.Recorder
+
+	BeforeEach(func() {
+		archive = &archiveBuffer{}
+		inner = &countingExecContext{}
+		recorder = clients.NewRecorder(archive)
+	})
+
+	When("commands are recorded", func() {
+		It("should replay the same responses in order without running anything", func() {
+			name := clients.ExecContextName("TestNamespace", "Test", "TestContainer", "TestNode")
+			ctx := clients.NewRecordingExecContext(inner, recorder, name)
+
+			stdin := bytes.Buffer{}
+			stdin.WriteString("date")
+			first, _, err := ctx.ExecCommandStdIn([]string{"/usr/bin/sh"}, stdin)
+			Expect(err).NotTo(HaveOccurred())
+
+			stdin = bytes.Buffer{}
+			stdin.WriteString("date")
+			second, _, err := ctx.ExecCommandStdIn([]string{"/usr/bin/sh"}, stdin)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err = ctx.ExecCommand([]string{"fail"})
+			Expect(err).To(HaveOccurred())
+			Expect(recorder.Close()).To(Succeed())
+			Expect(inner.calls).To(Equal(3))
+
+			loaded, err := clients.LoadReplayArchive(&archive.Buffer)
+			Expect(err).NotTo(HaveOccurred())
+			replay := clients.NewReplayExecContext(loaded, name)
+
+			stdin = bytes.Buffer{}
+			stdin.WriteString("date")
+			stdout, _, err := replay.ExecCommandStdIn([]string{"/usr/bin/sh"}, stdin)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(Equal(first))
+
+			stdin = bytes.Buffer{}
+			stdin.WriteString("date")
+			stdout, _, err = replay.ExecCommandStdIn([]string{"/usr/bin/sh"}, stdin)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(Equal(second))
+
+			stdout, stderr, err := replay.ExecCommand([]string{"fail"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("command failed"))
+			Expect(stdout).To(Equal("partial"))
+			Expect(stderr).To(Equal("oops"))
+
+			_, _, err = replay.ExecCommand([]string{"fail"})
+			Expect(errors.Is(err, clients.ErrReplayExhausted)).To(BeTrue())
+			Expect(inner.calls).To(Equal(3))
+		})
+	})
+
+	When("a command was recorded for a different container", func() {
+		It("should not be replayed", func() {
+			ctx := clients.NewRecordingExecContext(inner, recorder, "ns/pod/container@node1")
+			_, _, err := ctx.ExecCommand([]string{"ls"})
+			Expect(err).NotTo(HaveOccurred())
+
+			loaded, err := clients.LoadReplayArchive(&archive.Buffer)
+			Expect(err).NotTo(HaveOccurred())
+
+			replay := clients.NewReplayExecContext(loaded, "ns/pod/container@node2")
+			_, _, err = replay.ExecCommand([]string{"ls"})
+			Expect(errors.Is(err, clients.ErrReplayExhausted)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const maxArchiveLineSize = 64 * 1024 * 1024
+
+var ErrReplayExhausted = errors.New("no more recorded responses")
+
+// ReplayArchive holds recorded responses grouped by the request which produced them.
+// Responses for the same request are served in the order they were recorded.
+type ReplayArchive struct {
+	responses map[string][]*ExecRecord
+	mu        sync.Mutex
+}
+
+// LoadReplayArchive reads a JSON lines archive written by a Recorder
+func LoadReplayArchive(reader io.Reader) (*ReplayArchive, error) {
+	archive := &ReplayArchive{responses: make(map[string][]*ExecRecord)}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxArchiveLineSize)
+
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		rec := &ExecRecord{}
+
+		err := json.Unmarshal(line, rec)
+		if err != nil {
+			return archive, fmt.Errorf("failed to parse exec record on line %d: %w", lineNumber, err)
+		}
+
+		key := rec.key()
+		archive.responses[key] = append(archive.responses[key], rec)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return archive, fmt.Errorf("failed to read exec archive: %w", err)
+	}
+
+	return archive, nil
+}
+
+// LoadReplayArchiveFile reads the archive at path
+func LoadReplayArchiveFile(path string) (*ReplayArchive, error) {
+	fileHandle, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open exec archive %s: %w", path, err)
+	}
+	defer fileHandle.Close()
+
+	return LoadReplayArchive(fileHandle)
+}
+
+func (archive *ReplayArchive) next(key string) (*ExecRecord, bool) {
+	archive.mu.Lock()
+	defer archive.mu.Unlock()
+
+	recs := archive.responses[key]
+	if len(recs) == 0 {
+		return nil, false
+	}
+
+	archive.responses[key] = recs[1:]
+
+	return recs[0], true
+}
+
+// ReplayExecContext serves the responses from a ReplayArchive instead of running commands
+type ReplayExecContext struct {
+	archive *ReplayArchive
+	name    string
+}
+
+func NewReplayExecContext(archive *ReplayArchive, name string) *ReplayExecContext {
+	return &ReplayExecContext{archive: archive, name: name}
+}
+
+func (c *ReplayExecContext) replay(command []string, stdin string, useStdin bool) (stdout, stderr string, err error) {
+	rec, ok := c.archive.next(execRecordKey(c.name, command, stdin, useStdin))
+	if !ok {
+		log.Debugf("no recorded response on %s for command: %s", c.name, strings.Join(command, " "))
+
+		return "", "", fmt.Errorf(
+			"failed to replay command %s on %s: %w",
+			strings.Join(command, " "), c.name, ErrReplayExhausted,
+		)
+	}
+
+	if rec.Error != "" {
+		return rec.Stdout, rec.Stderr, fmt.Errorf("error running remote command: %s", rec.Error)
+	}
+
+	return rec.Stdout, rec.Stderr, nil
+}
+
+// ExecCommand returns the next recorded response for command
+func (c *ReplayExecContext) ExecCommand(command []string) (stdout, stderr string, err error) {
+	return c.replay(command, "", false)
+}
+
+// ExecCommandStdIn returns the next recorded response for command and buffIn
+//
+//nolint:lll // allow slightly long function definition
+func (c *ReplayExecContext) ExecCommandStdIn(command []string, buffIn bytes.Buffer) (stdout, stderr string, err error) {
+	return c.replay(command, buffIn.String(), true)
+}
+
+// CreatePodAndWait does nothing as there is no pod when replaying
+func (c *ReplayExecContext) CreatePodAndWait() error {
+	return nil
+}
+
+// DeletePodAndWait does nothing as there is no pod when replaying
+func (c *ReplayExecContext) DeletePodAndWait() error {
+	return nil
+}
@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type execSessionMode int
+
+const (
+	sessionLive execSessionMode = iota
+	sessionRecord
+	sessionReplay
+)
+
+// execSession decides whether exec contexts talk to the cluster,
+// record what they do or replay a previously recorded archive.
+// Persistent shells are kept by the name of their container so that it has only one.
+type execSession struct {
+	recorder   *Recorder
+	archive    *ReplayArchive
+	shells     map[string]*PersistentShellExecContext
+	podShells  map[string]*PersistentShellPodExecContext
+	mode       execSessionMode
+	persistent bool
+}
+
+var session = execSession{}
+
+// StartRecording records every command run through a session exec context to the archive at path
+func StartRecording(path string) error {
+	if session.mode != sessionLive {
+		return errors.New("an exec session is already recording or replaying")
+	}
+
+	recorder, err := NewFileRecorder(path)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("recording exec session to %s", path)
+	session = execSession{mode: sessionRecord, recorder: recorder}
+
+	return nil
+}
+
+// StartReplay serves every command run through a session exec context from the archive at path
+func StartReplay(path string) error {
+	if session.mode != sessionLive {
+		return errors.New("an exec session is already recording or replaying")
+	}
+
+	archive, err := LoadReplayArchiveFile(path)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("replaying exec session from %s", path)
+	session = execSession{mode: sessionReplay, archive: archive}
+
+	return nil
+}
+
+// EnablePersistentShells makes session exec contexts run every command in one
+// long-lived shell per container instead of starting an exec for each command.
+// It has no effect when replaying.
+func EnablePersistentShells() {
+	session.persistent = true
+}
+
+// StopExecSession closes any archive being recorded and persistent shells and returns to talking to the cluster
+func StopExecSession() error {
+	errs := make([]error, 0, len(session.shells)+len(session.podShells)+1)
+	for _, shell := range session.shells {
+		errs = append(errs, shell.Close())
+	}
+
+	for _, shell := range session.podShells {
+		errs = append(errs, shell.Close())
+	}
+
+	if session.recorder != nil {
+		errs = append(errs, session.recorder.Close())
+	}
+
+	session = execSession{}
+
+	return errors.Join(errs...)
+}
+
+// IsReplaying returns true if commands are being served from an archive so there is no cluster
+func IsReplaying() bool {
+	return session.mode == sessionReplay
+}
+
+// withPersistentShell returns the persistent shell of the container identified by name if they are enabled,
+// starting one for the context newCtx creates the first time. Otherwise it returns the context newCtx creates.
+func (s *execSession) withPersistentShell(name string, newCtx func() (ExecContext, error)) (ExecContext, error) {
+	if shell, ok := s.shells[name]; ok {
+		return shell, nil
+	}
+
+	ctx, err := newCtx()
+	if err != nil {
+		return ctx, err
+	}
+
+	container, ok := ctx.(*ContainerExecContext)
+	if !s.persistent || !ok {
+		return ctx, nil
+	}
+
+	shell, err := NewPersistentShellExecContext(container)
+	if err != nil {
+		return ctx, err
+	}
+
+	if s.shells == nil {
+		s.shells = make(map[string]*PersistentShellExecContext)
+	}
+
+	s.shells[name] = shell
+
+	return shell, nil
+}
+
+// withPersistentPodShell is the same as withPersistentShell for contexts which manage their own pod
+func (s *execSession) withPersistentPodShell(
+	name string,
+	newCtx func() (PodExecContext, error),
+) (PodExecContext, error) {
+	if shell, ok := s.podShells[name]; ok {
+		return shell, nil
+	}
+
+	ctx, err := newCtx()
+	if err != nil {
+		return ctx, err
+	}
+
+	pod, ok := ctx.(*ContainerCreationExecContext)
+	if !s.persistent || !ok {
+		return ctx, nil
+	}
+
+	shell, err := NewPersistentShellPodExecContext(pod)
+	if err != nil {
+		return ctx, err
+	}
+
+	if s.podShells == nil {
+		s.podShells = make(map[string]*PersistentShellPodExecContext)
+	}
+
+	s.podShells[name] = shell
+
+	return shell, nil
+}
+
+// GetSessionExecContext returns the exec context to use for the container identified by name.
+// When replaying newCtx is not called as there is no cluster to connect to.
+func GetSessionExecContext(name string, newCtx func() (ExecContext, error)) (ExecContext, error) {
+	switch session.mode {
+	case sessionReplay:
+		return NewReplayExecContext(session.archive, name), nil
+	case sessionRecord:
+		ctx, err := session.withPersistentShell(name, newCtx)
+		if err != nil {
+			return ctx, err
+		}
+
+		return NewRecordingExecContext(ctx, session.recorder, name), nil
+	case sessionLive:
+		return session.withPersistentShell(name, newCtx)
+	default:
+		return nil, fmt.Errorf("unknown exec session mode %d", session.mode)
+	}
+}
+
+// GetSessionPodExecContext is the same as GetSessionExecContext for contexts which manage their own pod
+func GetSessionPodExecContext(name string, newCtx func() (PodExecContext, error)) (PodExecContext, error) {
+	switch session.mode {
+	case sessionReplay:
+		return NewReplayExecContext(session.archive, name), nil
+	case sessionRecord:
+		ctx, err := session.withPersistentPodShell(name, newCtx)
+		if err != nil {
+			return ctx, err
+		}
+
+		return NewRecordingExecContext(ctx, session.recorder, name), nil
+	case sessionLive:
+		return session.withPersistentPodShell(name, newCtx)
+	default:
+		return nil, fmt.Errorf("unknown exec session mode %d", session.mode)
+	}
+}
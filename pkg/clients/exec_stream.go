@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// StreamContext runs long-lived commands whose output is consumed as it is produced
+type StreamContext interface {
+	StreamCommand(ctx context.Context, command []string, stdout io.Writer) error
+}
+
+// StreamCommand runs command in the container writing its stdout to stdout as it arrives.
+// It returns once the command exits or ctx is cancelled, cancelling ctx is not an error.
+// Streamed commands are not recorded by --record so can not be replayed.
+func (c *ContainerExecContext) StreamCommand(ctx context.Context, command []string, stdout io.Writer) error {
+	log.Debugf(
+		"stream command on ns=%s, pod=%s container=%s, cmd: %s",
+		c.GetNamespace(),
+		c.GetPodName(),
+		c.GetContainerName(),
+		strings.Join(command, " "),
+	)
+
+	exec, _, err := c.newExecutor(command, false)
+	if err != nil {
+		return err
+	}
+
+	var buffErr bytes.Buffer
+
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: stdout,
+		Stderr: &buffErr,
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			log.Debugf("Pod %s was not found, likely restarted so refreshing context", c.GetPodName())
+
+			refreshErr := c.refresh()
+			if refreshErr != nil {
+				log.Debug("Failed to refresh container context", refreshErr)
+			}
+		}
+
+		return fmt.Errorf("error streaming remote command: %w stderr: %s", err, buffErr.String())
+	}
+
+	return nil
+}
@@ -14,8 +14,12 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/alarms"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/constants"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/interference"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/runner"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
@@ -27,7 +31,10 @@ const (
 	defaultIncludeLogTimestamps bool   = false
 	defaultTempDir              string = "."
 	defaultKeepDebugFiles       bool   = false
+	defaultAdaptiveRate         string = "250ms"
+	defaultAdaptiveOffset       int    = 100
 	tempdirPerm                        = 0755
+	bytesPerMegabyte                   = 1024 * 1024
 )
 
 var (
@@ -40,8 +47,130 @@ var (
 	tempDir                string
 	keepDebugFiles         bool
 	unmanagedDebugPod      bool
+	metricsAddress         string
+	extraOutputs           []string
+	rotateSizeMB           int
+	rotateIntervalStr      string
+	compressionName        string
+	alarmExpressions       []string
+	alarmExitCode          bool
+	targetValues           []string
+	collectorRates         map[string]int
+	adaptivePolling        bool
+	adaptiveRateStr        string
+	adaptiveOffset         int
+	maxExecLatencyStr      string
+	rejectSlowSamples      bool
+	gnssDecoder            string
+	detectInterference     bool
+	interferenceJamInd     int
+	dpllAllCards           bool
+	helperPath             string
 )
 
+// getOutputs returns the sinks and file options for the output flags
+func getOutputs() ([]callbacks.Sink, callbacks.FileOptions) {
+	outputFormat := callbacks.Raw
+	if useAnalyserJSON {
+		outputFormat = callbacks.AnalyserJSON
+	}
+
+	sinks := []callbacks.Sink{{Filename: outputFile, Format: outputFormat}}
+
+	for _, extra := range extraOutputs {
+		sink, err := callbacks.ParseSink(extra)
+		if err != nil {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(err))
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	compression, err := callbacks.ParseCompression(compressionName)
+	if err != nil {
+		utils.IfErrorExitOrPanic(utils.NewMissingInputError(err))
+	}
+
+	options := callbacks.FileOptions{
+		Compression: compression,
+		MaxSize:     int64(rotateSizeMB) * bytesPerMegabyte,
+	}
+
+	if rotateIntervalStr != "" {
+		options.MaxAge, err = time.ParseDuration(rotateIntervalStr)
+		if err != nil {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				fmt.Errorf("failed to parse rotate interval: %w", err),
+			))
+		}
+	}
+
+	return sinks, options
+}
+
+// getAdaptivePolling returns the adaptive polling settings from the flags
+func getAdaptivePolling() collectors.AdaptivePolling {
+	fastInterval, err := time.ParseDuration(adaptiveRateStr)
+	if err != nil {
+		utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+			fmt.Errorf("failed to parse adaptive rate: %w", err),
+		))
+	}
+
+	return collectors.AdaptivePolling{
+		Enabled:         adaptivePolling,
+		FastInterval:    fastInterval,
+		OffsetThreshold: float64(adaptiveOffset),
+	}
+}
+
+// getSampleLatencyBound returns the bound on sample round trips from the flags
+func getSampleLatencyBound() collectors.SampleLatencyBound {
+	bound := collectors.SampleLatencyBound{Reject: rejectSlowSamples}
+	if maxExecLatencyStr == "" {
+		return bound
+	}
+
+	maxLatency, err := time.ParseDuration(maxExecLatencyStr)
+	if err != nil {
+		utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+			fmt.Errorf("failed to parse max exec latency: %w", err),
+		))
+	}
+
+	bound.Max = maxLatency
+
+	return bound
+}
+
+// getInterferenceThresholds returns the thresholds for the interference detector or nil if it is disabled
+func getInterferenceThresholds() *interference.Thresholds {
+	if !detectInterference {
+		return nil
+	}
+
+	thresholds := interference.DefaultThresholds()
+	thresholds.JamIndWarning = interferenceJamInd
+
+	return &thresholds
+}
+
+// getTargets returns the parsed --target values
+func getTargets() []collectors.Target {
+	targets := make([]collectors.Target, 0, len(targetValues))
+
+	for _, value := range targetValues {
+		target, err := collectors.ParseTarget(value)
+		if err != nil {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(err))
+		}
+
+		targets = append(targets, target)
+	}
+
+	return targets
+}
+
 // collectCmd represents the collect command
 var collectCmd = &cobra.Command{
 	Use:   "collect",
@@ -55,61 +184,119 @@ var collectCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		collectionRunner := runner.NewCollectorRunner(collectorNames)
-
-		requestedDuration, err := time.ParseDuration(requestedDurationStr)
-		if requestedDuration.Nanoseconds() < 0 {
-			log.Panicf("Requested duration must be positive")
+		switch gnssDecoder {
+		case collectors.GNSSDecoderAuto, collectors.GNSSDecoderBinary, collectors.GNSSDecoderText:
+		default:
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				fmt.Errorf("unknown GNSS decoder %q", gnssDecoder),
+			))
 		}
-		utils.IfErrorExitOrPanic(err)
 
-		for _, c := range collectorNames {
-			if (c == collectors.LogsCollectorName || c == runner.All) && logsOutputFile == "" {
-				utils.IfErrorExitOrPanic(utils.NewMissingInputError(
-					errors.New("if Logs collector is selected you must also provide a log output file")),
-				)
-			}
+		stopExecSession := startExecSession()
+
+		alarmsFired := runCollection(clockTypeUpper)
+		// os.Exit skips the deferred calls so the session is stopped before exiting with the alarm code
+		stopExecSession()
+
+		if alarmExitCode && alarmsFired {
+			utils.IfErrorExitOrPanic(utils.NewAlarmRaisedError(errors.New("one or more alarms fired during collection")))
 		}
+	},
+}
 
-		if strings.Contains(tempDir, "~") {
-			var usr *user.User
-			usr, err = user.Current()
-			if err != nil {
-				log.Fatal("Failed to fetch current user so could not resolve tempdir")
-			}
-			if tempDir == "~" {
-				tempDir = usr.HomeDir
-			} else if strings.HasPrefix(tempDir, "~/") {
-				tempDir = filepath.Join(usr.HomeDir, tempDir[2:])
-			}
+// runCollection runs the selected collectors until the requested duration has passed,
+// it returns true if any alarm fired during the collection
+func runCollection(clockTypeUpper string) bool { //nolint:funlen // allow a slightly long function
+	selectedCollectors, collectorPollIntervals, err := collectorSelection(collectorNames, collectorRates)
+	if err != nil {
+		utils.IfErrorExitOrPanic(utils.NewMissingInputError(err))
+	}
+
+	collectionRunner := runner.NewCollectorRunner(selectedCollectors)
+
+	requestedDuration, err := time.ParseDuration(requestedDurationStr)
+	if requestedDuration.Nanoseconds() < 0 {
+		log.Panicf("Requested duration must be positive")
+	}
+	utils.IfErrorExitOrPanic(err)
+
+	for _, c := range selectedCollectors {
+		if (c == collectors.LogsCollectorName || c == runner.All) && logsOutputFile == "" {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("if Logs collector is selected you must also provide a log output file")),
+			)
 		}
+	}
 
-		if err = os.MkdirAll(tempDir, tempdirPerm); err != nil {
-			log.Fatal(err)
+	if strings.Contains(tempDir, "~") {
+		var usr *user.User
+		usr, err = user.Current()
+		if err != nil {
+			log.Fatal("Failed to fetch current user so could not resolve tempdir")
 		}
+		if tempDir == "~" {
+			tempDir = usr.HomeDir
+		} else if strings.HasPrefix(tempDir, "~/") {
+			tempDir = filepath.Join(usr.HomeDir, tempDir[2:])
+		}
+	}
+
+	if err = os.MkdirAll(tempDir, tempdirPerm); err != nil {
+		log.Fatal(err)
+	}
+
+	outputs, outputOptions := getOutputs()
+
+	alarmRules, err := alarms.ParseRules(alarmExpressions)
+	if err != nil {
+		utils.IfErrorExitOrPanic(utils.NewMissingInputError(err))
+	}
+
+	constuctor, err := collectors.NewCollectionConstructor(
+		kubeConfig,
+		outputs,
+		outputOptions,
+		ptpInterface,
+		nodeName,
+		logsOutputFile,
+		tempDir,
+		pollInterval,
+		devInfoAnnouceInterval,
+		includeLogTimestamps,
+		keepDebugFiles,
+		unmanagedDebugPod,
+		clockTypeUpper,
+		metricsAddress,
+		alarmRules,
+		getTargets(),
+		collectorPollIntervals,
+		getAdaptivePolling(),
+		getSampleLatencyBound(),
+		gnssDecoder,
+		getInterferenceThresholds(),
+		dpllAllCards,
+		helperPath,
+	)
+	utils.IfErrorExitOrPanic(err)
 
-		constuctor, err := collectors.NewCollectionConstructor(
-			kubeConfig,
-			useAnalyserJSON,
-			outputFile,
-			ptpInterface,
-			nodeName,
-			logsOutputFile,
-			tempDir,
-			pollInterval,
-			devInfoAnnouceInterval,
-			includeLogTimestamps,
-			keepDebugFiles,
-			unmanagedDebugPod,
-			clockTypeUpper,
-		)
+	if constuctor.Metrics != nil {
+		err = constuctor.Metrics.Start()
 		utils.IfErrorExitOrPanic(err)
 
-		collectionRunner.Run(
-			requestedDuration,
-			constuctor,
-		)
-	},
+		defer func() {
+			err := constuctor.Metrics.Stop()
+			if err != nil {
+				log.Error(err)
+			}
+		}()
+	}
+
+	collectionRunner.Run(
+		requestedDuration,
+		constuctor,
+	)
+
+	return constuctor.Alarms != nil && constuctor.Alarms.HasFired()
 }
 
 func init() { //nolint:funlen // Allow this to get a little long
@@ -121,6 +308,28 @@ func init() { //nolint:funlen // Allow this to get a little long
 	AddInterfaceFlag(collectCmd)
 	AddNodeNameFlag(collectCmd)
 	AddClockTypeFlag(collectCmd)
+	AddRecordReplayFlags(collectCmd)
+	AddPersistentExecFlag(collectCmd)
+	AddConfigFlags(collectCmd)
+
+	collectCmd.Flags().StringArrayVar(&targetValues, "target", []string{},
+		"Node and PTP interface to collect from in the form <node>:<interface>[:<clock type>], "+
+			"can be given multiple times to collect from several nodes at once. "+
+			"Every output is labelled with its node and interface. Replaces --interface, --nodeName and --clock-type")
+	collectCmd.MarkFlagsMutuallyExclusive("target", "interface")
+	collectCmd.MarkFlagsMutuallyExclusive("target", "nodeName")
+	collectCmd.MarkFlagsMutuallyExclusive("target", "clock-type")
+
+	recordReplayPreRun := collectCmd.PreRun
+	collectCmd.PreRun = func(cmd *cobra.Command, args []string) {
+		// The profile may set any flag so it is applied first
+		applyProfile(cmd)
+		recordReplayPreRun(cmd, args)
+
+		if len(targetValues) > 0 {
+			liftRequiredFlag(cmd, "interface")
+		}
+	}
 
 	collectCmd.Flags().StringVarP(
 		&requestedDurationStr,
@@ -162,6 +371,33 @@ func init() { //nolint:funlen // Allow this to get a little long
 		),
 	)
 
+	collectCmd.Flags().StringToIntVar(&collectorRates, "collector-rate", map[string]int{},
+		"Poll interval in seconds for individual collectors overriding --rate, for example DPLL=1,PMC=10,GNSS=2")
+	collectCmd.Flags().BoolVar(&adaptivePolling, "adaptive", false,
+		"Poll faster while the DPLL, GNSS or PMC values show a state change or large offset "+
+			"then slow back down to the normal rate once they are stable")
+	collectCmd.Flags().StringVar(&adaptiveRateStr, "adaptive-rate", defaultAdaptiveRate,
+		"Poll interval used by --adaptive while there is activity, such as \"250ms\"")
+	collectCmd.Flags().IntVar(&adaptiveOffset, "adaptive-offset", defaultAdaptiveOffset,
+		"Offset in nanoseconds beyond which --adaptive considers there to be activity")
+	collectCmd.Flags().StringVar(&maxExecLatencyStr, "max-exec-latency", "",
+		"Mark samples whose round trip to the cluster took longer than this, such as \"500ms\", as unreliable")
+	collectCmd.Flags().BoolVar(&rejectSlowSamples, "reject-slow-samples", false,
+		"Drop samples which exceed --max-exec-latency instead of marking them as unreliable")
+	collectCmd.Flags().StringVar(&gnssDecoder, "gnss-decoder", collectors.GNSSDecoderAuto,
+		fmt.Sprintf("How the GNSS collector reads the receiver: %q decodes a raw UBX capture, %q parses ubxtool's text "+
+			"output and %q decodes the raw capture unless the first attempt fails",
+			collectors.GNSSDecoderBinary, collectors.GNSSDecoderText, collectors.GNSSDecoderAuto))
+
+	collectCmd.Flags().BoolVar(&dpllAllCards, "dpll-all-cards", false,
+		"Collect the netlink DPLL of every card found by detect rather than only the card of --interface, "+
+			"each record is tagged with the interface, PCI address and clock ID of its card")
+	collectCmd.Flags().StringVar(&helperPath, "helper-path", "",
+		fmt.Sprintf("Path of this tool in the containers of the cluster, such as %s. When given it is the PMC client, "+
+			"makes the netlink requests of the DPLL collectors in place of the netlink debug pod and is required by the "+
+			"DPLL-Events collector. It is copied there when missing if it was built for the node's architecture and "+
+			"is left there after the collection, by default pmc and ynl are used instead", devices.SuggestedHelperPath))
+
 	collectCmd.Flags().StringVarP(
 		&logsOutputFile,
 		"logs-output", "l", "",
@@ -178,4 +414,31 @@ func init() { //nolint:funlen // Allow this to get a little long
 	collectCmd.Flags().BoolVar(&keepDebugFiles, "keep", defaultKeepDebugFiles, "Keep debug files")
 
 	collectCmd.Flags().BoolVar(&unmanagedDebugPod, "unmanaged-debug-pod", false, "Do not manage debug pod")
+
+	collectCmd.Flags().StringSliceVar(&extraOutputs, "extra-output", []string{},
+		"Additional output in the form <format>:<filename> where format is raw or analyser. "+
+			"Can be given multiple times to write to several destinations at once")
+	collectCmd.Flags().IntVar(&rotateSizeMB, "rotate-size", 0,
+		"Start a new output file once the current one has this many megabytes written to it. Disabled if 0")
+	collectCmd.Flags().StringVar(&rotateIntervalStr, "rotate-interval", "",
+		"Start a new output file at this interval, such as \"1h\". Disabled if empty")
+	collectCmd.Flags().StringVar(&compressionName, "compression", "none",
+		"Compress output files with none, gzip or zstd")
+
+	collectCmd.Flags().StringVar(&metricsAddress, "metrics-address", "",
+		"Address (e.g. \":9090\") on which to serve the latest collected values as prometheus metrics at /metrics. "+
+			"Disabled if empty")
+
+	collectCmd.Flags().BoolVar(&detectInterference, "interference", false,
+		"Look for GNSS jamming and spoofing in the GNSS and GPSD values and write gnss/interference events "+
+			"when it starts, changes severity or clears")
+	collectCmd.Flags().IntVar(&interferenceJamInd, "interference-jam-ind", interference.DefaultThresholds().JamIndWarning,
+		"Jamming indicator (0-255) from UBX-MON-RF and UBX-MON-HW at which --interference reports jamming")
+
+	collectCmd.Flags().StringArrayVar(&alarmExpressions, "alarm", []string{},
+		"Alarm rule in the form \"<record id>.<field> <operator> [<value>] [for <samples>]\", "+
+			"for example \"dpll/time-error.terror abs> 50 for 3\". "+
+			"Operators are >, >=, <, <=, ==, !=, abs> and changes. Can be given multiple times")
+	collectCmd.Flags().BoolVar(&alarmExitCode, "alarm-exit-code", false,
+		fmt.Sprintf("Exit with code %d if any alarm fired during the collection", utils.AlarmRaised))
 }
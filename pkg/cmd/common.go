@@ -3,8 +3,11 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/constants"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
@@ -16,6 +19,9 @@ var (
 	ptpInterface    string
 	nodeName        string
 	clockType       string
+	recordArchive   string
+	replayArchive   string
+	persistentExec  bool
 )
 
 func AddKubeconfigFlag(targetCmd *cobra.Command) {
@@ -66,3 +72,61 @@ func AddClockTypeFlag(targetCmd *cobra.Command) {
 		"c", constants.ClockTypeGM,
 		"Clock type: GM (Grand Master) or BC (Boundary Clock)")
 }
+
+func AddRecordReplayFlags(targetCmd *cobra.Command) {
+	targetCmd.Flags().StringVar(&recordArchive,
+		"record", "",
+		"Path to an archive in which to record every command run on the cluster")
+	targetCmd.Flags().StringVar(&replayArchive,
+		"replay", "",
+		"Path to an archive recorded with --record to run against instead of a cluster")
+	targetCmd.MarkFlagsMutuallyExclusive("record", "replay")
+
+	// Required flags are validated after PreRun so the kubeconfig
+	// requirement can be lifted when there is no cluster to talk to.
+	targetCmd.PreRun = func(cmd *cobra.Command, args []string) {
+		if replayArchive != "" {
+			liftRequiredFlag(cmd, "kubeconfig")
+		}
+	}
+}
+
+func AddPersistentExecFlag(targetCmd *cobra.Command) {
+	targetCmd.Flags().BoolVar(&persistentExec,
+		"persistent-exec", false,
+		"Run commands in one long-lived shell per container rather than starting an exec for every command")
+}
+
+// liftRequiredFlag stops a flag being required, it must be called before cobra validates flags
+func liftRequiredFlag(cmd *cobra.Command, name string) {
+	err := cmd.Flags().SetAnnotation(name, cobra.BashCompOneRequiredFlag, []string{"false"})
+	utils.IfErrorExitOrPanic(err)
+}
+
+// startExecSession starts recording or replaying if requested,
+// the returned function should be called once the command has finished.
+func startExecSession() func() {
+	var err error
+
+	switch {
+	case recordArchive != "":
+		err = clients.StartRecording(recordArchive)
+	case replayArchive != "":
+		err = clients.StartReplay(replayArchive)
+	}
+
+	if err != nil {
+		utils.IfErrorExitOrPanic(utils.NewMissingInputError(err))
+	}
+
+	if persistentExec && replayArchive == "" {
+		clients.EnablePersistentShells()
+	}
+
+	return func() {
+		err := clients.StopExecSession()
+		if err != nil {
+			utils.IfErrorExitOrPanic(fmt.Errorf("failed to stop exec session: %w", err))
+		}
+	}
+}
@@ -17,7 +17,7 @@ var startDebug = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		clientset, err := clients.GetClientset(kubeConfig)
 		utils.IfErrorExitOrPanic(err)
-		ctx, err := contexts.GetNetlinkContext(clientset, nodeName, false)
+		ctx, err := contexts.GetNetlinkContext(clientset, nodeName, false, false)
 		utils.IfErrorExitOrPanic(err)
 		err = ctx.CreatePodAndWait()
 		utils.IfErrorExitOrPanic(err)
@@ -31,7 +31,7 @@ var stopDebug = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		clientset, err := clients.GetClientset(kubeConfig)
 		utils.IfErrorExitOrPanic(err)
-		ctx, err := contexts.GetNetlinkContext(clientset, nodeName, false)
+		ctx, err := contexts.GetNetlinkContext(clientset, nodeName, false, false)
 		utils.IfErrorExitOrPanic(err)
 		err = ctx.DeletePodAndWait()
 		utils.IfErrorExitOrPanic(err)
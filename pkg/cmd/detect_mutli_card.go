@@ -25,6 +25,9 @@ var detectCards = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "Error: Invalid clock type '%s'. Must be either '%s' or '%s'\n", clockType, constants.ClockTypeGM, constants.ClockTypeBC)
 			os.Exit(1)
 		}
+		stopExecSession := startExecSession()
+		defer stopExecSession()
+
 		detect.Detect(kubeConfig, nodeName, useAnalyserJSON, clockTypeUpper)
 	},
 }
@@ -35,4 +38,5 @@ func init() {
 	AddFormatFlag(detectCards)
 	AddNodeNameFlag(detectCards)
 	AddClockTypeFlag(detectCards)
+	AddRecordReplayFlags(detectCards)
 }
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/dpll"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+var (
+	dpllDump      string
+	dpllDo        string
+	dpllRequest   string
+	dpllSubscribe string
+	dpllSerial    string
+)
+
+// dpllRequestAttrs are the attributes which can be passed to a --do request
+type dpllRequestAttrs struct {
+	ID *uint32 `json:"id"`
+}
+
+// dpllCmd talks to the kernel's DPLL netlink family directly, its flags and output
+// follow ynl's cli.py so it can be used in its place
+var dpllCmd = &cobra.Command{
+	Use:   "dpll",
+	Short: "Query the DPLL netlink family of the host",
+	Long: `Query the DPLL netlink family of the host it is run on and print the replies as JSON.
+It takes the same --dump, --do, --json and --subscribe arguments as ynl's cli.py with the dpll spec,
+so it can be run in the linuxptp-daemon container or on the host in place of the netlink debug pod.
+With --serial-number it prints the PCIe serial number of the card of an interface, from which its clock ID is found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if dpllSerial != "" {
+			serialNumber, err := dpll.SerialNumber(dpllSerial)
+			utils.IfErrorExitOrPanic(err)
+			fmt.Fprintln(os.Stdout, serialNumber)
+
+			return
+		}
+
+		client, err := dpll.Dial()
+		utils.IfErrorExitOrPanic(err)
+		defer client.Close()
+
+		encoder := json.NewEncoder(os.Stdout)
+
+		switch {
+		case dpllSubscribe != "":
+			err = runDPLLMonitor(client, encoder)
+		case dpllDump != "":
+			err = runDPLLDump(client, encoder)
+		case dpllDo != "":
+			err = runDPLLDo(client, encoder)
+		default:
+			err = errors.New("one of --dump, --do, --subscribe or --serial-number is required")
+		}
+
+		utils.IfErrorExitOrPanic(err)
+	},
+}
+
+func runDPLLDump(client *dpll.Client, encoder *json.Encoder) error {
+	var (
+		reply any
+		err   error
+	)
+
+	switch dpllDump {
+	case "device-get":
+		reply, err = client.DumpDevices()
+	case "pin-get":
+		reply, err = client.DumpPins()
+	default:
+		return fmt.Errorf("unsupported dump %s", dpllDump)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to dump %s: %w", dpllDump, err)
+	}
+
+	return encoder.Encode(reply) //nolint:wrapcheck // the error is reported as is
+}
+
+func runDPLLDo(client *dpll.Client, encoder *json.Encoder) error {
+	attrs := dpllRequestAttrs{}
+
+	err := json.Unmarshal([]byte(dpllRequest), &attrs)
+	if err != nil || attrs.ID == nil {
+		return fmt.Errorf("--json must have the id to get, such as '{\"id\": 1}': %w", err)
+	}
+
+	var reply any
+
+	switch dpllDo {
+	case "device-get":
+		reply, err = client.GetDevice(*attrs.ID)
+	case "pin-get":
+		reply, err = client.GetPin(*attrs.ID)
+	default:
+		return fmt.Errorf("unsupported request %s", dpllDo)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to do %s: %w", dpllDo, err)
+	}
+
+	return encoder.Encode(reply) //nolint:wrapcheck // the error is reported as is
+}
+
+func runDPLLMonitor(client *dpll.Client, encoder *json.Encoder) error {
+	if dpllSubscribe != dpll.MonitorGroup {
+		return fmt.Errorf("unsupported multicast group %s", dpllSubscribe)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var encodeErr error
+
+	err := client.Monitor(ctx, func(notification *dpll.Notification) {
+		encodeErr = errors.Join(encodeErr, encoder.Encode(notification))
+	})
+
+	return errors.Join(err, encodeErr)
+}
+
+func init() {
+	rootCmd.AddCommand(dpllCmd)
+
+	dpllCmd.Flags().StringVar(&dpllDump, "dump", "", "Dump every object of a get request: device-get or pin-get")
+	dpllCmd.Flags().StringVar(&dpllDo, "do", "", "Make a single get request: device-get or pin-get")
+	dpllCmd.Flags().StringVar(&dpllRequest, "json", "", "The attributes of a --do request, such as '{\"id\": 1}'")
+	dpllCmd.Flags().StringVar(&dpllSubscribe, "subscribe", "", "Print the notifications of a multicast group: monitor")
+	dpllCmd.Flags().StringVar(&dpllSerial, "serial-number", "",
+		"Print the PCIe serial number of the card of an interface as lspci shows it without the dashes")
+	dpllCmd.MarkFlagsMutuallyExclusive("dump", "do", "subscribe", "serial-number")
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/ptpmgmt"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+var (
+	pmcConfigFile   string
+	pmcUDSAddress   string
+	pmcDomainNumber uint8
+)
+
+// pmcCmd talks to ptp4l over its UNIX socket directly, its arguments follow linuxptp's pmc
+var pmcCmd = &cobra.Command{
+	Use:   "pmc [flags] 'GET <management ID>'...",
+	Short: "Query the management datasets of ptp4l",
+	Long: `Send PTP management GETs to ptp4l over its UNIX socket and print each response as a line of JSON.
+The socket and domain are read from the ptp4l config given with -f, as pmc -u -f does,
+so it can be run in the linuxptp-daemon container in place of pmc. Any management ID pmc knows can be
+given by name, or any other by number such as 'GET 0xc005'.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config := &ptpmgmt.Config{UDSAddress: ptpmgmt.DefaultUDSAddress}
+
+		if pmcConfigFile != "" {
+			var err error
+			config, err = ptpmgmt.ReadConfig(pmcConfigFile)
+			utils.IfErrorExitOrPanic(err)
+		}
+
+		if cmd.Flags().Changed("uds-address") {
+			config.UDSAddress = pmcUDSAddress
+		}
+
+		if cmd.Flags().Changed("domain") {
+			config.DomainNumber = pmcDomainNumber
+		}
+
+		ids := make([]ptpmgmt.ManagementID, 0, len(args))
+
+		for _, arg := range args {
+			id, err := parsePMCGet(arg)
+			utils.IfErrorExitOrPanic(err)
+
+			ids = append(ids, id)
+		}
+
+		client, err := ptpmgmt.DialUDS(config.UDSAddress, config.DomainNumber)
+		utils.IfErrorExitOrPanic(err)
+		defer client.Close()
+
+		utils.IfErrorExitOrPanic(runPMCGets(client, ids, json.NewEncoder(os.Stdout)))
+	},
+}
+
+// parsePMCGet returns the management ID of a command such as 'GET CURRENT_DATA_SET'
+func parsePMCGet(arg string) (ptpmgmt.ManagementID, error) {
+	fields := strings.Fields(arg)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "GET") { //nolint:mnd // the action and the ID
+		return 0, fmt.Errorf("unsupported command '%s', only 'GET <management ID>' is supported", arg)
+	}
+
+	return ptpmgmt.ParseManagementID(fields[1]) //nolint:wrapcheck // the error is reported as is
+}
+
+// runPMCGets prints the responses to every GET, carrying on past a GET which failed
+func runPMCGets(client *ptpmgmt.Client, ids []ptpmgmt.ManagementID, encoder *json.Encoder) error {
+	var errs error
+
+	for _, id := range ids {
+		responses, err := client.Get(id)
+		errs = errors.Join(errs, err)
+
+		for _, response := range responses {
+			errs = errors.Join(errs, encoder.Encode(response))
+		}
+	}
+
+	return errs
+}
+
+func init() {
+	rootCmd.AddCommand(pmcCmd)
+
+	pmcCmd.Flags().StringVarP(&pmcConfigFile, "config", "f", "",
+		"The ptp4l config to read uds_address and domainNumber from")
+	pmcCmd.Flags().StringVarP(&pmcUDSAddress, "uds-address", "s", ptpmgmt.DefaultUDSAddress,
+		"The UNIX socket ptp4l listens on")
+	pmcCmd.Flags().Uint8VarP(&pmcDomainNumber, "domain", "d", 0, "The domain number of the requests")
+}
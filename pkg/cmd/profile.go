@@ -0,0 +1,297 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/runner"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	configFlag     = "config"
+	dumpConfigFlag = "dump-config"
+	collectorsKey  = "collectors"
+)
+
+// CollectorProfile overrides the settings of a single collector
+type CollectorProfile struct {
+	Enabled *bool `json:"enabled,omitempty"`
+	Rate    int   `json:"rate,omitempty"`
+}
+
+var (
+	configFile        string
+	dumpConfig        bool
+	collectorProfiles map[string]CollectorProfile
+)
+
+func AddConfigFlags(targetCmd *cobra.Command) {
+	targetCmd.Flags().StringVar(&configFile,
+		configFlag, "",
+		"Path to a YAML or JSON profile setting any of the other flags by name, "+
+			"flags given on the command line take precedence over the profile")
+	targetCmd.Flags().BoolVar(&dumpConfig,
+		dumpConfigFlag, false,
+		"Print the effective configuration, after merging the profile and flags, as YAML and exit")
+}
+
+// flagValueString converts a profile value into the string form used on the command line
+func flagValueString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any:
+		pairs := make([]string, 0, len(v))
+		for key, value := range v {
+			pairs = append(pairs, key+"="+flagValueString(value))
+		}
+
+		slices.Sort(pairs)
+
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// setFlagFromProfile sets a flag from its raw JSON value unless it is the flags default
+func setFlagFromProfile(flags *pflag.FlagSet, flag *pflag.Flag, raw json.RawMessage) error {
+	if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+		var values []any
+
+		err := json.Unmarshal(raw, &values)
+		if err != nil {
+			return fmt.Errorf("%s must be a list: %w", flag.Name, err)
+		}
+
+		items := make([]string, 0, len(values))
+		for _, value := range values {
+			items = append(items, flagValueString(value))
+		}
+
+		if "["+strings.Join(items, ",")+"]" == flag.DefValue {
+			return nil
+		}
+
+		err = sliceValue.Replace(items)
+		if err != nil {
+			return fmt.Errorf("failed to set %s: %w", flag.Name, err)
+		}
+
+		flag.Changed = true
+
+		return nil
+	}
+
+	var value any
+
+	err := json.Unmarshal(raw, &value)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", flag.Name, err)
+	}
+
+	if _, isList := value.([]any); isList {
+		return fmt.Errorf("%s must be a single value", flag.Name)
+	}
+
+	valueString := flagValueString(value)
+	if valueString == flag.DefValue || (valueString == "" && flag.DefValue == "[]") {
+		return nil
+	}
+
+	err = flags.Set(flag.Name, valueString)
+	if err != nil {
+		return fmt.Errorf("failed to set %s: %w", flag.Name, err)
+	}
+
+	return nil
+}
+
+// parseCollectorProfiles reads the collector overrides of a profile, rejecting
+// any setting a CollectorProfile does not have and any collector which does not exist
+func parseCollectorProfiles(raw json.RawMessage) (map[string]CollectorProfile, error) {
+	profiles := make(map[string]CollectorProfile)
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&profiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse collectors: %w", err)
+	}
+
+	for name := range profiles {
+		if !slices.Contains(runner.OptionalCollectorNames, name) && !slices.Contains(runner.RequiredCollectorNames, name) {
+			return nil, fmt.Errorf("unknown collector %s", name)
+		}
+	}
+
+	return profiles, nil
+}
+
+// loadProfile reads a YAML or JSON profile and sets each flag in it which was not given on the command line
+func loadProfile(cmd *cobra.Command, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	values := make(map[string]json.RawMessage)
+
+	err = yaml.UnmarshalStrict(content, &values)
+	if err != nil {
+		return fmt.Errorf("failed to parse profile %s: %w", path, err)
+	}
+
+	for name, raw := range values {
+		if name == collectorsKey {
+			collectorProfiles, err = parseCollectorProfiles(raw)
+			if err != nil {
+				return fmt.Errorf("profile %s: %w", path, err)
+			}
+
+			continue
+		}
+
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || name == configFlag || name == dumpConfigFlag || cmd.InheritedFlags().Lookup(name) != nil {
+			return fmt.Errorf("unknown setting %s in profile %s", name, path)
+		}
+
+		if flag.Changed {
+			continue
+		}
+
+		err = setFlagFromProfile(cmd.Flags(), flag, raw)
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// effectiveProfile returns the value of every flag along with the collector overrides
+func effectiveProfile(cmd *cobra.Command) map[string]any {
+	profile := make(map[string]any)
+
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == configFlag || flag.Name == dumpConfigFlag || flag.Name == "help" {
+			return
+		}
+
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			profile[flag.Name] = sliceValue.GetSlice()
+			return
+		}
+
+		value := flag.Value.String()
+
+		switch flag.Value.Type() {
+		case "stringToInt":
+			profile[flag.Name], _ = cmd.Flags().GetStringToInt(flag.Name)
+		case "bool":
+			profile[flag.Name], _ = strconv.ParseBool(value)
+		case "int":
+			profile[flag.Name], _ = strconv.Atoi(value)
+		default:
+			profile[flag.Name] = value
+		}
+	})
+
+	if len(collectorProfiles) > 0 {
+		profile[collectorsKey] = collectorProfiles
+	}
+
+	return profile
+}
+
+// applyProfile loads the profile given by --config and prints
+// the resulting configuration then exits if --dump-config was given.
+// It must be called before cobra validates the required flags.
+func applyProfile(cmd *cobra.Command) {
+	if configFile != "" {
+		err := loadProfile(cmd, configFile)
+		if err != nil {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(err))
+		}
+	}
+
+	if !dumpConfig {
+		return
+	}
+
+	err := writeProfile(cmd, os.Stdout)
+	utils.IfErrorExitOrPanic(err)
+
+	os.Exit(int(utils.Success))
+}
+
+// writeProfile writes the effective configuration as YAML, which can be given back with --config
+func writeProfile(cmd *cobra.Command, out io.Writer) error {
+	content, err := yaml.Marshal(effectiveProfile(cmd))
+	if err != nil {
+		return fmt.Errorf("failed to marshal the configuration: %w", err)
+	}
+
+	_, err = out.Write(content)
+	if err != nil {
+		return fmt.Errorf("failed to write the configuration: %w", err)
+	}
+
+	return nil
+}
+
+// collectorSelection applies the collector overrides from the profile to the selected collectors
+// and returns the collectors to run along with any per collector poll intervals,
+// rates given with --collector-rate take precedence over the profile
+func collectorSelection(selected []string, rates map[string]int) ([]string, map[string]int, error) {
+	enabled := append(make([]string, 0, len(selected)), selected...)
+	disabled := make([]string, 0)
+	pollIntervals := make(map[string]int)
+
+	for name, profile := range collectorProfiles {
+		if profile.Enabled != nil {
+			if *profile.Enabled {
+				enabled = append(enabled, name)
+			} else {
+				disabled = append(disabled, name)
+			}
+		}
+
+		if profile.Rate > 0 {
+			pollIntervals[name] = profile.Rate
+		}
+	}
+
+	maps.Copy(pollIntervals, rates)
+
+	for name, interval := range pollIntervals {
+		if interval < 1 {
+			return nil, nil, fmt.Errorf("poll interval for collector %s must be at least 1 second", name)
+		}
+	}
+
+	names, err := runner.WithoutCollectors(enabled, disabled)
+	if err != nil {
+		return nil, nil, err //nolint:wrapcheck // the error names the collector
+	}
+
+	return names, pollIntervals, nil
+}
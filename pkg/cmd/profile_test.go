@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cmd //nolint:testpackage // testing the unexported profile loading
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+)
+
+// newProfileTestCmd returns a command with a flag of each kind a profile can set
+func newProfileTestCmd() *cobra.Command {
+	testCmd := &cobra.Command{Use: "test"}
+	testCmd.Flags().String("interface", "", "")
+	testCmd.Flags().Int("rate", 1, "")
+	testCmd.Flags().Bool("adaptive", false, "")
+	testCmd.Flags().StringSlice("collector", []string{"defaults"}, "")
+	testCmd.Flags().StringToInt("collector-rate", map[string]int{}, "")
+	AddConfigFlags(testCmd)
+
+	return testCmd
+}
+
+func writeTestProfile(content string) string {
+	path := filepath.Join(GinkgoT().TempDir(), "profile.yaml")
+	Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+
+	return path
+}
+
+var _ = Describe("loadProfile", func() {
+	var testCmd *cobra.Command
+
+	BeforeEach(func() {
+		testCmd = newProfileTestCmd()
+		collectorProfiles = nil
+	})
+
+	DescribeTable("setting flags from a profile",
+		func(profile string, cliArgs []string, flagName, expected string) {
+			Expect(testCmd.ParseFlags(cliArgs)).To(Succeed())
+			Expect(loadProfile(testCmd, writeTestProfile(profile))).To(Succeed())
+			Expect(testCmd.Flags().Lookup(flagName).Value.String()).To(Equal(expected))
+		},
+		Entry("sets a string", "interface: ens7f0", []string{}, "interface", "ens7f0"),
+		Entry("sets an int", "rate: 5", []string{}, "rate", "5"),
+		Entry("sets a bool", "adaptive: true", []string{}, "adaptive", "true"),
+		Entry("sets a list", "collector: [GNSS, DPLL]", []string{}, "collector", "[GNSS,DPLL]"),
+		Entry("sets a map", "collector-rate: {GNSS: 2}", []string{}, "collector-rate", "[GNSS=2]"),
+		Entry("reads JSON", `{"interface": "ens7f0"}`, []string{}, "interface", "ens7f0"),
+		Entry("leaves a string given on the command line",
+			"interface: ens7f0", []string{"--interface=ens8f0"}, "interface", "ens8f0"),
+		Entry("leaves a list given on the command line",
+			"collector: [GNSS]", []string{"--collector=PMC"}, "collector", "[PMC]"),
+	)
+
+	DescribeTable("rejecting a profile",
+		func(profile, expectedErr string) {
+			err := loadProfile(testCmd, writeTestProfile(profile))
+			Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("with an unknown setting", "interfaces: ens7f0", "unknown setting interfaces"),
+		Entry("setting the profile itself", "config: other.yaml", "unknown setting config"),
+		Entry("with a setting given twice", "rate: 5\nrate: 6", "already set"),
+		Entry("with a list for a single value", "interface: [ens7f0]", "must be a single value"),
+		Entry("with an unknown collector setting", "collectors: {GNSS: {rat: 5}}", "unknown field"),
+		Entry("with an unknown collector", "collectors: {GNNS: {rate: 5}}", "unknown collector GNNS"),
+	)
+
+	It("should read the collector overrides", func() {
+		profile := "collectors:\n  GNSS: {rate: 5}\n  PMC: {enabled: false}\n"
+		Expect(loadProfile(testCmd, writeTestProfile(profile))).To(Succeed())
+		Expect(collectorProfiles).To(HaveKeyWithValue(collectors.GPSCollectorName, CollectorProfile{Rate: 5}))
+		Expect(collectorProfiles).To(HaveKey(collectors.PMCCollectorName))
+		Expect(*collectorProfiles[collectors.PMCCollectorName].Enabled).To(BeFalse())
+	})
+})
+
+var _ = Describe("collectorSelection", func() {
+	enabled := true
+	disabled := false
+
+	BeforeEach(func() {
+		collectorProfiles = map[string]CollectorProfile{
+			collectors.DPLLPinsCollectorName: {Enabled: &enabled},
+			collectors.PMCCollectorName:      {Enabled: &disabled},
+			collectors.GPSCollectorName:      {Rate: 5},
+			collectors.DPLLCollectorName:     {Rate: 3},
+		}
+	})
+
+	It("should enable and disable the collectors of the profile", func() {
+		names, _, err := collectorSelection([]string{collectors.GPSCollectorName, collectors.PMCCollectorName}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(ContainElements(collectors.DevInfoCollectorName, collectors.GPSCollectorName,
+			collectors.DPLLPinsCollectorName))
+		Expect(names).NotTo(ContainElement(collectors.PMCCollectorName))
+	})
+
+	It("should prefer the rates given on the command line", func() {
+		_, intervals, err := collectorSelection([]string{"defaults"}, map[string]int{collectors.GPSCollectorName: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(intervals).To(HaveKeyWithValue(collectors.GPSCollectorName, 2))
+		Expect(intervals).To(HaveKeyWithValue(collectors.DPLLCollectorName, 3))
+	})
+
+	It("should not disable a required collector", func() {
+		collectorProfiles[collectors.DevInfoCollectorName] = CollectorProfile{Enabled: &disabled}
+		_, _, err := collectorSelection([]string{"defaults"}, nil)
+		Expect(err).To(MatchError(ContainSubstring("is required")))
+	})
+
+	It("should not accept a poll interval below a second", func() {
+		_, _, err := collectorSelection([]string{"defaults"}, map[string]int{collectors.GPSCollectorName: 0})
+		Expect(err).To(MatchError(ContainSubstring("at least 1 second")))
+	})
+})
+
+var _ = Describe("writeProfile", func() {
+	BeforeEach(func() {
+		collectorProfiles = nil
+	})
+
+	It("should write the merged profile and flags so they can be loaded again", func() {
+		testCmd := newProfileTestCmd()
+		Expect(testCmd.ParseFlags([]string{"--rate=7"})).To(Succeed())
+		profile := "interface: ens7f0\nrate: 5\ncollectors:\n  GNSS: {rate: 5}\n"
+		Expect(loadProfile(testCmd, writeTestProfile(profile))).To(Succeed())
+
+		var out bytes.Buffer
+		Expect(writeProfile(testCmd, &out)).To(Succeed())
+
+		written := make(map[string]any)
+		Expect(yaml.Unmarshal(out.Bytes(), &written)).To(Succeed())
+		Expect(written).To(HaveKeyWithValue("interface", "ens7f0"))
+		Expect(written).To(HaveKeyWithValue("rate", BeNumerically("==", 7)))
+		Expect(written).To(HaveKeyWithValue("adaptive", false))
+		Expect(written).To(HaveKeyWithValue("collector", ConsistOf("defaults")))
+		Expect(written).NotTo(HaveKey(configFlag))
+		Expect(written).NotTo(HaveKey(dumpConfigFlag))
+		Expect(written).To(HaveKeyWithValue(collectorsKey, HaveKeyWithValue("GNSS", HaveKeyWithValue("rate",
+			BeNumerically("==", 5)))))
+
+		reloadCmd := newProfileTestCmd()
+		Expect(loadProfile(reloadCmd, writeTestProfile(out.String()))).To(Succeed())
+		Expect(reloadCmd.Flags().Lookup("rate").Value.String()).To(Equal("7"))
+	})
+})
+
+func TestCmd(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd Suite")
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/report"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+var (
+	reportInputs []string
+	reportJSON   bool
+)
+
+// reportCmd summarises the output of a collection
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarise collected data",
+	Long: `Summarise the output of collect written with --use-analyser-format.
+For each record ID and target it reports the time error statistics, time spent in
+each state, state transitions, gaps in sampling and the clockClass history.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		builder := report.NewBuilder()
+
+		for _, input := range reportInputs {
+			err := builder.ReadFile(input)
+			if err != nil {
+				utils.IfErrorExitOrPanic(utils.NewMissingInputError(err))
+			}
+		}
+
+		var writer io.Writer = os.Stdout
+
+		if outputFile != "" {
+			file, err := os.Create(outputFile)
+			utils.IfErrorExitOrPanic(err)
+			defer file.Close()
+
+			writer = file
+		}
+
+		summary := builder.Report()
+
+		var err error
+		if reportJSON {
+			err = summary.WriteJSON(writer)
+		} else {
+			err = summary.WriteText(writer)
+		}
+
+		if err != nil {
+			utils.IfErrorExitOrPanic(fmt.Errorf("failed to write report: %w", err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().StringArrayVarP(&reportInputs, "input", "f", []string{},
+		"Path to a file written by collect, can be given more than once for rotated files")
+	err := reportCmd.MarkFlagRequired("input")
+	utils.IfErrorExitOrPanic(err)
+
+	reportCmd.Flags().BoolVar(&reportJSON, "json", false, "Write the report as JSON")
+	AddOutputFlag(reportCmd)
+}
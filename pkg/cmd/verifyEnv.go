@@ -13,6 +13,8 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/verify"
 )
 
+var checkJamming bool
+
 var envCmd = &cobra.Command{
 	Use:   "env",
 	Short: "environment based actions",
@@ -31,7 +33,10 @@ var verifyEnvCmd = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "Error: Invalid clock type '%s'. Must be either '%s' or '%s'\n", clockType, constants.ClockTypeGM, constants.ClockTypeBC)
 			os.Exit(1)
 		}
-		verify.Verify(ptpInterface, kubeConfig, useAnalyserJSON, nodeName, clockTypeUpper)
+		stopExecSession := startExecSession()
+		defer stopExecSession()
+
+		verify.Verify(ptpInterface, kubeConfig, useAnalyserJSON, nodeName, clockTypeUpper, checkJamming)
 	},
 }
 
@@ -44,4 +49,8 @@ func init() {
 	AddInterfaceFlag(verifyEnvCmd)
 	AddNodeNameFlag(verifyEnvCmd)
 	AddClockTypeFlag(verifyEnvCmd)
+	AddRecordReplayFlags(verifyEnvCmd)
+
+	verifyEnvCmd.Flags().BoolVar(&checkJamming, "check-jamming", false,
+		"Also fail if the GNSS receiver shows a high jamming indicator or critical signs of jamming")
 }
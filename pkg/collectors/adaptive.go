@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+const (
+	// Number of quiet polls before the interval is doubled back towards the configured interval
+	adaptiveSettlePolls = 10
+)
+
+// ActivityReporter is implemented by collected values which can tell
+// whether something has happened since a previous value was collected
+type ActivityReporter interface {
+	// IsActive returns true if the state has changed since previous
+	// or an offset is beyond offsetThreshold nanoseconds
+	IsActive(previous callbacks.OutputType, offsetThreshold float64) bool
+}
+
+// AdaptivePolling controls whether collectors poll faster while there is activity
+type AdaptivePolling struct {
+	FastInterval    time.Duration
+	OffsetThreshold float64
+	Enabled         bool
+}
+
+// adaptiveInterval drops to the fast interval whenever a poll shows activity
+// and steps back up to the base interval once things have been quiet for a while
+type adaptiveInterval struct {
+	lastOutput callbacks.OutputType
+	settings   AdaptivePolling
+	base       time.Duration
+	current    time.Duration
+	quietPolls int
+	mu         sync.Mutex
+}
+
+func newAdaptiveInterval(base time.Duration, settings AdaptivePolling) *adaptiveInterval {
+	if settings.FastInterval <= 0 || settings.FastInterval > base {
+		settings.FastInterval = base
+	}
+
+	return &adaptiveInterval{
+		settings: settings,
+		base:     base,
+		current:  base,
+	}
+}
+
+func (ai *adaptiveInterval) get() time.Duration {
+	ai.mu.Lock()
+	defer ai.mu.Unlock()
+
+	return ai.current
+}
+
+// update adjusts the interval based on a newly collected output
+func (ai *adaptiveInterval) update(name string, output callbacks.OutputType) {
+	reporter, ok := output.(ActivityReporter)
+	if !ok {
+		return
+	}
+
+	ai.mu.Lock()
+	defer ai.mu.Unlock()
+
+	previous := ai.lastOutput
+	ai.lastOutput = output
+
+	if reporter.IsActive(previous, ai.settings.OffsetThreshold) {
+		ai.quietPolls = 0
+
+		if ai.current != ai.settings.FastInterval {
+			log.Infof("%s saw activity, polling every %s", name, ai.settings.FastInterval)
+			ai.current = ai.settings.FastInterval
+		}
+
+		return
+	}
+
+	ai.quietPolls++
+	if ai.quietPolls < adaptiveSettlePolls || ai.current == ai.base {
+		return
+	}
+
+	ai.quietPolls = 0
+	ai.current = min(ai.current*2, ai.base) //nolint:mnd // doubling back towards the base interval
+
+	log.Infof("%s is quiet, polling every %s", name, ai.current)
+}
@@ -6,8 +6,13 @@ import (
 	"fmt"
 	"time"
 
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/alarms"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/interference"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
@@ -23,23 +28,35 @@ type Collector interface {
 type CollectionConstructor struct {
 	Callback               callbacks.Callback
 	Clientset              *clients.Clientset
+	Metrics                *metrics.Exporter
+	Adaptive               AdaptivePolling
+	SampleLatency          SampleLatencyBound
+	Alarms                 *alarms.Engine
+	Interference           *interference.Detector
+	Target                 *Target
+	Targets                []Target
 	ErroredPolls           chan PollResult
+	CollectorPollIntervals map[string]int
 	TempDir                string
 	PTPNodeName            string
 	LogsOutputFile         string
 	PTPInterface           string
 	ClockType              string
+	GNSSDecoder            string
+	HelperPath             string
 	PollInterval           int
 	DevInfoAnnouceInterval int
 	IncludeLogTimestamps   bool
 	KeepDebugFiles         bool
 	UnmanagedDebugPod      bool
+	DPLLAllCards           bool
+	DebugPodPerNode        bool
 }
 
 func NewCollectionConstructor(
 	kubeConfig string,
-	useAnalyserJSON bool,
-	outputFile string,
+	outputs []callbacks.Sink,
+	outputOptions callbacks.FileOptions,
 	ptpInterface string,
 	ptpNodeName string,
 	logsOutputFile string,
@@ -50,25 +67,63 @@ func NewCollectionConstructor(
 	keepDebugFiles bool,
 	unmanagedDebugPod bool,
 	clockType string,
+	metricsAddress string,
+	alarmRules []*alarms.Rule,
+	targets []Target,
+	collectorPollIntervals map[string]int,
+	adaptive AdaptivePolling,
+	sampleLatency SampleLatencyBound,
+	gnssDecoder string,
+	interferenceThresholds *interference.Thresholds,
+	dpllAllCards bool,
+	helperPath string,
 ) (*CollectionConstructor, error) {
-	clientset, err := clients.GetClientset(kubeConfig)
+	var clientset *clients.Clientset
+
+	// When replaying there is no cluster so collectors must only rely on exec contexts
+	if !clients.IsReplaying() {
+		var err error
+
+		clientset, err = clients.GetClientset(kubeConfig)
+		if err != nil {
+			return &CollectionConstructor{}, fmt.Errorf("failed to create constructor values: %w", err)
+		}
+	}
+
+	callback, err := callbacks.SetupCallback(outputs, outputOptions)
 	if err != nil {
 		return &CollectionConstructor{}, fmt.Errorf("failed to create constructor values: %w", err)
 	}
 
-	outputFormat := callbacks.Raw
-	if useAnalyserJSON {
-		outputFormat = callbacks.AnalyserJSON
+	var detector *interference.Detector
+
+	if interferenceThresholds != nil {
+		// Interference events are written to the unobserved callback so they are not checked themselves
+		detector = interference.NewDetector(*interferenceThresholds, callback)
+		callback = callbacks.NewObservedCallback(callback, detector)
 	}
 
-	callback, err := callbacks.SetupCallback(outputFile, outputFormat)
-	if err != nil {
-		return &CollectionConstructor{}, fmt.Errorf("failed to create constructor values: %w", err)
+	var engine *alarms.Engine
+
+	if len(alarmRules) > 0 {
+		// Alarm events are written to the unobserved callback so they are not evaluated themselves
+		engine = alarms.NewEngine(alarmRules, callback)
+		callback = callbacks.NewObservedCallback(callback, engine)
+	}
+
+	var exporter *metrics.Exporter
+
+	if metricsAddress != "" {
+		exporter = metrics.NewExporter(metricsAddress)
+		callback = callbacks.NewObservedCallback(callback, exporter)
 	}
 
 	return &CollectionConstructor{
 		Callback:               callback,
 		Clientset:              clientset,
+		Metrics:                exporter,
+		Alarms:                 engine,
+		Interference:           detector,
 		PTPInterface:           ptpInterface,
 		PTPNodeName:            ptpNodeName,
 		LogsOutputFile:         logsOutputFile,
@@ -79,16 +134,46 @@ func NewCollectionConstructor(
 		KeepDebugFiles:         keepDebugFiles,
 		UnmanagedDebugPod:      unmanagedDebugPod,
 		ClockType:              clockType,
+		Targets:                targets,
+		CollectorPollIntervals: collectorPollIntervals,
+		Adaptive:               adaptive,
+		SampleLatency:          sampleLatency,
+		GNSSDecoder:            gnssDecoder,
+		DPLLAllCards:           dpllAllCards,
+		HelperPath:             helperPath,
 	}, nil
 }
 
+// GetPollInterval returns the poll interval for a collector, which is PollInterval unless it has been overridden
+func (constructor *CollectionConstructor) GetPollInterval(collectorName string) int {
+	return constructor.getPollIntervalOr(collectorName, constructor.PollInterval)
+}
+
+func (constructor *CollectionConstructor) getPollIntervalOr(collectorName string, fallback int) int {
+	if interval, ok := constructor.CollectorPollIntervals[collectorName]; ok {
+		return interval
+	}
+
+	return fallback
+}
+
+// SampleLatencyBound flags or drops samples whose round trip took longer than Max
+type SampleLatencyBound struct {
+	Max    time.Duration
+	Reject bool
+}
+
 type PollResult struct {
 	CollectorName string
-	Errors        []error
+	// InstanceName is set by the runner to tell apart the instances of a collector on different targets
+	InstanceName string
+	Errors       []error
 }
 
 type baseCollector struct {
 	callback     callbacks.Callback
+	adaptive     *adaptiveInterval
+	latencyBound SampleLatencyBound
 	poller       func() (callbacks.OutputType, error)
 	name         string
 	callbackTag  string
@@ -98,9 +183,51 @@ type baseCollector struct {
 }
 
 func (base *baseCollector) GetPollInterval() time.Duration {
+	if base.adaptive != nil {
+		return base.adaptive.get()
+	}
+
 	return base.pollInterval
 }
 
+// enableAdaptivePolling lets the poll interval drop while the collected values show activity
+func (base *baseCollector) enableAdaptivePolling(settings AdaptivePolling) {
+	if settings.Enabled {
+		base.adaptive = newAdaptiveInterval(base.pollInterval, settings)
+	}
+}
+
+// boundSampleLatency flags or drops samples whose round trip exceeds the bound
+func (base *baseCollector) boundSampleLatency(bound SampleLatencyBound) {
+	base.latencyBound = bound
+}
+
+// checkSampleTiming fills in the poll delay of a sample and returns false if it should be dropped
+func (base *baseCollector) checkSampleTiming(result callbacks.OutputType, requestedAt time.Time) bool {
+	timed, ok := result.(callbacks.TimedSample)
+	if !ok || timed.GetSampleTiming() == nil {
+		return true
+	}
+
+	// The sample is taken to be from the middle of the round trip
+	timing := timed.GetSampleTiming()
+	sampledAt := timing.SentAt.Add(timing.ExecLatency / 2) //nolint:mnd // half of the round trip
+	timing.PollDelay = sampledAt.Sub(requestedAt)
+
+	if base.latencyBound.Max <= 0 || timing.ExecLatency <= base.latencyBound.Max {
+		return true
+	}
+
+	if base.latencyBound.Reject {
+		log.Warnf("dropping %s sample as round trip %s exceeded %s", base.name, timing.ExecLatency, base.latencyBound.Max)
+		return false
+	}
+
+	timing.Unreliable = true
+
+	return true
+}
+
 func (base *baseCollector) IsAnnouncer() bool {
 	return base.isAnnouncer
 }
@@ -120,11 +247,21 @@ func (base *baseCollector) CleanUp() error {
 }
 
 func (base *baseCollector) poll() error {
+	requestedAt := time.Now()
+
 	result, err := base.poller()
 	if err != nil {
 		return fmt.Errorf("failed to fetch  %s %w", base.callbackTag, err)
 	}
 
+	if !base.checkSampleTiming(result, requestedAt) {
+		return nil
+	}
+
+	if base.adaptive != nil {
+		base.adaptive.update(base.name, result)
+	}
+
 	err = base.callback.Call(result, gpsNavKey)
 	if err != nil {
 		return fmt.Errorf("callback failed %w", err)
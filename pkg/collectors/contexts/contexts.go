@@ -31,6 +31,32 @@ func GetNetlinkDebugContainerImage() string {
 }
 
 func GetPTPDaemonContext(clientset *clients.Clientset, ptpNodeName string) (clients.ExecContext, error) {
+	name := clients.ExecContextName(PTPNamespace, PTPPodNamePrefix, PTPContainer, ptpNodeName)
+
+	return clients.GetSessionExecContext(name, func() (clients.ExecContext, error) { //nolint:wrapcheck // errors are wrapped
+		ctx, err := clients.NewContainerContext(clientset, PTPNamespace, PTPPodNamePrefix, PTPContainer, ptpNodeName)
+		if err != nil {
+			return ctx, fmt.Errorf("could not create container context %w", err)
+		}
+
+		return ctx, nil
+	})
+}
+
+// GetGPSDContext returns a context for the gpsd container of the PTP daemon pod. It is not
+// shared through the exec session as it is used to stream output rather than run commands.
+func GetGPSDContext(clientset *clients.Clientset, ptpNodeName string) (*clients.ContainerExecContext, error) {
+	ctx, err := clients.NewContainerContext(clientset, PTPNamespace, PTPPodNamePrefix, GPSContainer, ptpNodeName)
+	if err != nil {
+		return ctx, fmt.Errorf("could not create container context %w", err)
+	}
+
+	return ctx, nil
+}
+
+// GetPTPDaemonStreamContext returns a context for the PTP daemon container which, like
+// GetGPSDContext, is not shared through the exec session as it is used to stream output.
+func GetPTPDaemonStreamContext(clientset *clients.Clientset, ptpNodeName string) (*clients.ContainerExecContext, error) {
 	ctx, err := clients.NewContainerContext(clientset, PTPNamespace, PTPPodNamePrefix, PTPContainer, ptpNodeName)
 	if err != nil {
 		return ctx, fmt.Errorf("could not create container context %w", err)
@@ -39,17 +65,45 @@ func GetPTPDaemonContext(clientset *clients.Clientset, ptpNodeName string) (clie
 	return ctx, nil
 }
 
+// netlinkDebugPodName returns the name of the debug pod, when collecting from several nodes at once
+// the node is added so the pods do not clash. A pod which is not managed by this tool keeps the
+// plain name as that is the pod the user was asked to create.
+func netlinkDebugPodName(ptpNodeName string, unmanagedDebugPod, podPerNode bool) string {
+	if ptpNodeName == "" || unmanagedDebugPod || !podPerNode {
+		return NetlinkDebugPod
+	}
+
+	return NetlinkDebugPod + "-" + ptpNodeName
+}
+
+// GetNetlinkContext returns a context for the netlink debug pod on ptpNodeName,
+// podPerNode should be set when collecting from more than one node
 func GetNetlinkContext(
 	clientset *clients.Clientset,
 	ptpNodeName string,
 	unmanagedDebugPod bool,
+	podPerNode bool,
+) (clients.PodExecContext, error) {
+	podName := netlinkDebugPodName(ptpNodeName, unmanagedDebugPod, podPerNode)
+	name := clients.ExecContextName(PTPNamespace, podName, NetlinkDebugContainer, ptpNodeName)
+
+	return clients.GetSessionPodExecContext(name, func() (clients.PodExecContext, error) { //nolint:wrapcheck // errors are wrapped
+		return newNetlinkContext(clientset, podName, ptpNodeName, unmanagedDebugPod)
+	})
+}
+
+func newNetlinkContext(
+	clientset *clients.Clientset,
+	podName string,
+	ptpNodeName string,
+	unmanagedDebugPod bool,
 ) (*clients.ContainerCreationExecContext, error) {
 	hpt := corev1.HostPathDirectory
 
 	ctx, err := clients.NewContainerCreationExecContext(
 		clientset,
 		PTPNamespace,
-		NetlinkDebugPod,
+		podName,
 		NetlinkDebugContainer,
 		GetNetlinkDebugContainerImage(),
 		map[string]string{},
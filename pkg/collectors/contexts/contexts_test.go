@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package contexts_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeK8s "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+func runningDebugPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: contexts.PTPNamespace},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Containers: []corev1.Container{
+				{Name: contexts.NetlinkDebugContainer, Image: contexts.GetNetlinkDebugContainerImage()},
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+var _ = Describe("GetNetlinkContext", func() {
+	DescribeTable("finding the running debug pod",
+		func(podName string, unmanagedDebugPod, podPerNode bool) {
+			pod := runningDebugPod(podName)
+			clientset := testutils.GetMockedClientSet(pod)
+
+			// The fake clientset ignores field selectors so pods are only listed when their name is selected
+			fakeClient, ok := clientset.K8sClient.(*fakeK8s.Clientset)
+			Expect(ok).To(BeTrue())
+			fakeClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				pods := &corev1.PodList{}
+
+				listAction, ok := action.(k8stesting.ListAction)
+				Expect(ok).To(BeTrue())
+				if name, _ := listAction.GetListRestrictions().Fields.RequiresExactMatch("metadata.name"); name == pod.Name {
+					pods.Items = append(pods.Items, *pod)
+				}
+
+				return true, pods, nil
+			})
+
+			ctx, err := contexts.GetNetlinkContext(clientset, "node-1", unmanagedDebugPod, podPerNode)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctx.CreatePodAndWait()).To(Succeed())
+		},
+		Entry("with the plain name for a single node", contexts.NetlinkDebugPod, false, false),
+		Entry("with the node in the name for several nodes", contexts.NetlinkDebugPod+"-node-1", false, true),
+		Entry("with the plain name when unmanaged", contexts.NetlinkDebugPod, true, false),
+		Entry("with the plain name when unmanaged for several nodes", contexts.NetlinkDebugPod, true, true),
+	)
+})
+
+func TestContexts(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Contexts Suite")
+}
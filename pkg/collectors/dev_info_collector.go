@@ -163,7 +163,7 @@ func NewDevInfoCollector(constructor *CollectionConstructor) (Collector, error)
 
 	collector := &DevInfoCollector{
 		baseCollector: newBaseCollector(
-			constructor.DevInfoAnnouceInterval,
+			constructor.getPollIntervalOr(DevInfoCollectorName, constructor.DevInfoAnnouceInterval),
 			true,
 			constructor.Callback,
 			DevInfoCollectorName,
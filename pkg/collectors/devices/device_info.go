@@ -17,9 +17,12 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/constants"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
 type PTPDeviceInfo struct {
+	callbacks.Timed
+
 	Timestamp       string        `fetcherKey:"date"            json:"date"`
 	VendorID        string        `fetcherKey:"vendorID"        json:"vendorId"`
 	DeviceID        string        `fetcherKey:"devID"           json:"deviceInfo"`
@@ -80,12 +83,12 @@ func init() {
 func extractOffsetFromTimestamp(result map[string]string) (map[string]any, error) {
 	processedResult := make(map[string]any, 0)
 
-	timestamp, err := time.Parse(time.RFC3339Nano, result["date"])
+	offset, err := utils.ClockOffset(result["date"], time.Now())
 	if err != nil {
-		return processedResult, fmt.Errorf("failed to parse timestamp  %w", err)
+		return processedResult, err //nolint:wrapcheck // the error says what failed to parse
 	}
 
-	processedResult["timeOffset"] = time.Since(timestamp)
+	processedResult["timeOffset"] = offset
 
 	return processedResult, nil
 }
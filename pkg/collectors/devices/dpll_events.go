@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+const (
+	dpllEventDevicePrefix = "device-"
+	dpllEventPinPrefix    = "pin-"
+)
+
+// DPLLEventStreamCommand prints each DPLL netlink notification as a line of JSON as it arrives
+// using this tool at helperPath
+func DPLLEventStreamCommand(helperPath string) []string {
+	return []string{helperPath, "dpll", "--subscribe", "monitor"}
+}
+
+// DPLLEvent is a device or pin notification from the DPLL netlink monitor group
+type DPLLEvent struct {
+	Device *NetlinkStateEntry
+	Pin    *NetlinkPin
+	// Timestamp is when the notification reached the node
+	Timestamp string
+	// Name is the notification, such as device-change-ntf or pin-change-ntf
+	Name string
+}
+
+// ParseDPLLEvent decodes one line of the dpll --subscribe monitor output
+func ParseDPLLEvent(line []byte) (*DPLLEvent, error) {
+	notification := struct {
+		Timestamp time.Time       `json:"timestamp"`
+		Name      string          `json:"name"`
+		Msg       json.RawMessage `json:"msg"`
+	}{}
+
+	err := json.Unmarshal(line, &notification)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DPLL notification %w", err)
+	}
+
+	event := &DPLLEvent{
+		Name:      notification.Name,
+		Timestamp: notification.Timestamp.UTC().Format(time.RFC3339Nano),
+	}
+
+	switch {
+	case strings.HasPrefix(notification.Name, dpllEventPinPrefix):
+		event.Pin = &NetlinkPin{}
+		err = json.Unmarshal(notification.Msg, event.Pin)
+	case strings.HasPrefix(notification.Name, dpllEventDevicePrefix):
+		event.Device = &NetlinkStateEntry{}
+		err = json.Unmarshal(notification.Msg, event.Device)
+	default:
+		return nil, fmt.Errorf("unknown DPLL notification %s", notification.Name)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DPLL %s %w", notification.Name, err)
+	}
+
+	return event, nil
+}
+
+func (event *DPLLEvent) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	data := map[string]any{
+		"timestamp": event.Timestamp,
+		"event":     event.Name,
+	}
+
+	if event.Device != nil {
+		data["clockId"] = event.Device.ClockID
+		data["deviceId"] = event.Device.ID
+		data["deviceType"] = event.Device.ClockType
+		data["lockStatus"] = event.Device.LockStatus
+		data["mode"] = event.Device.Mode
+	}
+
+	if event.Pin != nil {
+		parents := make([]map[string]any, 0, len(event.Pin.ParentDevices))
+		for _, parent := range event.Pin.ParentDevices {
+			parents = append(parents, map[string]any{
+				"parentId":    parent.ParentID,
+				"direction":   parent.Direction,
+				"state":       parent.State,
+				"prio":        parent.Prio,
+				"phaseOffset": convertNetlinkOffset(parent.PhaseOffset),
+			})
+		}
+
+		data["clockId"] = event.Pin.ClockID
+		data["pinId"] = event.Pin.ID
+		data["label"] = event.Pin.Label
+		data["pinType"] = event.Pin.Type
+		data["frequency"] = event.Pin.Frequency
+		data["parentDevices"] = parents
+	}
+
+	return []*callbacks.AnalyserFormatType{{ID: "dpll/event", Data: data}}, nil
+}
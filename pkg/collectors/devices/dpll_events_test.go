@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+var _ = Describe("ParseDPLLEvent", func() {
+	It("should parse a device notification", func() {
+		event, err := devices.ParseDPLLEvent([]byte(`{"timestamp": "2023-06-16T11:49:47.058400123Z",
+			"name": "device-change-ntf", "msg": {"clock-id": 5799633565435100136, "id": 1,
+			"lock-status": "holdover", "mode": "automatic", "module-name": "ice", "type": "pps"}}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Pin).To(BeNil())
+
+		records, err := event.GetAnalyserFormat()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].ID).To(Equal("dpll/event"))
+		Expect(records[0].Data).To(HaveKeyWithValue("timestamp", "2023-06-16T11:49:47.058400123Z"))
+		Expect(records[0].Data).To(HaveKeyWithValue("event", "device-change-ntf"))
+		Expect(records[0].Data).To(HaveKeyWithValue("deviceType", "pps"))
+		Expect(records[0].Data).To(HaveKeyWithValue("lockStatus", "holdover"))
+	})
+
+	It("should parse a pin notification", func() {
+		event, err := devices.ParseDPLLEvent([]byte(`{"timestamp": "2023-06-16T12:49:47.0584+01:00",
+			"name": "pin-change-ntf", "msg": {"board-label": "GNSS-1PPS", "clock-id": 5799633565435100136,
+			"id": 3, "module-name": "ice", "type": "gnss", "parent-device": [
+			{"direction": "input", "parent-id": 1, "phase-offset": -1234000, "prio": 0, "state": "disconnected"}]}}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Device).To(BeNil())
+
+		records, err := event.GetAnalyserFormat()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records[0].Data).To(HaveKeyWithValue("timestamp", "2023-06-16T11:49:47.0584Z"))
+		Expect(records[0].Data).To(HaveKeyWithValue("label", "GNSS-1PPS"))
+		Expect(records[0].Data).To(HaveKeyWithValue("parentDevices", ConsistOf(And(
+			HaveKeyWithValue("state", "disconnected"),
+			HaveKeyWithValue("phaseOffset", -1.234),
+		))))
+	})
+
+	It("should reject unknown notifications", func() {
+		_, err := devices.ParseDPLLEvent([]byte(`{"name": "other-ntf", "msg": {}}`))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -5,6 +5,10 @@ package devices
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"math"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -13,97 +17,274 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
 )
 
 const (
 	unitConversionFactor = 100
+	iceDriver            = "ice"
 )
 
+var ptpPinFunctions = map[string]string{
+	"0": "none",
+	"1": "extts",
+	"2": "perout",
+	"3": "physync",
+}
+
+// PTPPinAssignment is what an SMA or U.FL pin of the PTP clock is assigned to
+type PTPPinAssignment struct {
+	Name     string `json:"name"`
+	Function string `json:"function"`
+	Channel  string `json:"channel"`
+}
+
 type DevFilesystemDPLLInfo struct {
-	Timestamp string  `fetcherKey:"date"          json:"timestamp"`
-	EECState  string  `fetcherKey:"dpll_0_state"  json:"eecstate"`
-	PPSState  string  `fetcherKey:"dpll_1_state"  json:"state"`
-	PPSOffset float64 `fetcherKey:"dpll_1_offset" json:"terror"`
+	callbacks.Timed
+
+	// Attributes has every DPLL attribute the driver exposes
+	Attributes map[string]string   `fetcherKey:"attributes" json:"attributes"`
+	EECOffset  *float64            `fetcherKey:"eecOffset"  json:"eecterror,omitempty"`
+	Pins       []*PTPPinAssignment `fetcherKey:"pins"       json:"pins"`
+	Timestamp  string              `fetcherKey:"date"       json:"timestamp"`
+	EECState   string              `fetcherKey:"eecState"   json:"eecstate"`
+	PPSState   string              `fetcherKey:"ppsState"   json:"state"`
+	EECRefPin  string              `fetcherKey:"eecRefPin"  json:"eecRefPin"`
+	PPSRefPin  string              `fetcherKey:"ppsRefPin"  json:"ppsRefPin"`
+	PPSOffset  float64             `fetcherKey:"ppsOffset"  json:"terror"`
+
+	offsetUnitsPerNs float64
+}
+
+// offsetNs converts an offset read from the driver to nanoseconds
+func (dpllInfo *DevFilesystemDPLLInfo) offsetNs(offset float64) float64 {
+	if dpllInfo.offsetUnitsPerNs == 0 {
+		return offset / unitConversionFactor
+	}
+
+	return offset / dpllInfo.offsetUnitsPerNs
 }
 
 // AnalyserJSON returns the json expected by the analysers
 func (dpllInfo *DevFilesystemDPLLInfo) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
-	formatted := callbacks.AnalyserFormatType{
-		ID: "dpll/time-error",
-		Data: map[string]any{
-			"timestamp": dpllInfo.Timestamp,
-			"eecstate":  dpllInfo.EECState,
-			"state":     dpllInfo.PPSState,
-			// Convert to nano seconds
-			"terror": dpllInfo.PPSOffset / unitConversionFactor,
-		},
+	pins := make([]map[string]any, 0, len(dpllInfo.Pins))
+	for _, pin := range dpllInfo.Pins {
+		pins = append(pins, map[string]any{"name": pin.Name, "function": pin.Function, "channel": pin.Channel})
 	}
 
+	data := map[string]any{
+		"timestamp":  dpllInfo.Timestamp,
+		"eecstate":   dpllInfo.EECState,
+		"state":      dpllInfo.PPSState,
+		"terror":     dpllInfo.offsetNs(dpllInfo.PPSOffset),
+		"eecRefPin":  dpllInfo.EECRefPin,
+		"ppsRefPin":  dpllInfo.PPSRefPin,
+		"pins":       pins,
+		"attributes": dpllInfo.Attributes,
+	}
+
+	if dpllInfo.EECOffset != nil {
+		data["eecterror"] = dpllInfo.offsetNs(*dpllInfo.EECOffset)
+	}
+
+	formatted := callbacks.AnalyserFormatType{ID: "dpll/time-error", Data: data}
+
 	return []*callbacks.AnalyserFormatType{&formatted}, nil
 }
 
+// GetMetrics returns the offsets and states to be exposed as metrics
+func (dpllInfo *DevFilesystemDPLLInfo) GetMetrics() []*metrics.Sample {
+	labels := map[string]string{"backend": "filesystem", "pin": dpllInfo.PPSRefPin, "clock_id": "", "pci_address": ""}
+	samples := []*metrics.Sample{
+		{
+			Name:   "dpll_pps_offset_ns",
+			Help:   "Phase offset of the PPS DPLL in nanoseconds",
+			Labels: labels,
+			Value:  dpllInfo.offsetNs(dpllInfo.PPSOffset),
+		},
+	}
+
+	if dpllInfo.EECOffset != nil {
+		samples = append(samples, &metrics.Sample{
+			Name:   "dpll_eec_offset_ns",
+			Help:   "Phase offset of the EEC DPLL in nanoseconds",
+			Labels: labels,
+			Value:  dpllInfo.offsetNs(*dpllInfo.EECOffset),
+		})
+	}
+
+	return append(samples, dpllStateSamples(labels, dpllInfo.EECState, dpllInfo.PPSState)...)
+}
+
+// IsActive returns true if a DPLL state has changed or the offset is beyond offsetThreshold
+func (dpllInfo *DevFilesystemDPLLInfo) IsActive(previous callbacks.OutputType, offsetThreshold float64) bool {
+	if math.Abs(dpllInfo.offsetNs(dpllInfo.PPSOffset)) > offsetThreshold {
+		return true
+	}
+
+	last, ok := previous.(*DevFilesystemDPLLInfo)
+
+	return ok && (last.EECState != dpllInfo.EECState || last.PPSState != dpllInfo.PPSState)
+}
+
+// dpllStateSamples returns the lock states as samples, states which are not numeric are skipped
+func dpllStateSamples(labels map[string]string, eecState, ppsState string) []*metrics.Sample {
+	samples := make([]*metrics.Sample, 0)
+
+	for name, state := range map[string]string{"dpll_eec_state": eecState, "dpll_pps_state": ppsState} {
+		value, err := strconv.ParseFloat(state, 64)
+		if err != nil {
+			continue
+		}
+
+		samples = append(samples, &metrics.Sample{
+			Name:   name,
+			Help:   "Lock state of the DPLL (-1 unknown, 0 invalid, 1 freerun, 2 locked, 3 locked-ho-acq, 4 holdover)",
+			Labels: labels,
+			Value:  value,
+		})
+	}
+
+	return samples
+}
+
 var (
 	dpllFSFetcher map[string]*fetcher.Fetcher
 )
 
 func init() {
 	dpllFSFetcher = make(map[string]*fetcher.Fetcher)
+
+	// The out-of-tree ice driver's layout
+	RegisterDPLLFilesystemLayout(&DPLLFilesystemLayout{
+		Driver:             iceDriver,
+		AttributeGlob:      "dpll_*",
+		PinsGlob:           "ptp/ptp*/pins/*",
+		EECState:           "dpll_0_state",
+		PPSState:           "dpll_1_state",
+		EECOffset:          "dpll_0_offset",
+		PPSOffset:          "dpll_1_offset",
+		EECRefPin:          "dpll_0_ref_pin",
+		PPSRefPin:          "dpll_1_ref_pin",
+		RequiredAttributes: []string{"dpll_0_state", "dpll_1_state", "dpll_1_offset"},
+		OffsetUnitsPerNs:   unitConversionFactor,
+	})
 }
 
-func postProcessDPLLFilesystem(result map[string]string) (map[string]any, error) {
-	processedResult := make(map[string]any)
+// parseSysfsValues splits the "<path>:<value>" lines grep prints for each file into values keyed by file name
+func parseSysfsValues(output string) map[string]string {
+	values := make(map[string]string)
 
-	offset, err := strconv.ParseFloat(result["dpll_1_offset"], 32)
-	if err != nil {
-		return processedResult, fmt.Errorf("failed converting dpll_1_offset %w to an int", err)
+	for line := range strings.SplitSeq(output, "\n") {
+		filePath, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+
+		values[path.Base(filePath)] = value
+	}
+
+	return values
+}
+
+// parsePTPPins returns the assignments of the PTP clock's pins, each of which reads as "<function> <channel>"
+func parsePTPPins(output string) []*PTPPinAssignment {
+	values := parseSysfsValues(output)
+	pins := make([]*PTPPinAssignment, 0, len(values))
+
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		fields := strings.Fields(values[name])
+		if len(fields) != 2 { //nolint:mnd // function and channel
+			log.Warnf("unexpected assignment %q for PTP pin %s", values[name], name)
+			continue
+		}
+
+		function, ok := ptpPinFunctions[fields[0]]
+		if !ok {
+			function = fields[0]
+		}
+
+		pins = append(pins, &PTPPinAssignment{Name: name, Function: function, Channel: fields[1]})
 	}
 
-	processedResult["dpll_1_offset"] = offset
+	return pins
+}
 
-	return processedResult, nil
+func buildPostProcessDPLLFilesystem(layout *DPLLFilesystemLayout) fetcher.PostProcessFuncType {
+	return func(result map[string]string) (map[string]any, error) {
+		processedResult := make(map[string]any)
+		attributes := parseSysfsValues(result["dpll-attributes"])
+
+		offset, err := strconv.ParseFloat(attributes[layout.PPSOffset], 32)
+		if err != nil {
+			return processedResult, fmt.Errorf("failed converting %s %w to an int", layout.PPSOffset, err)
+		}
+
+		processedResult["ppsOffset"] = offset
+
+		if eecOffset, ok := attributes[layout.EECOffset]; ok && layout.EECOffset != "" {
+			offset, err = strconv.ParseFloat(eecOffset, 32)
+			if err != nil {
+				return processedResult, fmt.Errorf("failed converting %s %w to an int", layout.EECOffset, err)
+			}
+
+			processedResult["eecOffset"] = &offset
+		}
+
+		processedResult["attributes"] = attributes
+		processedResult["eecState"] = attributes[layout.EECState]
+		processedResult["ppsState"] = attributes[layout.PPSState]
+		processedResult["eecRefPin"] = attributes[layout.EECRefPin]
+		processedResult["ppsRefPin"] = attributes[layout.PPSRefPin]
+		processedResult["pins"] = parsePTPPins(result["ptp-pins"])
+
+		return processedResult, nil
+	}
 }
 
 // BuildFilesystemDPLLInfoFetcher popluates the fetcher required for
-// collecting the DPLLInfo
-func BuildFilesystemDPLLInfoFetcher(interfaceName string) error { //nolint:dupl // Further dedup risks be too abstract or fragile
-	fetcherInst, err := fetcher.FetcherFactory(
-		[]*clients.Cmd{dateCmd},
-		[]fetcher.AddCommandArgs{
-			{
-				Key:     "dpll_0_state",
-				Command: fmt.Sprintf("cat /sys/class/net/%s/device/dpll_0_state", interfaceName),
-				Trim:    true,
-			},
-			{
-				Key:     "dpll_1_state",
-				Command: fmt.Sprintf("cat /sys/class/net/%s/device/dpll_1_state", interfaceName),
-				Trim:    true,
-			},
-			{
-				Key:     "dpll_1_offset",
-				Command: fmt.Sprintf("cat /sys/class/net/%s/device/dpll_1_offset", interfaceName),
-				Trim:    true,
-			},
+// collecting the DPLLInfo from the driver's sysfs layout
+func BuildFilesystemDPLLInfoFetcher(interfaceName string, layout *DPLLFilesystemLayout) error {
+	deviceDir := fmt.Sprintf("/sys/class/net/%s/device/", interfaceName)
+	commands := []fetcher.AddCommandArgs{
+		{
+			Key:     "dpll-attributes",
+			Command: "grep -s -H . " + deviceDir + layout.AttributeGlob,
+			Trim:    true,
 		},
-	)
+	}
+
+	if layout.PinsGlob != "" {
+		commands = append(commands, fetcher.AddCommandArgs{
+			Key:     "ptp-pins",
+			Command: "grep -s -H . " + deviceDir + layout.PinsGlob,
+			Trim:    true,
+		})
+	}
+
+	fetcherInst, err := fetcher.FetcherFactory([]*clients.Cmd{dateCmd}, commands)
 	if err != nil {
 		log.Errorf("failed to create fetcher for dpll: %s", err.Error())
 		return fmt.Errorf("failed to create fetcher for dpll: %w", err)
 	}
 
 	dpllFSFetcher[interfaceName] = fetcherInst
-	fetcherInst.SetPostProcessor(postProcessDPLLFilesystem)
+	fetcherInst.SetPostProcessor(buildPostProcessDPLLFilesystem(layout))
 
 	return nil
 }
 
 // GetDevDPLLFilesystemInfo returns the device DPLL info for an interface.
-func GetDevDPLLFilesystemInfo(ctx clients.ExecContext, interfaceName string) (*DevFilesystemDPLLInfo, error) {
-	dpllInfo := &DevFilesystemDPLLInfo{}
+func GetDevDPLLFilesystemInfo(
+	ctx clients.ExecContext,
+	interfaceName string,
+	layout *DPLLFilesystemLayout,
+) (*DevFilesystemDPLLInfo, error) {
+	dpllInfo := &DevFilesystemDPLLInfo{offsetUnitsPerNs: layout.OffsetUnitsPerNs}
 
 	fetcherInst, fetchedInstanceOk := dpllFSFetcher[interfaceName]
 	if !fetchedInstanceOk {
-		err := BuildFilesystemDPLLInfoFetcher(interfaceName)
+		err := BuildFilesystemDPLLInfoFetcher(interfaceName, layout)
 		if err != nil {
 			return dpllInfo, err
 		}
@@ -123,50 +304,9 @@ func GetDevDPLLFilesystemInfo(ctx clients.ExecContext, interfaceName string) (*D
 	return dpllInfo, nil
 }
 
+// IsDPLLFileSystemPresent returns true if the interface's device exposes its DPLLs in a registered sysfs layout
 func IsDPLLFileSystemPresent(ctx clients.ExecContext, interfaceName string) (bool, error) {
-	fetcherInst, err := fetcher.FetcherFactory(
-		[]*clients.Cmd{},
-		[]fetcher.AddCommandArgs{
-			{
-				Key:     "paths",
-				Command: fmt.Sprintf("ls -1 /sys/class/net/%s/device/", interfaceName),
-				Trim:    true,
-			},
-		},
-	)
-	if err != nil {
-		return false, fmt.Errorf("failed to build fetcher to check DPLL FS  %w", err)
-	}
-
-	type Paths struct {
-		Paths string `fetcherKey:"paths"`
-	}
-
-	paths := Paths{}
-	expected := map[string]bool{
-		"dpll_0_state":  false,
-		"dpll_1_state":  false,
-		"dpll_1_offset": false,
-	}
-
-	err = fetcherInst.Fetch(ctx, &paths)
-	if err != nil {
-		return false, fmt.Errorf("failed to check DPLL FS  %w", err)
-	}
-
-	for p := range strings.SplitSeq(paths.Paths, "\n") {
-		for expectedPath := range expected {
-			if strings.Trim(p, " ") == expectedPath {
-				expected[expectedPath] = true
-			}
-		}
-	}
-
-	for _, value := range expected {
-		if !value {
-			return false, nil
-		}
-	}
+	layout, err := DetectDPLLFilesystemLayout(ctx, interfaceName)
 
-	return true, nil
+	return layout != nil, err
 }
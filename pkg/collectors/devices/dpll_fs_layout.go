@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+)
+
+// DPLLFilesystemLayout describes where an out-of-tree driver exposes its DPLLs in sysfs.
+// Attribute names are relative to /sys/class/net/<interface>/device, an empty name means the driver does not expose it.
+type DPLLFilesystemLayout struct {
+	// Driver names the layout, such as ice
+	Driver string
+	// AttributeGlob matches every DPLL attribute of the driver, all of which are collected
+	AttributeGlob string
+	// PinsGlob matches the SMA and U.FL pin assignments of the PTP clock
+	PinsGlob  string
+	EECState  string
+	PPSState  string
+	EECOffset string
+	PPSOffset string
+	EECRefPin string
+	PPSRefPin string
+	// RequiredAttributes must all be present for the layout to be used
+	RequiredAttributes []string
+	// OffsetUnitsPerNs converts the offsets to nanoseconds
+	OffsetUnitsPerNs float64
+}
+
+var (
+	dpllFSLayoutsMu sync.Mutex
+	dpllFSLayouts   []*DPLLFilesystemLayout
+)
+
+// RegisterDPLLFilesystemLayout adds a driver's layout to those tried by DetectDPLLFilesystemLayout,
+// layouts are tried in the order they are registered
+func RegisterDPLLFilesystemLayout(layout *DPLLFilesystemLayout) {
+	dpllFSLayoutsMu.Lock()
+	defer dpllFSLayoutsMu.Unlock()
+
+	dpllFSLayouts = append(dpllFSLayouts, layout)
+}
+
+// UnregisterDPLLFilesystemLayout removes the layout of driver so that it is no longer tried
+func UnregisterDPLLFilesystemLayout(driver string) {
+	dpllFSLayoutsMu.Lock()
+	defer dpllFSLayoutsMu.Unlock()
+
+	dpllFSLayouts = slices.DeleteFunc(dpllFSLayouts, func(layout *DPLLFilesystemLayout) bool {
+		return layout.Driver == driver
+	})
+}
+
+// DetectDPLLFilesystemLayout returns the first registered layout the interface's device has all the attributes of,
+// or nil if the device does not expose its DPLLs in sysfs in a known layout
+func DetectDPLLFilesystemLayout(ctx clients.ExecContext, interfaceName string) (*DPLLFilesystemLayout, error) {
+	fetcherInst, err := fetcher.FetcherFactory(
+		[]*clients.Cmd{},
+		[]fetcher.AddCommandArgs{
+			{
+				Key:     "paths",
+				Command: fmt.Sprintf("ls -1 /sys/class/net/%s/device/", interfaceName),
+				Trim:    true,
+			},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build fetcher to check DPLL FS  %w", err)
+	}
+
+	type Paths struct {
+		Paths string `fetcherKey:"paths"`
+	}
+
+	paths := Paths{}
+
+	err = fetcherInst.Fetch(ctx, &paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check DPLL FS  %w", err)
+	}
+
+	present := make([]string, 0)
+	for p := range strings.SplitSeq(paths.Paths, "\n") {
+		present = append(present, strings.Trim(p, " "))
+	}
+
+	dpllFSLayoutsMu.Lock()
+	defer dpllFSLayoutsMu.Unlock()
+
+	for _, layout := range dpllFSLayouts {
+		if hasAll(present, layout.RequiredAttributes) {
+			log.Debugf("%s has the %s DPLL filesystem layout", interfaceName, layout.Driver)
+			return layout, nil
+		}
+	}
+
+	return nil, nil //nolint:nilnil // not having a known layout is not an error
+}
+
+func hasAll(present, required []string) bool {
+	for _, name := range required {
+		if !slices.Contains(present, name) {
+			return false
+		}
+	}
+
+	return true
+}
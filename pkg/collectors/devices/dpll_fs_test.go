@@ -39,32 +39,120 @@ var _ = Describe("NewContainerContext", func() {
 		}
 		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
 	})
+	detectLayout := func(interfaceName string, paths string) *devices.DPLLFilesystemLayout {
+		expectedInput := fmt.Sprintf("echo '<paths>';ls -1 /sys/class/net/%s/device/;echo '</paths>';", interfaceName)
+		response[expectedInput] = []byte("<paths>\n" + paths + "\n</paths>\n")
+
+		ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
+		Expect(err).NotTo(HaveOccurred())
+		layout, err := devices.DetectDPLLFilesystemLayout(ctx, interfaceName)
+		Expect(err).NotTo(HaveOccurred())
+
+		return layout
+	}
+	When("called DetectDPLLFilesystemLayout", func() {
+		It("should only return a layout when the device has its attributes", func() {
+			Expect(detectLayout("noDPLLInterface", "net\nptp\nvendor")).To(BeNil())
+
+			layout := detectLayout("iceInterface", "dpll_0_state\ndpll_1_offset\ndpll_1_state\nnet\nptp")
+			Expect(layout).NotTo(BeNil())
+			Expect(layout.Driver).To(Equal("ice"))
+		})
+		It("should return layouts registered for other drivers", func() {
+			devices.RegisterDPLLFilesystemLayout(&devices.DPLLFilesystemLayout{
+				Driver:             "test",
+				AttributeGlob:      "test_dpll_*",
+				PPSState:           "test_dpll_pps_state",
+				PPSOffset:          "test_dpll_pps_offset",
+				RequiredAttributes: []string{"test_dpll_pps_state", "test_dpll_pps_offset"},
+				OffsetUnitsPerNs:   1,
+			})
+			DeferCleanup(devices.UnregisterDPLLFilesystemLayout, "test")
+
+			layout := detectLayout("testInterface", "test_dpll_pps_offset\ntest_dpll_pps_state")
+			Expect(layout).NotTo(BeNil())
+			Expect(layout.Driver).To(Equal("test"))
+		})
+		It("should not return a layout once it is unregistered", func() {
+			devices.RegisterDPLLFilesystemLayout(&devices.DPLLFilesystemLayout{
+				Driver:             "unregistered",
+				AttributeGlob:      "unregistered_dpll_*",
+				RequiredAttributes: []string{"unregistered_dpll_state"},
+			})
+			devices.UnregisterDPLLFilesystemLayout("unregistered")
+
+			Expect(detectLayout("unregisteredInterface", "unregistered_dpll_state")).To(BeNil())
+		})
+	})
 	When("called GetDevDPLLInfo", func() {
 		It("should return a valid DevDPLLInfo", func() {
 			eecState := "2"
 			pssState := "10"
 			offset := float64(-34)
+			eecOffset := float64(1200)
+
+			layout := detectLayout("aFakeInterface", "dpll_0_state\ndpll_1_offset\ndpll_1_state")
 
 			expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
-			expectedInput += "echo '<dpll_0_state>';cat /sys/class/net/aFakeInterface/device/dpll_0_state;echo '</dpll_0_state>';"
-			expectedInput += "echo '<dpll_1_state>';cat /sys/class/net/aFakeInterface/device/dpll_1_state;echo '</dpll_1_state>';"
-			expectedInput += "echo '<dpll_1_offset>';cat /sys/class/net/aFakeInterface/device/dpll_1_offset;echo '</dpll_1_offset>';"
+			expectedInput += "echo '<dpll-attributes>';grep -s -H . /sys/class/net/aFakeInterface/device/dpll_*;" +
+				"echo '</dpll-attributes>';"
+			expectedInput += "echo '<ptp-pins>';grep -s -H . /sys/class/net/aFakeInterface/device/ptp/ptp*/pins/*;" +
+				"echo '</ptp-pins>';"
 
+			deviceDir := "/sys/class/net/aFakeInterface/device/"
 			expectedOutput := "<date>\n1686916187.0584\n</date>\n"
-			expectedOutput += fmt.Sprintf("<dpll_0_state>\n%s\n</dpll_0_state>\n", eecState)
-			expectedOutput += fmt.Sprintf("<dpll_1_state>\n%s\n</dpll_1_state>\n", pssState)
-			expectedOutput += fmt.Sprintf("<dpll_1_offset>\n%f\n</dpll_1_offset>\n", offset)
+			expectedOutput += "<dpll-attributes>\n"
+			expectedOutput += fmt.Sprintf("%sdpll_0_offset:%f\n", deviceDir, eecOffset)
+			expectedOutput += deviceDir + "dpll_0_ref_pin:SMA1\n"
+			expectedOutput += fmt.Sprintf("%sdpll_0_state:%s\n", deviceDir, eecState)
+			expectedOutput += fmt.Sprintf("%sdpll_1_offset:%f\n", deviceDir, offset)
+			expectedOutput += deviceDir + "dpll_1_ref_pin:GNSS-1PPS\n"
+			expectedOutput += fmt.Sprintf("%sdpll_1_state:%s\n", deviceDir, pssState)
+			expectedOutput += "</dpll-attributes>\n"
+			expectedOutput += "<ptp-pins>\n"
+			expectedOutput += deviceDir + "ptp/ptp1/pins/U.FL1:0 1\n"
+			expectedOutput += deviceDir + "ptp/ptp1/pins/SMA1:1 1\n"
+			expectedOutput += deviceDir + "ptp/ptp1/pins/SMA2:2 2\n"
+			expectedOutput += "</ptp-pins>\n"
 
 			response[expectedInput] = []byte(expectedOutput)
 
 			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
 			Expect(err).NotTo(HaveOccurred())
-			info, err := devices.GetDevDPLLFilesystemInfo(ctx, "aFakeInterface")
+			info, err := devices.GetDevDPLLFilesystemInfo(ctx, "aFakeInterface", layout)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(info.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
 			Expect(info.EECState).To(Equal(eecState))
 			Expect(info.PPSState).To(Equal(pssState))
 			Expect(info.PPSOffset).To(Equal(offset))
+			Expect(*info.EECOffset).To(Equal(eecOffset))
+			Expect(info.EECRefPin).To(Equal("SMA1"))
+			Expect(info.PPSRefPin).To(Equal("GNSS-1PPS"))
+			Expect(info.Attributes).To(HaveLen(6))
+			Expect(info.Pins).To(Equal([]*devices.PTPPinAssignment{
+				{Name: "SMA1", Function: "extts", Channel: "1"},
+				{Name: "SMA2", Function: "perout", Channel: "2"},
+				{Name: "U.FL1", Function: "none", Channel: "1"},
+			}))
+			Expect(info.GetSampleTiming()).NotTo(BeNil())
+			Expect(info.GetSampleTiming().ClockSkew).NotTo(BeNil())
+
+			records, err := info.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records[0].Data).To(HaveKeyWithValue("terror", -0.34))
+			Expect(records[0].Data).To(HaveKeyWithValue("eecterror", 12.0))
+		})
+	})
+	When("called IsActive", func() {
+		It("should report state changes and large offsets", func() {
+			locked := &devices.DevFilesystemDPLLInfo{EECState: "2", PPSState: "2", PPSOffset: 500}
+			holdover := &devices.DevFilesystemDPLLInfo{EECState: "2", PPSState: "4", PPSOffset: 500}
+			drifting := &devices.DevFilesystemDPLLInfo{EECState: "2", PPSState: "4", PPSOffset: -50000}
+
+			Expect(locked.IsActive(nil, 100)).To(BeFalse())
+			Expect(locked.IsActive(locked, 100)).To(BeFalse())
+			Expect(holdover.IsActive(locked, 100)).To(BeTrue())
+			Expect(drifting.IsActive(holdover, 100)).To(BeTrue())
 		})
 	})
 })
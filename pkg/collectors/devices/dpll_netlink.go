@@ -14,6 +14,7 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
@@ -41,15 +42,59 @@ const (
 	EECOffsetParentID      = 0
 	PPSOffesetParentID     = 1
 	DPLLPhaseOffsetDivider = 1000
+
+	ynlDPLLCommand = "/linux/tools/net/ynl/cli.py --spec /linux/Documentation/netlink/specs/dpll.yaml"
+	ynlJSONEncoder = " | python3 /root/custom_scripts/json_encoder.py"
 )
 
+// dpllNetlinkCommand returns the command which makes the request args of the DPLL netlink family.
+// It runs this tool's dpll command from helperPath, or ynl's cli.py when helperPath is empty.
+func dpllNetlinkCommand(helperPath, args string) string {
+	if helperPath == "" {
+		return ynlDPLLCommand + " " + args + ynlJSONEncoder
+	}
+
+	return helperPath + " dpll " + args
+}
+
+// dpllSerialNumberCommand returns the command which prints the serial number of the card of the interface,
+// with this tool from helperPath or lspci when helperPath is empty
+func dpllSerialNumberCommand(helperPath, interfaceName string) string {
+	if helperPath == "" {
+		return fmt.Sprintf(
+			`export IFNAME=%s; export BUSID=$(readlink /sys/class/net/$IFNAME/device | xargs basename | cut -d ':' -f 2,3);`+
+				` echo $(lspci -v | grep $BUSID -A 20 |grep 'Serial Number' | awk '{print $NF}' | tr -d '-')`,
+			interfaceName,
+		)
+	}
+
+	return helperPath + " dpll --serial-number " + interfaceName
+}
+
+func dpllDeviceDumpCommand(helperPath string) string {
+	return dpllNetlinkCommand(helperPath, "--dump device-get")
+}
+
+func dpllPinDumpCommand(helperPath string) string {
+	return dpllNetlinkCommand(helperPath, "--dump pin-get")
+}
+
+func dpllPinGetCommand(helperPath string, pinID int32) string {
+	return dpllNetlinkCommand(helperPath, fmt.Sprintf("--do pin-get --json '{\"id\": %d}'", pinID))
+}
+
 type DevNetlinkDPLLInfo struct {
-	PinType   string
-	Timestamp string `fetcherKey:"date"       json:"timestamp"`
-	EECState  string `fetcherKey:"eec"        json:"eecstate"`
-	PPSState  string `fetcherKey:"pps"        json:"state"`
-	PPSOffset int64  `fetcherKey:"pps_offset" json:"terror"`
-	EECOffset int64  `fetcherKey:"eec_offset" json:"eecterror"`
+	callbacks.Timed
+
+	PinType    string
+	Interface  string `json:"interface"`
+	PCIAddress string `json:"pciAddress"`
+	Timestamp  string `fetcherKey:"date"       json:"timestamp"`
+	EECState   string `fetcherKey:"eec"        json:"eecstate"`
+	PPSState   string `fetcherKey:"pps"        json:"state"`
+	PPSOffset  int64  `fetcherKey:"pps_offset" json:"terror"`
+	EECOffset  int64  `fetcherKey:"eec_offset" json:"eecterror"`
+	ClockID    uint64 `json:"clockId"`
 }
 
 func convertNetlinkOffset(offset int64) float64 {
@@ -76,14 +121,56 @@ func (dpllInfo *DevNetlinkDPLLInfo) GetAnalyserFormat() ([]*callbacks.AnalyserFo
 			"state":     dpllInfo.PPSState,
 			"terror":    convertNetlinkOffset(dpllInfo.PPSOffset),
 			"eecterror": convertNetlinkOffset(dpllInfo.EECOffset),
+			// Identifies the card when the DPLLs of several cards are collected together
+			"interface":  dpllInfo.Interface,
+			"pciAddress": dpllInfo.PCIAddress,
+			"clockId":    dpllInfo.ClockID,
 		},
 	}
 
 	return []*callbacks.AnalyserFormatType{&formatted}, nil
 }
 
+// GetMetrics returns the offsets and states to be exposed as metrics
+func (dpllInfo *DevNetlinkDPLLInfo) GetMetrics() []*metrics.Sample {
+	labels := map[string]string{
+		"backend":     "netlink",
+		"pin":         dpllInfo.PinType,
+		"clock_id":    strconv.FormatUint(dpllInfo.ClockID, 10),
+		"pci_address": dpllInfo.PCIAddress,
+	}
+	samples := []*metrics.Sample{
+		{
+			Name:   "dpll_pps_offset_ns",
+			Help:   "Phase offset of the PPS DPLL in nanoseconds",
+			Labels: labels,
+			Value:  convertNetlinkOffset(dpllInfo.PPSOffset),
+		},
+		{
+			Name:   "dpll_eec_offset_ns",
+			Help:   "Phase offset of the EEC DPLL in nanoseconds",
+			Labels: labels,
+			Value:  convertNetlinkOffset(dpllInfo.EECOffset),
+		},
+	}
+
+	return append(samples, dpllStateSamples(labels, dpllInfo.EECState, dpllInfo.PPSState)...)
+}
+
+// IsActive returns true if a DPLL state has changed or the PPS offset is beyond offsetThreshold
+func (dpllInfo *DevNetlinkDPLLInfo) IsActive(previous callbacks.OutputType, offsetThreshold float64) bool {
+	if math.Abs(convertNetlinkOffset(dpllInfo.PPSOffset)) > offsetThreshold {
+		return true
+	}
+
+	last, ok := previous.(*DevNetlinkDPLLInfo)
+
+	return ok && (last.EECState != dpllInfo.EECState || last.PPSState != dpllInfo.PPSState)
+}
+
 type NetlinkStateEntry struct {
 	LockStatus string `json:"lock-status"` //nolint:tagliatelle // not my choice
+	Mode       string `json:"mode"`        //nolint:tagliatelle // not my choice
 	Driver     string `json:"module-name"` //nolint:tagliatelle // not my choice
 	ClockType  string `json:"type"`        //nolint:tagliatelle // not my choice
 	ClockID    uint64 `json:"clock-id"`    //nolint:tagliatelle // not my choice
@@ -177,56 +264,73 @@ type NetlinkFrequencySupportedRange struct {
 // },
 
 var (
-	dpllNetlinkFetcher map[uint64]*fetcher.Fetcher
+	dpllNetlinkFetcher map[string]*fetcher.Fetcher
 	dpllClockIDFetcher map[string]*fetcher.Fetcher
 )
 
 func init() {
-	dpllNetlinkFetcher = make(map[uint64]*fetcher.Fetcher)
+	dpllNetlinkFetcher = make(map[string]*fetcher.Fetcher)
 	dpllClockIDFetcher = make(map[string]*fetcher.Fetcher)
 }
 
-func buildPostProcessDPLLNetlink(clockID uint64) fetcher.PostProcessFuncType {
-	return func(result map[string]string) (map[string]any, error) {
-		processedResult := make(map[string]any)
+// netlinkLockStates returns the lock state of each of the clock's DPLLs keyed by the DPLL type
+func netlinkLockStates(devicesJSON string, clockID uint64) map[string]string {
+	lockStates := make(map[string]string)
+	entries := make([]NetlinkStateEntry, 0)
 
-		entries := make([]NetlinkStateEntry, 0)
+	err := json.Unmarshal([]byte(devicesJSON), &entries)
+	if err != nil {
+		log.Errorf("Failed to unmarshal netlink device output: %s", err.Error())
+	}
 
-		err := json.Unmarshal([]byte(result["dpll-netlink-device"]), &entries)
-		if err != nil {
-			log.Errorf("Failed to unmarshal netlink device output: %s", err.Error())
+	log.Debug("entries: ", entries)
+
+	for _, entry := range entries {
+		if entry.ClockID == clockID {
+			state, ok := states[entry.LockStatus]
+			if !ok {
+				log.Errorf("Unknown state: %s", state)
+				state = "-1"
+			}
+
+			lockStates[entry.ClockType] = state
 		}
+	}
 
-		log.Debug("entries: ", entries)
+	return lockStates
+}
 
-		for _, entry := range entries {
-			if entry.ClockID == clockID {
-				state, ok := states[entry.LockStatus]
-				if !ok {
-					log.Errorf("Unknown state: %s", state)
-					state = "-1"
-				}
+// netlinkPhaseOffsets returns the phase offsets of the pin to the EEC and PPS DPLLs
+func netlinkPhaseOffsets(pinJSON string) (eecOffset, ppsOffset int64) {
+	pin := NetlinkPin{}
 
-				processedResult[entry.ClockType] = state
-			}
+	err := json.Unmarshal([]byte(pinJSON), &pin)
+	if err != nil {
+		log.Errorf("Failed to unmarshal netlink pin output: %s", err.Error())
+	}
+
+	for _, parentPin := range pin.ParentDevices {
+		switch parentPin.ParentID % 2 {
+		case EECOffsetParentID:
+			eecOffset = parentPin.PhaseOffset
+		case PPSOffesetParentID:
+			ppsOffset = parentPin.PhaseOffset
 		}
+	}
 
-		pin := NetlinkPin{}
+	return eecOffset, ppsOffset
+}
 
-		err = json.Unmarshal([]byte(result["dpll-netlink-offset"]), &pin)
-		if err != nil {
-			log.Errorf("Failed to unmarshal netlink pin output: %s", err.Error())
-		}
+func buildPostProcessDPLLNetlink(clockID uint64) fetcher.PostProcessFuncType {
+	return func(result map[string]string) (map[string]any, error) {
+		processedResult := make(map[string]any)
 
-		for _, parentPin := range pin.ParentDevices {
-			switch parentPin.ParentID % 2 {
-			case EECOffsetParentID:
-				processedResult["ecc_offset"] = parentPin.PhaseOffset
-			case PPSOffesetParentID:
-				processedResult["pps_offset"] = parentPin.PhaseOffset
-			}
+		for clockType, state := range netlinkLockStates(result["dpll-netlink-device"], clockID) {
+			processedResult[clockType] = state
 		}
 
+		processedResult["eec_offset"], processedResult["pps_offset"] = netlinkPhaseOffsets(result["dpll-netlink-offset"])
+
 		return processedResult, nil
 	}
 }
@@ -238,19 +342,14 @@ func BuildDPLLNetlinkDeviceFetcher(params NetlinkParameters) error { //nolint:du
 		[]*clients.Cmd{dateCmd},
 		[]fetcher.AddCommandArgs{
 			{
-				Key: "dpll-netlink-device",
-				Command: "/linux/tools/net/ynl/cli.py --spec /linux/Documentation/netlink/specs/dpll.yaml --dump device-get | " +
-					"python3 /root/custom_scripts/json_encoder.py",
-				Trim: true,
+				Key:     "dpll-netlink-device",
+				Command: dpllDeviceDumpCommand(params.HelperPath),
+				Trim:    true,
 			},
 			{
-				Key: "dpll-netlink-offset",
-				Command: fmt.Sprintf(
-					"/linux/tools/net/ynl/cli.py --spec /linux/Documentation/netlink/specs/dpll.yaml --do pin-get --json %s | "+
-						"python3 /root/custom_scripts/json_encoder.py",
-					fmt.Sprintf("'{\"id\": %d}'", params.OffsetPin),
-				),
-				Trim: true,
+				Key:     "dpll-netlink-offset",
+				Command: dpllPinGetCommand(params.HelperPath, params.OffsetPin),
+				Trim:    true,
 			},
 		},
 	)
@@ -259,7 +358,7 @@ func BuildDPLLNetlinkDeviceFetcher(params NetlinkParameters) error { //nolint:du
 		return fmt.Errorf("failed to create fetcher for dpll netlink: %w", err)
 	}
 
-	dpllNetlinkFetcher[params.ClockID] = fetcherInst
+	dpllNetlinkFetcher[netlinkFetcherKey(params)] = fetcherInst
 	fetcherInst.SetPostProcessor(buildPostProcessDPLLNetlink(params.ClockID))
 
 	return nil
@@ -267,16 +366,16 @@ func BuildDPLLNetlinkDeviceFetcher(params NetlinkParameters) error { //nolint:du
 
 // GetDevDPLLInfo returns the device DPLL info for an interface.
 func GetDevDPLLNetlinkInfo(ctx clients.ExecContext, params NetlinkParameters) (*DevNetlinkDPLLInfo, error) {
-	dpllInfo := &DevNetlinkDPLLInfo{PinType: params.PinType}
+	dpllInfo := newDevNetlinkDPLLInfo(params)
 
-	fetcherInst, fetchedInstanceOk := dpllNetlinkFetcher[params.ClockID]
+	fetcherInst, fetchedInstanceOk := dpllNetlinkFetcher[netlinkFetcherKey(params)]
 	if !fetchedInstanceOk {
 		err := BuildDPLLNetlinkDeviceFetcher(params)
 		if err != nil {
 			return dpllInfo, err
 		}
 
-		fetcherInst, fetchedInstanceOk = dpllNetlinkFetcher[params.ClockID]
+		fetcherInst, fetchedInstanceOk = dpllNetlinkFetcher[netlinkFetcherKey(params)]
 		if !fetchedInstanceOk {
 			return dpllInfo, errors.New("failed to create fetcher for DPLLInfo using netlink interface")
 		}
@@ -290,24 +389,29 @@ func GetDevDPLLNetlinkInfo(ctx clients.ExecContext, params NetlinkParameters) (*
 	return dpllInfo, nil
 }
 
-func BuildNetlinkInfoFetcher(interfaceName string) error {
+// netlinkFetcherKey tells apart the fetchers of a clock which make their requests in different ways
+func netlinkFetcherKey(params NetlinkParameters) string {
+	return params.HelperPath + ":" + strconv.FormatUint(params.ClockID, 16)
+}
+
+func BuildNetlinkInfoFetcher(interfaceName, helperPath string) error {
 	fetcherInst, err := fetcher.FetcherFactory(
 		[]*clients.Cmd{dateCmd},
 		[]fetcher.AddCommandArgs{
 			{
-				Key: "dpll-netlink-clock-serial-number",
-				Command: fmt.Sprintf(
-					`export IFNAME=%s; export BUSID=$(readlink /sys/class/net/$IFNAME/device | xargs basename | cut -d ':' -f 2,3);`+
-						` echo $(lspci -v | grep $BUSID -A 20 |grep 'Serial Number' | awk '{print $NF}' | tr -d '-')`,
-					interfaceName,
-				),
-				Trim: true,
+				Key:     "dpll-netlink-clock-serial-number",
+				Command: dpllSerialNumberCommand(helperPath, interfaceName),
+				Trim:    true,
+			},
+			{
+				Key:     "dpll-netlink-pci-address",
+				Command: fmt.Sprintf("basename $(readlink /sys/class/net/%s/device)", interfaceName),
+				Trim:    true,
 			},
 			{
-				Key: "dpll-netlink-pins",
-				Command: "/linux/tools/net/ynl/cli.py --spec /linux/Documentation/netlink/specs/dpll.yaml --dump pin-get | " +
-					"python3 /root/custom_scripts/json_encoder.py",
-				Trim: true,
+				Key:     "dpll-netlink-pins",
+				Command: dpllPinDumpCommand(helperPath),
+				Trim:    true,
 			},
 		},
 	)
@@ -317,7 +421,7 @@ func BuildNetlinkInfoFetcher(interfaceName string) error {
 	}
 
 	fetcherInst.SetPostProcessor(postProcessDPLLNetlinkClockID)
-	dpllClockIDFetcher[interfaceName] = fetcherInst
+	dpllClockIDFetcher[helperPath+":"+interfaceName] = fetcherInst
 
 	return nil
 }
@@ -390,6 +494,7 @@ func postProcessDPLLNetlinkClockID(result map[string]string) (map[string]any, er
 	}
 
 	processedResult["clockID"] = clockID
+	processedResult["pciAddress"] = result["dpll-netlink-pci-address"]
 
 	offsetPintID, pinType, err := selectPin([]byte(result["dpll-netlink-pins"]), clockID)
 	if err != nil {
@@ -403,23 +508,29 @@ func postProcessDPLLNetlinkClockID(result map[string]string) (map[string]any, er
 }
 
 type NetlinkParameters struct {
-	Timestamp string `fetcherKey:"date"      json:"timestamp"`
-	PinType   string `fetcherKey:"pinType"   json:"pinType"`
-	ClockID   uint64 `fetcherKey:"clockID"   json:"clockId"`
-	OffsetPin int32  `fetcherKey:"offsetPin" json:"offsetPin"`
+	Interface  string `json:"interface"`
+	Timestamp  string `fetcherKey:"date"       json:"timestamp"`
+	PinType    string `fetcherKey:"pinType"    json:"pinType"`
+	PCIAddress string `fetcherKey:"pciAddress" json:"pciAddress"`
+	ClockID    uint64 `fetcherKey:"clockID"    json:"clockId"`
+	OffsetPin  int32  `fetcherKey:"offsetPin"  json:"offsetPin"`
+	// HelperPath is where this tool is run from to make the netlink requests, ynl is used when it is empty
+	HelperPath string `json:"-"`
 }
 
-func GetNetlinkParameters(ctx clients.ExecContext, interfaceName string) (NetlinkParameters, error) {
-	netlinkInfo := NetlinkParameters{}
+// GetNetlinkParameters returns the clock and offset pin of the card of the interface,
+// making the netlink requests with this tool from helperPath or ynl when helperPath is empty
+func GetNetlinkParameters(ctx clients.ExecContext, interfaceName, helperPath string) (NetlinkParameters, error) {
+	netlinkInfo := NetlinkParameters{Interface: interfaceName, HelperPath: helperPath}
 
-	fetcherInst, fetchedInstanceOk := dpllClockIDFetcher[interfaceName]
+	fetcherInst, fetchedInstanceOk := dpllClockIDFetcher[helperPath+":"+interfaceName]
 	if !fetchedInstanceOk {
-		err := BuildNetlinkInfoFetcher(interfaceName)
+		err := BuildNetlinkInfoFetcher(interfaceName, helperPath)
 		if err != nil {
 			return netlinkInfo, err
 		}
 
-		fetcherInst, fetchedInstanceOk = dpllClockIDFetcher[interfaceName]
+		fetcherInst, fetchedInstanceOk = dpllClockIDFetcher[helperPath+":"+interfaceName]
 		if !fetchedInstanceOk {
 			return netlinkInfo, errors.New("failed to create fetcher for DPLLInfo using netlink interface")
 		}
@@ -432,3 +543,12 @@ func GetNetlinkParameters(ctx clients.ExecContext, interfaceName string) (Netlin
 
 	return netlinkInfo, nil
 }
+
+func newDevNetlinkDPLLInfo(params NetlinkParameters) *DevNetlinkDPLLInfo {
+	return &DevNetlinkDPLLInfo{
+		PinType:    params.PinType,
+		Interface:  params.Interface,
+		PCIAddress: params.PCIAddress,
+		ClockID:    params.ClockID,
+	}
+}
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
+)
+
+// DevNetlinkDPLLCards is the DPLL info of several cards taken by the same exec
+// so that the leader and follower cards can be compared at the same point in time
+type DevNetlinkDPLLCards struct {
+	callbacks.Timed
+
+	Timestamp string                `fetcherKey:"date"  json:"timestamp"`
+	Cards     []*DevNetlinkDPLLInfo `fetcherKey:"cards" json:"cards"`
+}
+
+// GetAnalyserFormat returns the time error record of every card
+func (cards *DevNetlinkDPLLCards) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted := make([]*callbacks.AnalyserFormatType, 0, len(cards.Cards))
+
+	for _, card := range cards.Cards {
+		cardFormatted, err := card.GetAnalyserFormat()
+		if err != nil {
+			return formatted, err
+		}
+
+		formatted = append(formatted, cardFormatted...)
+	}
+
+	return formatted, nil
+}
+
+// GetMetrics returns the offsets and states of every card, which are told apart by their clock_id label
+func (cards *DevNetlinkDPLLCards) GetMetrics() []*metrics.Sample {
+	samples := make([]*metrics.Sample, 0)
+
+	for _, card := range cards.Cards {
+		samples = append(samples, card.GetMetrics()...)
+	}
+
+	return samples
+}
+
+// IsActive returns true if any of the cards is active compared to the same card in previous
+func (cards *DevNetlinkDPLLCards) IsActive(previous callbacks.OutputType, offsetThreshold float64) bool {
+	lastCards := make(map[uint64]*DevNetlinkDPLLInfo)
+
+	if last, ok := previous.(*DevNetlinkDPLLCards); ok {
+		for _, card := range last.Cards {
+			lastCards[card.ClockID] = card
+		}
+	}
+
+	for _, card := range cards.Cards {
+		var lastCard callbacks.OutputType
+		if found, ok := lastCards[card.ClockID]; ok {
+			lastCard = found
+		}
+
+		if card.IsActive(lastCard, offsetThreshold) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var dpllNetlinkCardsFetcher map[string]*fetcher.Fetcher
+
+func init() {
+	dpllNetlinkCardsFetcher = make(map[string]*fetcher.Fetcher)
+}
+
+func cardsFetcherKey(cards []NetlinkParameters) string {
+	keys := make([]string, 0, len(cards))
+	for _, card := range cards {
+		keys = append(keys, netlinkFetcherKey(card))
+	}
+
+	return strings.Join(keys, ",")
+}
+
+func cardOffsetKey(card NetlinkParameters) string {
+	return "dpll-netlink-offset-" + strconv.FormatUint(card.ClockID, 16)
+}
+
+func buildPostProcessDPLLNetlinkCards(cards []NetlinkParameters) fetcher.PostProcessFuncType {
+	return func(result map[string]string) (map[string]any, error) {
+		processedCards := make([]*DevNetlinkDPLLInfo, 0, len(cards))
+
+		for _, card := range cards {
+			dpllInfo := newDevNetlinkDPLLInfo(card)
+			dpllInfo.Timestamp = result["date"]
+
+			lockStates := netlinkLockStates(result["dpll-netlink-device"], card.ClockID)
+			dpllInfo.EECState = lockStates["eec"]
+			dpllInfo.PPSState = lockStates["pps"]
+			dpllInfo.EECOffset, dpllInfo.PPSOffset = netlinkPhaseOffsets(result[cardOffsetKey(card)])
+
+			processedCards = append(processedCards, dpllInfo)
+		}
+
+		return map[string]any{"cards": processedCards}, nil
+	}
+}
+
+// BuildDPLLNetlinkCardsFetcher populates the fetcher which collects the DPLLInfo of all of the cards in one exec,
+// the device dump is shared by the cards so only each card's offset pin is fetched separately
+func BuildDPLLNetlinkCardsFetcher(cards []NetlinkParameters) error {
+	commands := []fetcher.AddCommandArgs{
+		{
+			Key:     "dpll-netlink-device",
+			Command: dpllDeviceDumpCommand(cards[0].HelperPath),
+			Trim:    true,
+		},
+	}
+
+	for _, card := range cards {
+		commands = append(commands, fetcher.AddCommandArgs{
+			Key:     cardOffsetKey(card),
+			Command: dpllPinGetCommand(card.HelperPath, card.OffsetPin),
+			Trim:    true,
+		})
+	}
+
+	fetcherInst, err := fetcher.FetcherFactory([]*clients.Cmd{dateCmd}, commands)
+	if err != nil {
+		log.Errorf("failed to create fetcher for dpll netlink cards: %s", err.Error())
+		return fmt.Errorf("failed to create fetcher for dpll netlink cards: %w", err)
+	}
+
+	fetcherInst.SetPostProcessor(buildPostProcessDPLLNetlinkCards(cards))
+	dpllNetlinkCardsFetcher[cardsFetcherKey(cards)] = fetcherInst
+
+	return nil
+}
+
+// GetDevDPLLNetlinkCards returns the DPLL info of every card
+func GetDevDPLLNetlinkCards(ctx clients.ExecContext, cards []NetlinkParameters) (*DevNetlinkDPLLCards, error) {
+	dpllCards := &DevNetlinkDPLLCards{}
+
+	fetcherInst, fetchedInstanceOk := dpllNetlinkCardsFetcher[cardsFetcherKey(cards)]
+	if !fetchedInstanceOk {
+		err := BuildDPLLNetlinkCardsFetcher(cards)
+		if err != nil {
+			return dpllCards, err
+		}
+
+		fetcherInst, fetchedInstanceOk = dpllNetlinkCardsFetcher[cardsFetcherKey(cards)]
+		if !fetchedInstanceOk {
+			return dpllCards, errors.New("failed to create fetcher for DPLLInfo of the cards using netlink interface")
+		}
+	}
+
+	err := fetcherInst.Fetch(ctx, dpllCards)
+	if err != nil {
+		return dpllCards, fmt.Errorf("failed to fetch dpllInfo of the cards via netlink: %w", err)
+	}
+
+	return dpllCards, nil
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"bufio"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+const (
+	leaderClockID   = uint64(5799633565435100136)
+	followerClockID = uint64(5799633565433967608)
+
+	dpllCardsDevicesJSON = `[
+		{"clock-id": 5799633565435100136, "id": 0, "lock-status": "locked-ho-acq", "module-name": "ice", "type": "eec"},
+		{"clock-id": 5799633565435100136, "id": 1, "lock-status": "locked-ho-acq", "module-name": "ice", "type": "pps"},
+		{"clock-id": 5799633565433967608, "id": 2, "lock-status": "locked", "module-name": "ice", "type": "eec"},
+		{"clock-id": 5799633565433967608, "id": 3, "lock-status": "holdover", "module-name": "ice", "type": "pps"}
+	]`
+	dpllLeaderPinJSON = `{"board-label": "GNSS-1PPS", "clock-id": 5799633565435100136, "id": 3, "parent-device": [
+		{"direction": "input", "parent-id": 0, "phase-offset": -1234000, "state": "connected"},
+		{"direction": "input", "parent-id": 1, "phase-offset": 56000, "state": "connected"}]}`
+	dpllFollowerPinJSON = `{"board-label": "SMA1", "clock-id": 5799633565433967608, "id": 20, "parent-device": [
+		{"direction": "input", "parent-id": 2, "phase-offset": 2000000, "state": "connected"},
+		{"direction": "input", "parent-id": 3, "phase-offset": -7000, "state": "connected"}]}`
+)
+
+var _ = Describe("GetDevDPLLNetlinkCards", func() {
+	var clientset *clients.Clientset
+	var response map[string][]byte
+	BeforeEach(func() { //nolint:dupl // this is test setup code
+		clientset = testutils.GetMockedClientSet(testPod)
+		response = make(map[string][]byte)
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			reader := bufio.NewReader(options.Stdin)
+			cmd := ""
+			keepReading := true
+			var cmdSb strings.Builder
+			for keepReading {
+				line, prefix, _ := reader.ReadLine()
+				keepReading = prefix
+				cmdSb.WriteString(string(line))
+			}
+			cmd += cmdSb.String()
+			return response[cmd], []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	})
+
+	cards := []devices.NetlinkParameters{
+		{
+			Interface: "ens7f0", PCIAddress: "0000:51:00.0", PinType: devices.OnePPSLabel,
+			ClockID: leaderClockID, OffsetPin: 3, HelperPath: "/opt/vse",
+		},
+		{
+			Interface: "ens8f0", PCIAddress: "0000:8a:00.0", PinType: devices.SMA1Label,
+			ClockID: followerClockID, OffsetPin: 20, HelperPath: "/opt/vse",
+		},
+	}
+
+	getCards := func() *devices.DevNetlinkDPLLCards {
+		pinGet := func(id string) string {
+			return "/opt/vse dpll --do pin-get --json '{\"id\": " + id + "}'"
+		}
+
+		expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
+		expectedInput += "echo '<dpll-netlink-device>';/opt/vse dpll --dump device-get" +
+			";echo '</dpll-netlink-device>';"
+		expectedInput += "echo '<dpll-netlink-offset-507c6fffff30fbe8>';" + pinGet("3") +
+			";echo '</dpll-netlink-offset-507c6fffff30fbe8>';"
+		expectedInput += "echo '<dpll-netlink-offset-507c6fffff1fb3f8>';" + pinGet("20") +
+			";echo '</dpll-netlink-offset-507c6fffff1fb3f8>';"
+
+		expectedOutput := "<date>\n1686916187.0584\n</date>\n"
+		expectedOutput += "<dpll-netlink-device>\n" + strings.ReplaceAll(dpllCardsDevicesJSON, "\n", "") +
+			"\n</dpll-netlink-device>\n"
+		expectedOutput += "<dpll-netlink-offset-507c6fffff30fbe8>\n" + strings.ReplaceAll(dpllLeaderPinJSON, "\n", "") +
+			"\n</dpll-netlink-offset-507c6fffff30fbe8>\n"
+		expectedOutput += "<dpll-netlink-offset-507c6fffff1fb3f8>\n" + strings.ReplaceAll(dpllFollowerPinJSON, "\n", "") +
+			"\n</dpll-netlink-offset-507c6fffff1fb3f8>\n"
+
+		response[expectedInput] = []byte(expectedOutput)
+
+		ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
+		Expect(err).NotTo(HaveOccurred())
+		dpllCards, err := devices.GetDevDPLLNetlinkCards(ctx, cards)
+		Expect(err).NotTo(HaveOccurred())
+
+		return dpllCards
+	}
+
+	When("called GetDevDPLLNetlinkCards", func() {
+		It("should return the states and offsets of each card from the same exec", func() {
+			dpllCards := getCards()
+			Expect(dpllCards.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(dpllCards.Cards).To(HaveLen(2))
+
+			leader := dpllCards.Cards[0]
+			Expect(leader.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(leader.EECState).To(Equal("3"))
+			Expect(leader.PPSState).To(Equal("3"))
+			Expect(leader.EECOffset).To(BeNumerically("==", -1234000))
+			Expect(leader.PPSOffset).To(BeNumerically("==", 56000))
+
+			follower := dpllCards.Cards[1]
+			Expect(follower.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(follower.EECState).To(Equal("2"))
+			Expect(follower.PPSState).To(Equal("4"))
+			Expect(follower.EECOffset).To(BeNumerically("==", 2000000))
+			Expect(follower.PPSOffset).To(BeNumerically("==", -7000))
+		})
+	})
+
+	When("called GetAnalyserFormat", func() {
+		It("should tag the record of each card with the card", func() {
+			records, err := getCards().GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(HaveLen(2))
+
+			Expect(records[0].ID).To(Equal("dpll/time-error"))
+			Expect(records[0].Data).To(HaveKeyWithValue("interface", "ens7f0"))
+			Expect(records[0].Data).To(HaveKeyWithValue("pciAddress", "0000:51:00.0"))
+			Expect(records[0].Data).To(HaveKeyWithValue("clockId", leaderClockID))
+			Expect(records[0].Data).To(HaveKeyWithValue("terror", 0.056))
+
+			Expect(records[1].ID).To(Equal("dpll-sma1/time-error"))
+			Expect(records[1].Data).To(HaveKeyWithValue("interface", "ens8f0"))
+			Expect(records[1].Data).To(HaveKeyWithValue("clockId", followerClockID))
+			Expect(records[1].Data).To(HaveKeyWithValue("state", "4"))
+			Expect(records[1].Data).To(HaveKeyWithValue("terror", -0.007))
+		})
+	})
+
+	When("called GetMetrics", func() {
+		It("should label the samples of each card with its clock ID", func() {
+			clockIDs := make(map[string]bool)
+
+			for _, sample := range getCards().GetMetrics() {
+				clockIDs[sample.Labels["clock_id"]] = true
+			}
+
+			Expect(clockIDs).To(HaveLen(2))
+			Expect(clockIDs).To(HaveKey("5799633565435100136"))
+			Expect(clockIDs).To(HaveKey("5799633565433967608"))
+		})
+	})
+})
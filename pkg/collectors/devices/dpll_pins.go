@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
+)
+
+// DPLLPins is the pin table of every DPLL device on the node
+type DPLLPins struct {
+	callbacks.Timed
+
+	Timestamp string               `fetcherKey:"date"    json:"timestamp"`
+	Devices   []*NetlinkStateEntry `fetcherKey:"devices" json:"devices"`
+	Pins      []*NetlinkPin        `fetcherKey:"pins"    json:"pins"`
+}
+
+var dpllPinsFetcher map[string]*fetcher.Fetcher
+
+func init() {
+	dpllPinsFetcher = make(map[string]*fetcher.Fetcher)
+}
+
+// BuildDPLLPinsFetcher populates the fetcher which collects the pin table,
+// making the netlink requests with this tool from helperPath or ynl when helperPath is empty
+func BuildDPLLPinsFetcher(helperPath string) error {
+	fetcherInst, err := fetcher.FetcherFactory(
+		[]*clients.Cmd{getDateCommand()},
+		[]fetcher.AddCommandArgs{
+			{
+				Key:     "dpll-netlink-devices",
+				Command: dpllDeviceDumpCommand(helperPath),
+				Trim:    true,
+			},
+			{
+				Key:     "dpll-netlink-pins",
+				Command: dpllPinDumpCommand(helperPath),
+				Trim:    true,
+			},
+		},
+	)
+	if err != nil {
+		log.Errorf("failed to create fetcher for dpll pins: %s", err.Error())
+		return fmt.Errorf("failed to create fetcher for dpll pins: %w", err)
+	}
+
+	fetcherInst.SetPostProcessor(processDPLLPins)
+	dpllPinsFetcher[helperPath] = fetcherInst
+
+	return nil
+}
+
+func processDPLLPins(result map[string]string) (map[string]any, error) {
+	processedResult := make(map[string]any)
+
+	dpllDevices := make([]*NetlinkStateEntry, 0)
+
+	err := json.Unmarshal([]byte(result["dpll-netlink-devices"]), &dpllDevices)
+	if err != nil {
+		return processedResult, fmt.Errorf("failed to unmarshal netlink device output: %w", err)
+	}
+
+	pins := make([]*NetlinkPin, 0)
+
+	err = json.Unmarshal([]byte(result["dpll-netlink-pins"]), &pins)
+	if err != nil {
+		return processedResult, fmt.Errorf("failed to unmarshal netlink pin output: %w", err)
+	}
+
+	processedResult["devices"] = dpllDevices
+	processedResult["pins"] = pins
+
+	return processedResult, nil
+}
+
+// GetDPLLPins returns the pin table of every DPLL device on the node
+func GetDPLLPins(ctx clients.ExecContext, helperPath string) (*DPLLPins, error) {
+	dpllPins := &DPLLPins{}
+
+	fetcherInst, fetchedInstanceOk := dpllPinsFetcher[helperPath]
+	if !fetchedInstanceOk {
+		err := BuildDPLLPinsFetcher(helperPath)
+		if err != nil {
+			return dpllPins, err
+		}
+
+		fetcherInst = dpllPinsFetcher[helperPath]
+	}
+
+	err := fetcherInst.Fetch(ctx, dpllPins)
+	if err != nil {
+		log.Debugf("failed to fetch DPLL pins %s", err.Error())
+		return dpllPins, fmt.Errorf("failed to fetch DPLL pins %w", err)
+	}
+
+	return dpllPins, nil
+}
+
+func (dpllPins *DPLLPins) devicesByID() map[int]*NetlinkStateEntry {
+	devicesByID := make(map[int]*NetlinkStateEntry, len(dpllPins.Devices))
+	for _, device := range dpllPins.Devices {
+		devicesByID[device.ID] = device
+	}
+
+	return devicesByID
+}
+
+// pinRecord returns the values which describe the pin itself
+func (dpllPins *DPLLPins) pinRecord(pin *NetlinkPin) map[string]any {
+	return map[string]any{
+		"timestamp":   dpllPins.Timestamp,
+		"clockId":     pin.ClockID,
+		"moduleName":  pin.ModuleName,
+		"pinId":       pin.ID,
+		"label":       pin.Label,
+		"pinType":     pin.Type,
+		"frequency":   pin.Frequency,
+		"phaseAdjust": pin.PhaseAdjust,
+	}
+}
+
+// GetAnalyserFormat returns a record for each relationship a pin has with a DPLL device or another pin
+func (dpllPins *DPLLPins) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	devicesByID := dpllPins.devicesByID()
+	records := make([]*callbacks.AnalyserFormatType, 0, len(dpllPins.Pins))
+
+	for _, pin := range dpllPins.Pins {
+		// A pin which is not connected to anything still has a record so that it is seen in the pin table
+		if len(pin.ParentDevices) == 0 && len(pin.ParentPins) == 0 {
+			records = append(records, &callbacks.AnalyserFormatType{ID: "dpll/pins", Data: dpllPins.pinRecord(pin)})
+			continue
+		}
+
+		for _, parent := range pin.ParentDevices {
+			data := dpllPins.pinRecord(pin)
+			data["parentId"] = parent.ParentID
+			data["direction"] = parent.Direction
+			data["state"] = parent.State
+			data["prio"] = parent.Prio
+			data["phaseOffset"] = convertNetlinkOffset(parent.PhaseOffset)
+
+			if device, ok := devicesByID[parent.ParentID]; ok {
+				data["deviceType"] = device.ClockType
+				data["lockStatus"] = device.LockStatus
+				data["mode"] = device.Mode
+			}
+
+			records = append(records, &callbacks.AnalyserFormatType{ID: "dpll/pins", Data: data})
+		}
+
+		for _, parent := range pin.ParentPins {
+			data := dpllPins.pinRecord(pin)
+			data["parentPinId"] = parent.ParentID
+			data["state"] = parent.State
+
+			records = append(records, &callbacks.AnalyserFormatType{ID: "dpll/pins", Data: data})
+		}
+	}
+
+	return records, nil
+}
+
+// GetMetrics returns the state, priority and phase offset of each input pin for each DPLL device
+func (dpllPins *DPLLPins) GetMetrics() []*metrics.Sample {
+	devicesByID := dpllPins.devicesByID()
+	samples := make([]*metrics.Sample, 0)
+
+	for _, pin := range dpllPins.Pins {
+		for _, parent := range pin.ParentDevices {
+			if parent.Direction != InputDirection {
+				continue
+			}
+
+			// Every sample of a metric must have the same labels so device_type is empty for an unknown device
+			labels := map[string]string{
+				"clock_id":    strconv.FormatUint(pin.ClockID, 10),
+				"pin":         pin.Label,
+				"pin_id":      strconv.Itoa(int(pin.ID)),
+				"device":      strconv.Itoa(parent.ParentID),
+				"device_type": "",
+			}
+			if device, ok := devicesByID[parent.ParentID]; ok {
+				labels["device_type"] = device.ClockType
+			}
+
+			connected := 0.0
+			if parent.State == ConnectedState {
+				connected = 1
+			}
+
+			samples = append(samples,
+				&metrics.Sample{
+					Name:   "dpll_pin_connected",
+					Help:   "1 if the input pin is the one the DPLL device is connected to",
+					Labels: labels,
+					Value:  connected,
+				},
+				&metrics.Sample{
+					Name:   "dpll_pin_prio",
+					Help:   "Priority of the input pin for the DPLL device",
+					Labels: labels,
+					Value:  float64(parent.Prio),
+				},
+				&metrics.Sample{
+					Name:   "dpll_pin_phase_offset_ns",
+					Help:   "Phase offset between the input pin and the DPLL device in nanoseconds",
+					Labels: labels,
+					Value:  convertNetlinkOffset(parent.PhaseOffset),
+				},
+			)
+		}
+	}
+
+	return samples
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"bufio"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+const (
+	ynlCommand    = "/linux/tools/net/ynl/cli.py --spec /linux/Documentation/netlink/specs/dpll.yaml"
+	ynlJSONEncode = " | python3 /root/custom_scripts/json_encoder.py"
+
+	dpllDevicesJSON = `[
+		{"clock-id": 5799633565435100136, "id": 0, "lock-status": "locked-ho-acq", "mode": "automatic",
+		 "module-name": "ice", "type": "eec"},
+		{"clock-id": 5799633565435100136, "id": 1, "lock-status": "locked-ho-acq", "mode": "automatic",
+		 "module-name": "ice", "type": "pps"}
+	]`
+	dpllPinsJSON = `[
+		{"board-label": "GNSS-1PPS", "clock-id": 5799633565435100136, "frequency": 1, "id": 3,
+		 "module-name": "ice", "phase-adjust": 0, "type": "gnss",
+		 "parent-device": [
+			{"direction": "input", "parent-id": 0, "phase-offset": -1234000, "prio": 0, "state": "connected"},
+			{"direction": "input", "parent-id": 1, "phase-offset": 56000, "prio": 0, "state": "connected"}
+		 ]},
+		{"board-label": "SMA2", "clock-id": 5799633565435100136, "frequency": 10000000, "id": 5,
+		 "module-name": "ice", "phase-adjust": 250, "type": "ext",
+		 "parent-device": [
+			{"direction": "input", "parent-id": 0, "phase-offset": 0, "prio": 4, "state": "selectable"},
+			{"direction": "output", "parent-id": 1, "phase-offset": 0, "prio": 0, "state": "disconnected"}
+		 ]},
+		{"clock-id": 5799633565435100136, "id": 13, "module-name": "ice", "type": "synce-eth-port",
+		 "parent-pin": [{"parent-id": 2, "state": "connected"}]},
+		{"board-label": "SMA1", "clock-id": 5799633565435100136, "id": 4, "module-name": "ice", "type": "ext",
+		 "parent-device": [
+			{"direction": "input", "parent-id": 9, "phase-offset": 0, "prio": 2, "state": "selectable"}
+		 ]},
+		{"board-label": "U.FL1", "clock-id": 5799633565435100136, "id": 6, "module-name": "ice", "type": "ext"}
+	]`
+)
+
+var _ = Describe("GetDPLLPins", func() {
+	var clientset *clients.Clientset
+	var response map[string][]byte
+	BeforeEach(func() { //nolint:dupl // this is test setup code
+		clientset = testutils.GetMockedClientSet(testPod)
+		response = make(map[string][]byte)
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			reader := bufio.NewReader(options.Stdin)
+			cmd := ""
+			keepReading := true
+			var cmdSb strings.Builder
+			for keepReading {
+				line, prefix, _ := reader.ReadLine()
+				keepReading = prefix
+				cmdSb.WriteString(string(line))
+			}
+			cmd += cmdSb.String()
+			return response[cmd], []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	})
+
+	getPinsWith := func(helperPath string, dump func(string) string) *devices.DPLLPins {
+		expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
+		expectedInput += "echo '<dpll-netlink-devices>';" + dump("device-get") + ";echo '</dpll-netlink-devices>';"
+		expectedInput += "echo '<dpll-netlink-pins>';" + dump("pin-get") + ";echo '</dpll-netlink-pins>';"
+
+		expectedOutput := "<date>\n1686916187.0584\n</date>\n"
+		expectedOutput += "<dpll-netlink-devices>\n" + strings.ReplaceAll(dpllDevicesJSON, "\n", "") +
+			"\n</dpll-netlink-devices>\n"
+		expectedOutput += "<dpll-netlink-pins>\n" + strings.ReplaceAll(dpllPinsJSON, "\n", "") +
+			"\n</dpll-netlink-pins>\n"
+
+		response[expectedInput] = []byte(expectedOutput)
+
+		ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
+		Expect(err).NotTo(HaveOccurred())
+		pins, err := devices.GetDPLLPins(ctx, helperPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		return pins
+	}
+
+	getPins := func() *devices.DPLLPins {
+		return getPinsWith("/opt/vse", func(object string) string {
+			return "/opt/vse dpll --dump " + object
+		})
+	}
+
+	When("called GetDPLLPins", func() {
+		It("should return every pin of every device", func() {
+			pins := getPins()
+			Expect(pins.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(pins.Devices).To(HaveLen(2))
+			Expect(pins.Devices[1].Mode).To(Equal("automatic"))
+			Expect(pins.Pins).To(HaveLen(5))
+			Expect(pins.Pins[1].Label).To(Equal("SMA2"))
+		})
+
+		It("should fall back to ynl when the tool is not available", func() {
+			pins := getPinsWith("", func(object string) string {
+				return ynlCommand + " --dump " + object + ynlJSONEncode
+			})
+			Expect(pins.Devices).To(HaveLen(2))
+			Expect(pins.Pins).To(HaveLen(5))
+		})
+	})
+
+	When("called GetAnalyserFormat", func() {
+		It("should return a record for each parent of each pin", func() {
+			records, err := getPins().GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(HaveLen(7))
+
+			for _, record := range records {
+				Expect(record.ID).To(Equal("dpll/pins"))
+				Expect(record.Data).To(HaveKeyWithValue("timestamp", "2023-06-16T11:49:47.0584Z"))
+			}
+
+			Expect(records[0].Data).To(HaveKeyWithValue("label", "GNSS-1PPS"))
+			Expect(records[0].Data).To(HaveKeyWithValue("deviceType", "eec"))
+			Expect(records[0].Data).To(HaveKeyWithValue("lockStatus", "locked-ho-acq"))
+			Expect(records[0].Data).To(HaveKeyWithValue("phaseOffset", -1.234))
+			Expect(records[1].Data).To(HaveKeyWithValue("deviceType", "pps"))
+			Expect(records[2].Data).To(HaveKeyWithValue("prio", 4))
+			Expect(records[2].Data).To(HaveKeyWithValue("phaseAdjust", int32(250)))
+			Expect(records[3].Data).To(HaveKeyWithValue("direction", "output"))
+			Expect(records[4].Data).To(HaveKeyWithValue("parentPinId", int32(2)))
+			Expect(records[4].Data).NotTo(HaveKey("direction"))
+			Expect(records[5].Data).To(HaveKeyWithValue("parentId", 9))
+			Expect(records[5].Data).NotTo(HaveKey("deviceType"))
+			Expect(records[6].Data).To(HaveKeyWithValue("label", "U.FL1"))
+			Expect(records[6].Data).NotTo(HaveKey("parentId"))
+			Expect(records[6].Data).NotTo(HaveKey("parentPinId"))
+		})
+	})
+
+	When("called GetMetrics", func() {
+		It("should return samples for the input pins", func() {
+			samples := getPins().GetMetrics()
+			Expect(samples).To(HaveLen(12))
+			Expect(samples[0].Name).To(Equal("dpll_pin_connected"))
+			Expect(samples[0].Value).To(BeNumerically("==", 1))
+			Expect(samples[0].Labels).To(HaveKeyWithValue("device_type", "eec"))
+			Expect(samples[6].Labels).To(HaveKeyWithValue("pin", "SMA2"))
+			Expect(samples[6].Value).To(BeNumerically("==", 0))
+			Expect(samples[9].Labels).To(HaveKeyWithValue("pin", "SMA1"))
+			Expect(samples[9].Labels).To(HaveKeyWithValue("device_type", ""))
+
+			for _, sample := range samples {
+				Expect(sample.Labels).To(HaveKey("device_type"))
+			}
+		})
+	})
+})
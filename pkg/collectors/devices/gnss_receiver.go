@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+// GNSSReceiver reads the versions and navigation values of a GNSS receiver
+type GNSSReceiver interface {
+	// Vendor returns the vendor of the receiver such as u-blox
+	Vendor() string
+	// GetVersions returns the versions of the receiver and of the tools used to read it
+	GetVersions(ctx clients.ExecContext) (*GPSVersions, error)
+	// GetNav returns the navigation, clock and antenna values of the receiver
+	GetNav(ctx clients.ExecContext) (*GPSDetails, error)
+}
+
+// RawGNSSReceiver is a GNSSReceiver which can also decode a raw capture of the receiver's messages
+type RawGNSSReceiver interface {
+	GNSSReceiver
+	GetNavBinary(ctx clients.ExecContext) (*GPSDetails, error)
+}
+
+// GNSSModel is a receiver model and the minimum versions it is supported with
+type GNSSModel struct {
+	Vendor             string `json:"vendor"`
+	Module             string `json:"module"`
+	MinFirmwareVersion string `json:"minFirmwareVersion"`
+	MinProtocolVersion string `json:"minProtocolVersion"`
+}
+
+// GNSSDetectFunc returns the receiver on the host if it is from the driver's vendor
+type GNSSDetectFunc func(ctx clients.ExecContext) (GNSSReceiver, error)
+
+type gnssDriver struct {
+	detect GNSSDetectFunc
+	vendor string
+}
+
+var (
+	gnssDriversMu sync.Mutex
+	gnssDrivers   []*gnssDriver
+	gnssModels    = make(map[string]*GNSSModel)
+)
+
+// RegisterGNSSDriver adds a vendor's receivers to those tried by DetectGNSSReceiver,
+// drivers are tried in the order they are registered
+func RegisterGNSSDriver(vendor string, detect GNSSDetectFunc) {
+	gnssDriversMu.Lock()
+	defer gnssDriversMu.Unlock()
+
+	gnssDrivers = append(gnssDrivers, &gnssDriver{vendor: vendor, detect: detect})
+}
+
+// RegisterGNSSModel adds a supported receiver model
+func RegisterGNSSModel(model *GNSSModel) {
+	gnssDriversMu.Lock()
+	defer gnssDriversMu.Unlock()
+
+	gnssModels[model.Module] = model
+}
+
+// GetGNSSModel returns the supported receiver model named module
+func GetGNSSModel(module string) (*GNSSModel, bool) {
+	gnssDriversMu.Lock()
+	defer gnssDriversMu.Unlock()
+
+	model, ok := gnssModels[module]
+
+	return model, ok
+}
+
+// DetectGNSSReceiver returns the receiver on the host from the first driver which recognises it
+func DetectGNSSReceiver(ctx clients.ExecContext) (GNSSReceiver, error) {
+	gnssDriversMu.Lock()
+	drivers := append([]*gnssDriver{}, gnssDrivers...)
+	gnssDriversMu.Unlock()
+
+	if len(drivers) == 0 {
+		return nil, errors.New("no GNSS receiver drivers are registered")
+	}
+
+	detectErrors := make([]error, 0, len(drivers))
+
+	for _, driver := range drivers {
+		receiver, err := driver.detect(ctx)
+		if err == nil {
+			log.Debugf("detected %s GNSS receiver", driver.vendor)
+			return receiver, nil
+		}
+
+		detectErrors = append(detectErrors, fmt.Errorf("%s: %w", driver.vendor, err))
+	}
+
+	return nil, fmt.Errorf("failed to detect the GNSS receiver: %w", utils.MakeCompositeError("", detectErrors))
+}
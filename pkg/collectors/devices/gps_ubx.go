@@ -5,6 +5,7 @@ package devices
 import (
 	"fmt"
 	"maps"
+	"math"
 	"regexp"
 	"strconv"
 	"time"
@@ -12,15 +13,21 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
-	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
-	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
 type GPSDetails struct {
+	callbacks.Timed
+
 	NavStatus      GPSNavStatus         `fetcherKey:"navStatus"      json:"navStatus"`
 	AntennaDetails []*GPSAntennaDetails `fetcherKey:"antennaDetails" json:"antennaDetails"`
 	NavClock       GPSNavClock          `fetcherKey:"navClock"       json:"navClock"`
+	NavSat         *GPSNavSat           `fetcherKey:"navSat"         json:"navSat,omitempty"`
+	TimeUTC        *GPSTimeUTC          `fetcherKey:"timeUTC"        json:"timeUTC,omitempty"`
+	LeapSeconds    *GPSLeapSeconds      `fetcherKey:"leapSeconds"    json:"leapSeconds,omitempty"`
+	TimePulse      *GPSTimePulse        `fetcherKey:"timePulse"      json:"timePulse,omitempty"`
+	MonHW          *GPSMonHW            `fetcherKey:"monHW"          json:"monHW,omitempty"`
 }
 
 type GPSNavStatus struct {
@@ -36,10 +43,13 @@ type GPSNavClock struct {
 }
 
 type GPSAntennaDetails struct {
-	Timestamp string `json:"timestamp"`
-	BlockID   int    `json:"blockId"`
-	Status    int    `json:"status"`
-	Power     int    `json:"power"`
+	Timestamp  string `json:"timestamp"`
+	BlockID    int    `json:"blockId"`
+	Status     int    `json:"status"`
+	Power      int    `json:"power"`
+	NoisePerMS int    `json:"noisePerMS"`
+	AGCCnt     int    `json:"agcCnt"`
+	JamInd     int    `json:"jamInd"`
 }
 
 func (gpsNav *GPSDetails) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
@@ -62,9 +72,95 @@ func (gpsNav *GPSDetails) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType,
 		})
 	}
 
+	optional := []struct {
+		data any
+		id   string
+		ok   bool
+	}{
+		{gpsNav.NavSat, "gnss/sat-info", gpsNav.NavSat != nil},
+		{gpsNav.TimeUTC, "gnss/utc-time", gpsNav.TimeUTC != nil},
+		{gpsNav.LeapSeconds, "gnss/leap-seconds", gpsNav.LeapSeconds != nil},
+		{gpsNav.TimePulse, "gnss/time-pulse", gpsNav.TimePulse != nil},
+		{gpsNav.MonHW, "gnss/hw-mon", gpsNav.MonHW != nil},
+	}
+
+	for _, message := range optional {
+		if message.ok {
+			messages = append(messages, &callbacks.AnalyserFormatType{ID: message.id, Data: message.data})
+		}
+	}
+
 	return messages, nil
 }
 
+// GetMetrics returns the navigation and antenna values to be exposed as metrics
+func (gpsNav *GPSDetails) GetMetrics() []*metrics.Sample {
+	samples := []*metrics.Sample{
+		{
+			Name:   "gnss_time_accuracy_ns",
+			Help:   "Time accuracy estimate reported by UBX-NAV-CLOCK in nanoseconds",
+			Labels: map[string]string{},
+			Value:  float64(gpsNav.NavClock.TimeAcc),
+		},
+		{
+			Name:   "gnss_frequency_accuracy_ps_per_s",
+			Help:   "Frequency accuracy estimate reported by UBX-NAV-CLOCK in ps/s",
+			Labels: map[string]string{},
+			Value:  float64(gpsNav.NavClock.FreqAcc),
+		},
+		{
+			Name:   "gnss_fix",
+			Help:   "GPS fix type reported by UBX-NAV-STATUS",
+			Labels: map[string]string{},
+			Value:  float64(gpsNav.NavStatus.GPSFix),
+		},
+	}
+
+	for _, ant := range gpsNav.AntennaDetails {
+		labels := map[string]string{"block": strconv.Itoa(ant.BlockID)}
+		samples = append(samples,
+			&metrics.Sample{
+				Name:   "gnss_antenna_status",
+				Help:   "Antenna status reported by UBX-MON-RF (2 is OK)",
+				Labels: labels,
+				Value:  float64(ant.Status),
+			},
+			&metrics.Sample{
+				Name:   "gnss_antenna_power",
+				Help:   "Antenna power reported by UBX-MON-RF (1 is on)",
+				Labels: labels,
+				Value:  float64(ant.Power),
+			},
+		)
+	}
+
+	return append(samples, gpsNav.getExtendedMetrics()...)
+}
+
+// IsActive returns true if the fix or an antenna status has changed or the time accuracy is beyond offsetThreshold
+func (gpsNav *GPSDetails) IsActive(previous callbacks.OutputType, offsetThreshold float64) bool {
+	if math.Abs(float64(gpsNav.NavClock.TimeAcc)) > offsetThreshold {
+		return true
+	}
+
+	last, ok := previous.(*GPSDetails)
+	if !ok {
+		return false
+	}
+
+	if last.NavStatus.GPSFix != gpsNav.NavStatus.GPSFix || len(last.AntennaDetails) != len(gpsNav.AntennaDetails) {
+		return true
+	}
+
+	for i, ant := range gpsNav.AntennaDetails {
+		if last.AntennaDetails[i].Status != ant.Status {
+			return true
+		}
+	}
+
+	return false
+}
+
 var (
 	timeStampPattern  = `(\d+.\d+)`
 	ubxNavStatusRegex = regexp.MustCompile(
@@ -101,7 +197,7 @@ var (
 	)
 	ubxAntInternalBlockRegex = regexp.MustCompile(
 		`\s+blockId (\d) flags \w+ antStatus (\d) antPower (\d+) postStatus \d reserved2 \d \d \d \d\n` +
-			`\s+noisePerMS \d+ agcCnt \d+ jamInd \d+ ofsI -?\d+ magI \d+ ofsQ -?\d+ magQ \d+\n` +
+			`\s+noisePerMS (\d+) agcCnt (\d+) jamInd (\d+) ofsI -?\d+ magI \d+ ofsQ -?\d+ magQ \d+\n` +
 			`\s+reserved3 \d \d \d\n?`,
 		// 	blockId 0 flags x0 antStatus 2 antPower 1 postStatus 0 reserved2 0 0 0 0
 		// 	noisePerMS 90 agcCnt 4914 jamInd 14 ofsI 15 magI 147 ofsQ 25 magQ 148
@@ -110,24 +206,8 @@ var (
 		// 	noisePerMS 47 agcCnt 6318 jamInd 6 ofsI 17 magI 151 ofsQ 3 magQ 149
 		// 	reserved3 0 0 0
 	)
-
-	gpsFetcher *fetcher.Fetcher
 )
 
-func init() {
-	gpsFetcher = fetcher.NewFetcher()
-	gpsFetcher.SetPostProcessor(processUBX)
-
-	err := gpsFetcher.AddNewCommand(
-		"GPS",
-		"ubxtool -t -p NAV-STATUS -p NAV-CLOCK -p MON-RF -P 29.20",
-		true,
-	)
-	if err != nil {
-		panic(fmt.Errorf("failed to setup GPS fetcher %w", err))
-	}
-}
-
 // processUBXNavStatus parses the output of the ubxtool extracting the required values for GPSNav
 func processUBXNavStatus(result map[string]string) (map[string]any, error) {
 	processedResult := make(map[string]any)
@@ -235,11 +315,19 @@ func processUBXMonRF(result map[string]string) (map[string]any, error) { //nolin
 			return processedResult, fmt.Errorf("failed to convert %s to an int for antPowerValue %w", antBlock[3], err)
 		}
 
+		// The remaining values are only matched as digits so can not fail to convert
+		noisePerMS, _ := strconv.Atoi(antBlock[4]) //nolint:errcheck // matched as digits
+		agcCnt, _ := strconv.Atoi(antBlock[5])     //nolint:errcheck // matched as digits
+		jamInd, _ := strconv.Atoi(antBlock[6])     //nolint:errcheck // matched as digits
+
 		antennaDetails = append(antennaDetails, &GPSAntennaDetails{
-			Timestamp: timestamp,
-			BlockID:   antBlockIDValue,
-			Status:    antStatusValue,
-			Power:     antPowerValue,
+			Timestamp:  timestamp,
+			BlockID:    antBlockIDValue,
+			Status:     antStatusValue,
+			Power:      antPowerValue,
+			NoisePerMS: noisePerMS,
+			AGCCnt:     agcCnt,
+			JamInd:     jamInd,
 		})
 	}
 
@@ -276,6 +364,14 @@ func processUBX(result map[string]string) (map[string]any, error) { //nolint:fun
 
 	maps.Copy(processedResult, processedUBXMonRF)
 
+	processedUBXExtended, err := processUBXExtended(result)
+	if err != nil {
+		log.Debugf("processUBXExtended Failed: %s", err.Error())
+		errors = append(errors, err)
+	}
+
+	maps.Copy(processedResult, processedUBXExtended)
+
 	if len(errors) > 0 {
 		return processedResult,
 			fmt.Errorf(
@@ -286,16 +382,3 @@ func processUBX(result map[string]string) (map[string]any, error) { //nolint:fun
 
 	return processedResult, nil
 }
-
-// GetGPSNav returns GPSNav of the host
-func GetGPSNav(ctx clients.ExecContext) (*GPSDetails, error) {
-	gpsNav := &GPSDetails{}
-
-	err := gpsFetcher.Fetch(ctx, gpsNav)
-	if err != nil {
-		log.Debugf("failed to fetch gpsNav %s", err.Error())
-		return gpsNav, fmt.Errorf("failed to fetch gpsNav %w", err)
-	}
-
-	return gpsNav, nil
-}
@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/ubx"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+// The raw capture is base64 encoded so the binary frames survive being
+// passed back as text and can not be mistaken for the command's end tag.
+const ubxRawCommand = `f=$(mktemp);` +
+	`ubxtool -p NAV-STATUS -p NAV-CLOCK -p MON-RF -p NAV-SAT -p NAV-TIMEUTC -p NAV-TIMELS -p TIM-TP -p MON-HW ` +
+	`-P %s -R "$f" >/dev/null;base64 -w0 "$f";echo;rm -f "$f"`
+
+// decodeUBXCapture returns the last of each message in a base64 encoded capture keyed by its name, such as NAV-SAT
+func decodeUBXCapture(capture string) (map[string]any, error) {
+	data, err := base64.StdEncoding.DecodeString(capture)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode UBX capture %w", err)
+	}
+
+	frames, err := ubx.ReadFrames(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read UBX capture %w", err)
+	}
+
+	messages := make(map[string]any)
+
+	for _, frame := range frames {
+		msg, decodeErr := ubx.Decode(frame)
+		if decodeErr != nil {
+			log.Debugf("skipping UBX-%s: %s", frame.Name(), decodeErr.Error())
+			continue
+		}
+
+		messages[frame.Name()] = msg
+	}
+
+	return messages, nil
+}
+
+func ubxNavStatusDetails(timestamp string, status *ubx.NavStatus) GPSNavStatus {
+	return GPSNavStatus{
+		Timestamp: timestamp,
+		GPSFix:    int(status.GPSFix),
+		Flags:     "0x" + strconv.FormatUint(uint64(status.Flags), 16),
+	}
+}
+
+func ubxNavClockDetails(timestamp string, clock *ubx.NavClock) GPSNavClock {
+	return GPSNavClock{
+		Timestamp: timestamp,
+		TimeAcc:   int(clock.TAcc),
+		FreqAcc:   int(clock.FAcc),
+	}
+}
+
+func ubxMonRFDetails(timestamp string, monRF *ubx.MonRF) []*GPSAntennaDetails {
+	antennaDetails := make([]*GPSAntennaDetails, 0, len(monRF.Blocks))
+
+	for i := range monRF.Blocks {
+		block := &monRF.Blocks[i]
+		antennaDetails = append(antennaDetails, &GPSAntennaDetails{
+			Timestamp:  timestamp,
+			BlockID:    int(block.BlockID),
+			Status:     int(block.AntStatus),
+			Power:      int(block.AntPower),
+			NoisePerMS: int(block.NoisePerMS),
+			AGCCnt:     int(block.AGCCnt),
+			JamInd:     int(block.JamInd),
+		})
+	}
+
+	return antennaDetails
+}
+
+func ubxNavSatDetails(timestamp string, navSat *ubx.NavSat) *GPSNavSat {
+	satellites := make([]*GPSSatellite, 0, len(navSat.Satellites))
+
+	for _, sv := range navSat.Satellites {
+		satellites = append(satellites, newGPSSatellite(
+			int(sv.GNSSID), int(sv.SVID), int(sv.CNO), int(sv.Elev), int(sv.Azim), int(sv.Flags),
+		))
+	}
+
+	return newGPSNavSat(timestamp, satellites)
+}
+
+func ubxTimeUTCDetails(timestamp string, timeUTC *ubx.NavTimeUTC) *GPSTimeUTC {
+	utc := time.Date(
+		int(timeUTC.Year), time.Month(timeUTC.Month), int(timeUTC.Day),
+		int(timeUTC.Hour), int(timeUTC.Min), int(timeUTC.Sec), int(timeUTC.Nano), time.UTC,
+	).Format(time.RFC3339Nano)
+
+	return newGPSTimeUTC(timestamp, utc, int(timeUTC.TAcc), int(timeUTC.Nano), int(timeUTC.Valid))
+}
+
+func ubxLeapSecondsDetails(timestamp string, timeLS *ubx.NavTimeLS) *GPSLeapSeconds {
+	return newGPSLeapSeconds(timestamp, int(timeLS.CurrLs), int(timeLS.SrcOfCurrLs),
+		int(timeLS.LsChange), int(timeLS.TimeToLsEvent), int(timeLS.Valid))
+}
+
+func ubxTimePulseDetails(timestamp string, timTP *ubx.TimTP) *GPSTimePulse {
+	return &GPSTimePulse{
+		Timestamp: timestamp,
+		TowMS:     int(timTP.TowMS),
+		TowSubMS:  int(timTP.TowSubMS),
+		QErr:      int(timTP.QErr),
+		Week:      int(timTP.Week),
+		Flags:     int(timTP.Flags),
+	}
+}
+
+func ubxMonHWDetails(timestamp string, monHW *ubx.MonHW) *GPSMonHW {
+	return &GPSMonHW{
+		Timestamp:  timestamp,
+		NoisePerMS: int(monHW.NoisePerMS),
+		AGCCnt:     int(monHW.AGCCnt),
+		AStatus:    int(monHW.AStatus),
+		APower:     int(monHW.APower),
+		JamInd:     int(monHW.JamInd),
+		Flags:      int(monHW.Flags),
+	}
+}
+
+// addDecoded adds the details of the message name to processedResult under key if it was in the capture
+func addDecoded[M, T any](
+	processedResult map[string]any,
+	messages map[string]any,
+	name, key, timestamp string,
+	details func(string, *M) T,
+) error {
+	msg, ok := messages[name].(*M)
+	if !ok {
+		return fmt.Errorf("UBX-%s was not found in the UBX capture", name)
+	}
+
+	processedResult[key] = details(timestamp, msg)
+
+	return nil
+}
+
+// processUBXBinary decodes the raw UBX capture into the same values as processUBX
+func processUBXBinary(result map[string]string) (map[string]any, error) {
+	processedResult := make(map[string]any)
+
+	if strings.TrimSpace(result["GPSRaw"]) == "" {
+		return processedResult, fmt.Errorf("no UBX messages were captured from the receiver")
+	}
+
+	messages, err := decodeUBXCapture(result["GPSRaw"])
+	if err != nil {
+		return processedResult, err
+	}
+
+	timestamp := result["date"]
+	errors := make([]error, 0)
+
+	for _, err := range []error{
+		addDecoded(processedResult, messages, "NAV-STATUS", "navStatus", timestamp, ubxNavStatusDetails),
+		addDecoded(processedResult, messages, "NAV-CLOCK", "navClock", timestamp, ubxNavClockDetails),
+		addDecoded(processedResult, messages, "MON-RF", "antennaDetails", timestamp, ubxMonRFDetails),
+	} {
+		if err != nil {
+			errors = append(errors, err)
+		}
+	}
+
+	// Not every receiver supports these so they are left out rather than being an error
+	for _, err := range []error{
+		addDecoded(processedResult, messages, "NAV-SAT", "navSat", timestamp, ubxNavSatDetails),
+		addDecoded(processedResult, messages, "NAV-TIMEUTC", "timeUTC", timestamp, ubxTimeUTCDetails),
+		addDecoded(processedResult, messages, "NAV-TIMELS", "leapSeconds", timestamp, ubxLeapSecondsDetails),
+		addDecoded(processedResult, messages, "TIM-TP", "timePulse", timestamp, ubxTimePulseDetails),
+		addDecoded(processedResult, messages, "MON-HW", "monHW", timestamp, ubxMonHWDetails),
+	} {
+		if err != nil {
+			log.Debug(err.Error())
+		}
+	}
+
+	if len(errors) > 0 {
+		return processedResult,
+			fmt.Errorf(
+				"the following errors occurred decoding the GNSS values: %w",
+				utils.MakeCompositeError("", errors),
+			)
+	}
+
+	return processedResult, nil
+}
@@ -0,0 +1,431 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+// The extended messages are read as "name value" pairs rather than with
+// a regex per message so that small changes to ubxtool's layout do not
+// stop them being parsed.
+var (
+	ubxMessageBlockRegex = regexp.MustCompile(
+		timeStampPattern + `\nUBX-([A-Z]+-[A-Z0-9]+):\n((?:[ \t][^\n]*(?:\n|$))*)`,
+		// 1686916187.0584
+		// UBX-TIM-TP:
+		//   towMS 474605000 towSubMS 0 qErr -1544 week 2266
+		//   flags 0x1b refInfo 0x0
+	)
+	ubxFieldRegex = regexp.MustCompile(`([A-Za-z]\w*)\s+(-?(?:0x|x)?[0-9a-fA-F]+)\b`)
+)
+
+const (
+	// UBX-NAV-SAT flags
+	ubxSatQualityMask = 0x07
+	ubxSatUsed        = 0x08
+	ubxSatHealthShift = 4
+	ubxSatHealthMask  = 0x03
+
+	// UBX-NAV-TIMEUTC valid flags
+	ubxTimeUTCValidTOW      = 0x01
+	ubxTimeUTCValidWKN      = 0x02
+	ubxTimeUTCValidUTC      = 0x04
+	ubxTimeUTCStandardShift = 4
+
+	// UBX-NAV-TIMELS valid flags
+	ubxTimeLSValidCurrLs        = 0x01
+	ubxTimeLSValidTimeToLsEvent = 0x02
+)
+
+type GPSSatellite struct {
+	GNSSID     int  `json:"gnssId"`
+	SVID       int  `json:"svId"`
+	CNO        int  `json:"cno"`
+	Elevation  int  `json:"elev"`
+	Azimuth    int  `json:"azim"`
+	QualityInd int  `json:"qualityInd"`
+	Health     int  `json:"health"`
+	Used       bool `json:"used"`
+}
+
+// GPSNavSat is the signal quality of each satellite from UBX-NAV-SAT
+type GPSNavSat struct {
+	Timestamp  string          `json:"timestamp"`
+	Satellites []*GPSSatellite `json:"satellites"`
+	NumSVs     int             `json:"numSvs"`
+	NumUsed    int             `json:"numUsed"`
+	MeanUsedCN float64         `json:"meanUsedCno"`
+}
+
+// GPSTimeUTC is the UTC time and its validity from UBX-NAV-TIMEUTC
+type GPSTimeUTC struct {
+	Timestamp   string `json:"timestamp"`
+	UTC         string `json:"utc"`
+	TimeAcc     int    `json:"tAcc"`
+	Nano        int    `json:"nano"`
+	UTCStandard int    `json:"utcStandard"`
+	ValidTOW    bool   `json:"validTOW"`
+	ValidWKN    bool   `json:"validWKN"`
+	ValidUTC    bool   `json:"validUTC"`
+}
+
+// GPSLeapSeconds is the leap second information from UBX-NAV-TIMELS
+type GPSLeapSeconds struct {
+	Timestamp             string `json:"timestamp"`
+	CurrentLeapSeconds    int    `json:"currLs"`
+	SourceOfCurrent       int    `json:"srcOfCurrLs"`
+	LeapSecondChange      int    `json:"lsChange"`
+	TimeToLeapSecondEvent int    `json:"timeToLsEvent"`
+	ValidCurrentLs        bool   `json:"validCurrLs"`
+	ValidTimeToLsEvent    bool   `json:"validTimeToLsEvent"`
+}
+
+// GPSTimePulse is the time pulse quantisation error from UBX-TIM-TP
+type GPSTimePulse struct {
+	Timestamp string `json:"timestamp"`
+	TowMS     int    `json:"towMS"`
+	TowSubMS  int    `json:"towSubMS"`
+	QErr      int    `json:"qErr"`
+	Week      int    `json:"week"`
+	Flags     int    `json:"flags"`
+}
+
+// GPSMonHW is the hardware status from UBX-MON-HW
+type GPSMonHW struct {
+	Timestamp  string `json:"timestamp"`
+	NoisePerMS int    `json:"noisePerMS"`
+	AGCCnt     int    `json:"agcCnt"`
+	AStatus    int    `json:"aStatus"`
+	APower     int    `json:"aPower"`
+	JamInd     int    `json:"jamInd"`
+	Flags      int    `json:"flags"`
+}
+
+type ubxMessage struct {
+	timestamp string
+	lines     []string
+}
+
+// findUBXMessages returns the last of each message in the ubxtool output keyed by its name, such as NAV-SAT
+func findUBXMessages(output string) (map[string]*ubxMessage, error) {
+	messages := make(map[string]*ubxMessage)
+
+	for _, match := range ubxMessageBlockRegex.FindAllStringSubmatch(output, -1) {
+		timestamp, err := utils.ParseTimestamp(match[1])
+		if err != nil {
+			return messages, fmt.Errorf("failed to parse timestamp of UBX-%s %w", match[2], err)
+		}
+
+		messages[match[2]] = &ubxMessage{
+			timestamp: timestamp.Format(time.RFC3339Nano),
+			lines:     strings.Split(strings.TrimRight(match[3], "\n"), "\n"),
+		}
+	}
+
+	return messages, nil
+}
+
+// ubxFields are the numeric "name value" pairs of a message
+type ubxFields map[string]string
+
+func parseUBXFields(lines ...string) ubxFields {
+	fields := make(ubxFields)
+
+	for _, line := range lines {
+		for _, match := range ubxFieldRegex.FindAllStringSubmatch(line, -1) {
+			if _, ok := fields[match[1]]; !ok {
+				fields[match[1]] = match[2]
+			}
+		}
+	}
+
+	return fields
+}
+
+// get returns the value of the field name, ubxtool writes hex values as either 0x1f or x1f
+func (fields ubxFields) get(name string) (int, error) {
+	value, ok := fields[name]
+	if !ok {
+		return 0, fmt.Errorf("field %s not found", name)
+	}
+
+	if strings.HasPrefix(value, "x") {
+		value = "0" + value
+	}
+
+	parsed, err := strconv.ParseInt(value, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s value %s %w", name, value, err)
+	}
+
+	return int(parsed), nil
+}
+
+// getAll returns the values of each of names in order
+func (fields ubxFields) getAll(names ...string) ([]int, error) {
+	values := make([]int, 0, len(names))
+
+	for _, name := range names {
+		value, err := fields.get(name)
+		if err != nil {
+			return values, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// newGPSSatellite returns a satellite from the UBX-NAV-SAT values, flags holds the quality, health and used bits
+func newGPSSatellite(gnssID, svID, cno, elevation, azimuth, flags int) *GPSSatellite {
+	return &GPSSatellite{
+		GNSSID:     gnssID,
+		SVID:       svID,
+		CNO:        cno,
+		Elevation:  elevation,
+		Azimuth:    azimuth,
+		QualityInd: flags & ubxSatQualityMask,
+		Health:     (flags >> ubxSatHealthShift) & ubxSatHealthMask,
+		Used:       flags&ubxSatUsed != 0,
+	}
+}
+
+// newGPSNavSat returns the UBX-NAV-SAT details with the number and mean C/N0 of the satellites in use
+func newGPSNavSat(timestamp string, satellites []*GPSSatellite) *GPSNavSat {
+	navSat := &GPSNavSat{Timestamp: timestamp, Satellites: satellites, NumSVs: len(satellites)}
+	usedCNO := 0
+
+	for _, sat := range satellites {
+		if sat.Used {
+			navSat.NumUsed++
+			usedCNO += sat.CNO
+		}
+	}
+
+	if navSat.NumUsed > 0 {
+		navSat.MeanUsedCN = float64(usedCNO) / float64(navSat.NumUsed)
+	}
+
+	return navSat
+}
+
+func parseUBXNavSat(msg *ubxMessage) (*GPSNavSat, error) {
+	satellites := make([]*GPSSatellite, 0)
+
+	for _, line := range msg.lines {
+		fields := parseUBXFields(line)
+		if _, ok := fields["gnssId"]; !ok {
+			continue
+		}
+
+		// ubxtool has written both svid and svId
+		if _, ok := fields["svid"]; ok {
+			fields["svId"] = fields["svid"]
+		}
+
+		values, err := fields.getAll("gnssId", "svId", "cno", "elev", "azim", "flags")
+		if err != nil {
+			return newGPSNavSat(msg.timestamp, satellites), fmt.Errorf("failed to parse UBX-NAV-SAT satellite %w", err)
+		}
+
+		satellites = append(satellites, newGPSSatellite(values[0], values[1], values[2], values[3], values[4], values[5]))
+	}
+
+	return newGPSNavSat(msg.timestamp, satellites), nil
+}
+
+var ubxTimeUTCRegex = regexp.MustCompile(`Time\s+(\d+)/(\d+)/(\d+)\s+(\d+):(\d+):(\d+)`)
+
+func parseUBXNavTimeUTC(msg *ubxMessage) (*GPSTimeUTC, error) {
+	fields := parseUBXFields(msg.lines...)
+
+	values, err := fields.getAll("tAcc", "nano", "valid")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse UBX-NAV-TIMEUTC %w", err)
+	}
+
+	utc := ""
+	if match := ubxTimeUTCRegex.FindStringSubmatch(strings.Join(msg.lines, "\n")); match != nil {
+		date := make([]int, 0, len(match)-1)
+		for _, part := range match[1:] {
+			value, _ := strconv.Atoi(part) //nolint:errcheck // the regex only matches digits
+			date = append(date, value)
+		}
+
+		utc = time.Date(
+			date[0], time.Month(date[1]), date[2], date[3], date[4], date[5], values[1], time.UTC,
+		).Format(time.RFC3339Nano)
+	}
+
+	return newGPSTimeUTC(msg.timestamp, utc, values[0], values[1], values[2]), nil
+}
+
+// newGPSTimeUTC returns the UBX-NAV-TIMEUTC details, valid holds the validity bits and the UTC standard
+func newGPSTimeUTC(timestamp, utc string, timeAcc, nano, valid int) *GPSTimeUTC {
+	return &GPSTimeUTC{
+		Timestamp:   timestamp,
+		UTC:         utc,
+		TimeAcc:     timeAcc,
+		Nano:        nano,
+		UTCStandard: valid >> ubxTimeUTCStandardShift,
+		ValidTOW:    valid&ubxTimeUTCValidTOW != 0,
+		ValidWKN:    valid&ubxTimeUTCValidWKN != 0,
+		ValidUTC:    valid&ubxTimeUTCValidUTC != 0,
+	}
+}
+
+func parseUBXNavTimeLS(msg *ubxMessage) (*GPSLeapSeconds, error) {
+	fields := parseUBXFields(msg.lines...)
+
+	values, err := fields.getAll("currLs", "srcOfCurrLs", "lsChange", "timeToLsEvent", "valid")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse UBX-NAV-TIMELS %w", err)
+	}
+
+	return newGPSLeapSeconds(msg.timestamp, values[0], values[1], values[2], values[3], values[4]), nil
+}
+
+// newGPSLeapSeconds returns the UBX-NAV-TIMELS details, valid holds the validity bits
+func newGPSLeapSeconds(timestamp string, currLs, srcOfCurrLs, lsChange, timeToLsEvent, valid int) *GPSLeapSeconds {
+	return &GPSLeapSeconds{
+		Timestamp:             timestamp,
+		CurrentLeapSeconds:    currLs,
+		SourceOfCurrent:       srcOfCurrLs,
+		LeapSecondChange:      lsChange,
+		TimeToLeapSecondEvent: timeToLsEvent,
+		ValidCurrentLs:        valid&ubxTimeLSValidCurrLs != 0,
+		ValidTimeToLsEvent:    valid&ubxTimeLSValidTimeToLsEvent != 0,
+	}
+}
+
+func parseUBXTimTP(msg *ubxMessage) (*GPSTimePulse, error) {
+	fields := parseUBXFields(msg.lines...)
+
+	values, err := fields.getAll("towMS", "towSubMS", "qErr", "week", "flags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse UBX-TIM-TP %w", err)
+	}
+
+	return &GPSTimePulse{
+		Timestamp: msg.timestamp,
+		TowMS:     values[0],
+		TowSubMS:  values[1],
+		QErr:      values[2],
+		Week:      values[3],
+		Flags:     values[4],
+	}, nil
+}
+
+func parseUBXMonHW(msg *ubxMessage) (*GPSMonHW, error) {
+	fields := parseUBXFields(msg.lines...)
+
+	values, err := fields.getAll("noisePerMS", "agcCnt", "aStatus", "aPower", "jamInd", "flags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse UBX-MON-HW %w", err)
+	}
+
+	return &GPSMonHW{
+		Timestamp:  msg.timestamp,
+		NoisePerMS: values[0],
+		AGCCnt:     values[1],
+		AStatus:    values[2],
+		APower:     values[3],
+		JamInd:     values[4],
+		Flags:      values[5],
+	}, nil
+}
+
+// processUBXExtended parses the optional messages, not every receiver supports
+// all of them so a missing message is left out rather than being an error
+func processUBXExtended(result map[string]string) (map[string]any, error) {
+	processedResult := make(map[string]any)
+
+	messages, err := findUBXMessages(result["GPS"])
+	if err != nil {
+		return processedResult, err
+	}
+
+	addMessage(processedResult, messages, "NAV-SAT", "navSat", parseUBXNavSat)
+	addMessage(processedResult, messages, "NAV-TIMEUTC", "timeUTC", parseUBXNavTimeUTC)
+	addMessage(processedResult, messages, "NAV-TIMELS", "leapSeconds", parseUBXNavTimeLS)
+	addMessage(processedResult, messages, "TIM-TP", "timePulse", parseUBXTimTP)
+	addMessage(processedResult, messages, "MON-HW", "monHW", parseUBXMonHW)
+
+	return processedResult, nil
+}
+
+func addMessage[T any](
+	processedResult map[string]any,
+	messages map[string]*ubxMessage,
+	name, key string,
+	parse func(*ubxMessage) (*T, error),
+) {
+	msg, ok := messages[name]
+	if !ok {
+		log.Debugf("UBX-%s was not found in the ubxtool output", name)
+		return
+	}
+
+	value, err := parse(msg)
+	if err != nil {
+		log.Debugf("failed to parse UBX-%s: %s", name, err.Error())
+		return
+	}
+
+	processedResult[key] = value
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+// getExtendedMetrics returns the values of the optional messages to be exposed as metrics
+func (gpsNav *GPSDetails) getExtendedMetrics() []*metrics.Sample {
+	samples := make([]*metrics.Sample, 0)
+	add := func(name, help string, value float64) {
+		samples = append(samples, &metrics.Sample{Name: name, Help: help, Labels: map[string]string{}, Value: value})
+	}
+
+	if gpsNav.NavSat != nil {
+		add("gnss_satellites_used", "Satellites used in the navigation solution from UBX-NAV-SAT",
+			float64(gpsNav.NavSat.NumUsed))
+		add("gnss_used_cno_mean_dbhz", "Mean C/N0 of the satellites used in the navigation solution from UBX-NAV-SAT",
+			gpsNav.NavSat.MeanUsedCN)
+	}
+
+	if gpsNav.TimeUTC != nil {
+		add("gnss_utc_valid", "Whether UTC is valid from UBX-NAV-TIMEUTC", boolToFloat(gpsNav.TimeUTC.ValidUTC))
+	}
+
+	if gpsNav.LeapSeconds != nil {
+		add("gnss_leap_seconds", "Current number of leap seconds from UBX-NAV-TIMELS",
+			float64(gpsNav.LeapSeconds.CurrentLeapSeconds))
+	}
+
+	if gpsNav.TimePulse != nil {
+		add("gnss_time_pulse_qerr_ps", "Time pulse quantisation error from UBX-TIM-TP in picoseconds",
+			float64(gpsNav.TimePulse.QErr))
+	}
+
+	if gpsNav.MonHW != nil {
+		add("gnss_jamming_indicator", "CW jamming indicator from UBX-MON-HW", float64(gpsNav.MonHW.JamInd))
+		add("gnss_agc_count", "AGC monitor count from UBX-MON-HW", float64(gpsNav.MonHW.AGCCnt))
+	}
+
+	return samples
+}
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+)
+
+const (
+	UBloxVendor = "u-blox"
+
+	// DefaultUBXProtocolVersion is used when the protocol version of the receiver can not be detected
+	DefaultUBXProtocolVersion = "29.20"
+
+	ubxNavCommand = "ubxtool -t -p NAV-STATUS -p NAV-CLOCK -p MON-RF " +
+		"-p NAV-SAT -p NAV-TIMEUTC -p NAV-TIMELS -p TIM-TP -p MON-HW -P %s"
+	ubxMonVerCommand = "ubxtool -t -p MON-VER"
+)
+
+// UBloxReceiver reads a u-blox receiver with ubxtool using the receiver's protocol version
+type UBloxReceiver struct {
+	navFetcher      *fetcher.Fetcher
+	rawFetcher      *fetcher.Fetcher
+	verFetcher      *fetcher.Fetcher
+	protocolVersion string
+}
+
+var ubxDetectFetcher *fetcher.Fetcher
+
+func init() {
+	// MON-VER is answered whatever protocol version is asked for so it is used to find the version
+	ubxDetectFetcherInst, err := fetcher.FetcherFactory(
+		[]*clients.Cmd{},
+		[]fetcher.AddCommandArgs{
+			{
+				Key:     "UBXMonVer",
+				Command: ubxMonVerCommand,
+				Trim:    true,
+			},
+		},
+	)
+	if err != nil {
+		panic(fmt.Errorf("failed to setup u-blox detect fetcher %w", err))
+	}
+
+	ubxDetectFetcherInst.SetPostProcessor(processExtentions)
+	ubxDetectFetcher = ubxDetectFetcherInst
+
+	RegisterGNSSDriver(UBloxVendor, DetectUBloxReceiver)
+
+	for _, model := range []*GNSSModel{
+		{Vendor: UBloxVendor, Module: "ZED-F9T", MinFirmwareVersion: "2.20", MinProtocolVersion: "29.20"},
+		{Vendor: UBloxVendor, Module: "NEO-M8T", MinFirmwareVersion: "1.10", MinProtocolVersion: "22.00"},
+		{Vendor: UBloxVendor, Module: "LEA-M8T", MinFirmwareVersion: "1.10", MinProtocolVersion: "22.00"},
+	} {
+		RegisterGNSSModel(model)
+	}
+}
+
+// NewUBloxReceiver returns a UBloxReceiver which speaks protocolVersion, such as 29.20
+func NewUBloxReceiver(protocolVersion string) (*UBloxReceiver, error) {
+	navFetcher := fetcher.NewFetcher()
+	navFetcher.SetPostProcessor(processUBX)
+
+	err := navFetcher.AddNewCommand("GPS", fmt.Sprintf(ubxNavCommand, protocolVersion), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup GPS fetcher %w", err)
+	}
+
+	rawFetcher, err := fetcher.FetcherFactory(
+		[]*clients.Cmd{},
+		[]fetcher.AddCommandArgs{
+			{
+				Key:     "GPSRaw",
+				Command: fmt.Sprintf(ubxRawCommand, protocolVersion),
+				Trim:    true,
+			},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup GPS raw fetcher %w", err)
+	}
+
+	// The date is taken once the capture has finished so it is close to when the messages arrived
+	rawFetcher.AddCommand(getDateCommand())
+	rawFetcher.SetPostProcessor(processUBXBinary)
+
+	verFetcher, err := fetcher.FetcherFactory(
+		[]*clients.Cmd{},
+		[]fetcher.AddCommandArgs{
+			{
+				Key:     "UBXMonVer",
+				Command: ubxMonVerCommand + " -P " + protocolVersion,
+				Trim:    true,
+			},
+			{
+				Key:     "UBXVersion",
+				Command: "ubxtool -V",
+				Trim:    true,
+			},
+			{
+				Key:     "GPSDVersion",
+				Command: "gpsd --version",
+				Trim:    true,
+			},
+			{
+				Key:     "GNSSDevices",
+				Command: "ls -1 /dev | grep gnss", // using grep so we just get an empty string if there is nothing
+				Trim:    true,
+			},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup GPSD Version fetcher %w", err)
+	}
+
+	verFetcher.SetPostProcessor(processGPSVer)
+
+	return &UBloxReceiver{
+		protocolVersion: protocolVersion,
+		navFetcher:      navFetcher,
+		rawFetcher:      rawFetcher,
+		verFetcher:      verFetcher,
+	}, nil
+}
+
+// DetectUBloxReceiver returns a UBloxReceiver using the protocol version reported in the receiver's MON-VER
+func DetectUBloxReceiver(ctx clients.ExecContext) (GNSSReceiver, error) {
+	monVer := &GPSVersions{}
+
+	err := ubxDetectFetcher.Fetch(ctx, monVer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch MON-VER %w", err)
+	}
+
+	if monVer.ProtoVersion == "" {
+		return nil, errors.New("MON-VER did not report the protocol version")
+	}
+
+	log.Debugf("found u-blox %s using protocol version %s", monVer.Module, monVer.ProtoVersion)
+
+	return NewUBloxReceiver(monVer.ProtoVersion)
+}
+
+// DefaultGNSSReceiver returns the receiver assumed when it can not be detected, a u-blox receiver using
+// the default protocol version as this is what older collections used, which lets recordings of them be replayed.
+func DefaultGNSSReceiver() (GNSSReceiver, error) {
+	return NewUBloxReceiver(DefaultUBXProtocolVersion)
+}
+
+func (receiver *UBloxReceiver) Vendor() string {
+	return UBloxVendor
+}
+
+// ProtocolVersion returns the protocol version passed to ubxtool
+func (receiver *UBloxReceiver) ProtocolVersion() string {
+	return receiver.protocolVersion
+}
+
+// GetVersions returns GPSVersions of the host
+func (receiver *UBloxReceiver) GetVersions(ctx clients.ExecContext) (*GPSVersions, error) {
+	gpsVer := &GPSVersions{}
+
+	err := receiver.verFetcher.Fetch(ctx, gpsVer)
+	if err != nil {
+		log.Debugf("failed to fetch gpsVer %s", err.Error())
+		return gpsVer, fmt.Errorf("failed to fetch gpsVer %w", err)
+	}
+
+	return gpsVer, nil
+}
+
+// GetNav returns GPSNav of the host parsed from ubxtool's text output
+func (receiver *UBloxReceiver) GetNav(ctx clients.ExecContext) (*GPSDetails, error) {
+	gpsNav := &GPSDetails{}
+
+	err := receiver.navFetcher.Fetch(ctx, gpsNav)
+	if err != nil {
+		log.Debugf("failed to fetch gpsNav %s", err.Error())
+		return gpsNav, fmt.Errorf("failed to fetch gpsNav %w", err)
+	}
+
+	return gpsNav, nil
+}
+
+// GetNavBinary returns GPSNav of the host decoded from a raw UBX capture rather than ubxtool's text output
+func (receiver *UBloxReceiver) GetNavBinary(ctx clients.ExecContext) (*GPSDetails, error) {
+	gpsNav := &GPSDetails{}
+
+	err := receiver.rawFetcher.Fetch(ctx, gpsNav)
+	if err != nil {
+		log.Debugf("failed to fetch gpsNav from UBX capture %s", err.Error())
+		return gpsNav, fmt.Errorf("failed to fetch gpsNav from UBX capture %w", err)
+	}
+
+	return gpsNav, nil
+}
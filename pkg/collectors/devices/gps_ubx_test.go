@@ -4,7 +4,9 @@ package devices_test
 
 import (
 	"bufio"
+	"encoding/base64"
 	"net/url"
+	"os"
 	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -17,7 +19,7 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
 )
 
-var _ = Describe("GetGPSNav", func() {
+var _ = Describe("UBloxReceiver", func() {
 	var clientset *clients.Clientset
 	var response map[string][]byte
 	BeforeEach(func() { //nolint:dupl // this is test setup code
@@ -39,9 +41,10 @@ var _ = Describe("GetGPSNav", func() {
 		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
 	})
 
-	When("called GetGPSNav", func() {
+	When("called GetNav", func() {
 		It("should return a valid GPSNav", func() {
-			expectedInput := "echo '<GPS>';ubxtool -t -p NAV-STATUS -p NAV-CLOCK -p MON-RF -P 29.20;echo '</GPS>';"
+			expectedInput := "echo '<GPS>';ubxtool -t -p NAV-STATUS -p NAV-CLOCK -p MON-RF " +
+				"-p NAV-SAT -p NAV-TIMEUTC -p NAV-TIMELS -p TIM-TP -p MON-HW -P 29.20;echo '</GPS>';"
 
 			expectedOutput := strings.Join([]string{
 				"<GPS>",
@@ -63,6 +66,37 @@ var _ = Describe("GetGPSNav", func() {
 				"1686916187.0586",
 				"UBX-NAV-CLOCK:",
 				"  iTOW 474605000 clkB -61594 clkD -56 tAcc 5 fAcc 164",
+				"",
+				"1686916187.0588",
+				"UBX-NAV-SAT:",
+				" iTOW 474605000 version 1 numSvs 3 reserved1 0 0",
+				"  gnssId 0 svid   2 cno 42 elev  60 azim 120 prRes     -3 flags x191f",
+				"  gnssId 0 svid  12 cno 38 elev  31 azim 275 prRes      5 flags x191f",
+				"  gnssId 2 svid  11 cno  0 elev  -5 azim  33 prRes      0 flags x11",
+				"",
+				"1686916187.0590",
+				"UBX-NAV-TIMEUTC:",
+				"  iTOW 474605000 tAcc 12 nano -2518 Time  2023/6/16 11:49:47",
+				"  valid x37",
+				"",
+				"1686916187.0592",
+				"UBX-NAV-TIMELS:",
+				" iTOW 474605000 version 0 reserved2 0 0 0 srcOfCurrLs 2",
+				" currLs 18 srcOfLsChange 2 lsChange 0 timeToLsEvent -184204797",
+				" dateOfLsGpsWn 1929 dateOfLsGpsDn 7 reserved2 0 0 0",
+				" valid x3",
+				"",
+				"1686916187.0594",
+				"UBX-TIM-TP:",
+				"  towMS 474605000 towSubMS 0 qErr -1544 week 2266",
+				"  flags 0x1b refInfo 0x0",
+				"",
+				"1686916187.0596",
+				"UBX-MON-HW:",
+				" pinSel 0xf000 pinBank 0x0 pinDir 0x10000 pinVal 0xef6f noisePerMS 88",
+				" agcCnt 6041 aStatus 2 aPower 1 flags 0x1 reserved1 0",
+				" usedMask 0xfffff",
+				" jamInd 12 reserved2 0 0 pinIrq 0x0 pullH 0x0 pullL 0x0",
 				"</GPS>",
 			}, "\n")
 			response[expectedInput] = []byte(expectedOutput)
@@ -70,7 +104,10 @@ var _ = Describe("GetGPSNav", func() {
 			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
 			Expect(err).NotTo(HaveOccurred())
 
-			gpsInfo, err := devices.GetGPSNav(ctx)
+			receiver, err := devices.NewUBloxReceiver("29.20")
+			Expect(err).NotTo(HaveOccurred())
+
+			gpsInfo, err := receiver.GetNav(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(gpsInfo.NavStatus.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
 			Expect(gpsInfo.NavStatus.GPSFix).To(Equal(3))
@@ -88,7 +125,102 @@ var _ = Describe("GetGPSNav", func() {
 			Expect(gpsInfo.AntennaDetails[1].BlockID).To(Equal(1))
 			Expect(gpsInfo.AntennaDetails[1].Status).To(Equal(2))
 			Expect(gpsInfo.AntennaDetails[1].Power).To(Equal(1))
+			Expect(gpsInfo.AntennaDetails[1].NoisePerMS).To(Equal(49))
+			Expect(gpsInfo.AntennaDetails[1].AGCCnt).To(Equal(6669))
+			Expect(gpsInfo.AntennaDetails[1].JamInd).To(Equal(2))
+
+			Expect(gpsInfo.NavSat.Timestamp).To(Equal("2023-06-16T11:49:47.0588Z"))
+			Expect(gpsInfo.NavSat.NumSVs).To(Equal(3))
+			Expect(gpsInfo.NavSat.NumUsed).To(Equal(2))
+			Expect(gpsInfo.NavSat.MeanUsedCN).To(Equal(40.0))
+			Expect(gpsInfo.NavSat.Satellites[2].Elevation).To(Equal(-5))
+			Expect(gpsInfo.NavSat.Satellites[2].Used).To(BeFalse())
+
+			Expect(gpsInfo.TimeUTC.UTC).To(Equal("2023-06-16T11:49:46.999997482Z"))
+			Expect(gpsInfo.TimeUTC.TimeAcc).To(Equal(12))
+			Expect(gpsInfo.TimeUTC.ValidUTC).To(BeTrue())
+			Expect(gpsInfo.TimeUTC.UTCStandard).To(Equal(3))
+
+			Expect(gpsInfo.LeapSeconds.CurrentLeapSeconds).To(Equal(18))
+			Expect(gpsInfo.LeapSeconds.TimeToLeapSecondEvent).To(Equal(-184204797))
+			Expect(gpsInfo.LeapSeconds.ValidCurrentLs).To(BeTrue())
+
+			Expect(gpsInfo.TimePulse.QErr).To(Equal(-1544))
+			Expect(gpsInfo.TimePulse.Week).To(Equal(2266))
+
+			Expect(gpsInfo.MonHW.JamInd).To(Equal(12))
+			Expect(gpsInfo.MonHW.AGCCnt).To(Equal(6041))
+			Expect(gpsInfo.MonHW.AStatus).To(Equal(2))
+
+			formatted, err := gpsInfo.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			ids := make([]string, 0, len(formatted))
+			for _, message := range formatted {
+				ids = append(ids, message.ID)
+			}
+			Expect(ids).To(ContainElements(
+				"gnss/sat-info", "gnss/utc-time", "gnss/leap-seconds", "gnss/time-pulse", "gnss/hw-mon",
+			))
+
+		})
+	})
+
+	When("called GetNavBinary", func() {
+		It("should return a valid GPSNav decoded from the raw capture", func() {
+			expectedInput := "echo '<GPSRaw>';f=$(mktemp);ubxtool -p NAV-STATUS -p NAV-CLOCK -p MON-RF -p NAV-SAT " +
+				"-p NAV-TIMEUTC -p NAV-TIMELS -p TIM-TP -p MON-HW -P 29.20 -R \"$f\" >/dev/null;" +
+				"base64 -w0 \"$f\";echo;rm -f \"$f\";echo '</GPSRaw>';" +
+				"echo '<date>';date +%s.%N;echo '</date>';"
+
+			// The capture also has NMEA sentences, a corrupted frame and a truncated frame
+			capture, err := os.ReadFile("../../ubx/test_files/nav.ubx")
+			Expect(err).NotTo(HaveOccurred())
+
+			expectedOutput := strings.Join([]string{
+				"<GPSRaw>",
+				base64.StdEncoding.EncodeToString(capture),
+				"</GPSRaw>",
+				"<date>",
+				"1686916187.0600",
+				"</date>",
+			}, "\n")
+			response[expectedInput] = []byte(expectedOutput)
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
+			Expect(err).NotTo(HaveOccurred())
+
+			receiver, err := devices.NewUBloxReceiver("29.20")
+			Expect(err).NotTo(HaveOccurred())
+
+			gpsInfo, err := receiver.GetNavBinary(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gpsInfo.NavStatus.Timestamp).To(Equal("2023-06-16T11:49:47.06Z"))
+			Expect(gpsInfo.NavStatus.GPSFix).To(Equal(3))
+			Expect(gpsInfo.NavStatus.Flags).To(Equal("0xdd"))
+
+			Expect(gpsInfo.NavClock.TimeAcc).To(Equal(5))
+			Expect(gpsInfo.NavClock.FreqAcc).To(Equal(164))
+
+			Expect(gpsInfo.AntennaDetails).To(HaveLen(2))
+			Expect(gpsInfo.AntennaDetails[1].BlockID).To(Equal(1))
+			Expect(gpsInfo.AntennaDetails[1].Status).To(Equal(2))
+			Expect(gpsInfo.AntennaDetails[1].Power).To(Equal(1))
+			Expect(gpsInfo.AntennaDetails[1].NoisePerMS).To(Equal(49))
+			Expect(gpsInfo.AntennaDetails[1].JamInd).To(Equal(2))
+
+			Expect(gpsInfo.NavSat.NumSVs).To(Equal(3))
+			Expect(gpsInfo.NavSat.NumUsed).To(Equal(2))
+			Expect(gpsInfo.NavSat.MeanUsedCN).To(Equal(40.0))
+			Expect(gpsInfo.NavSat.Satellites[2].Elevation).To(Equal(-5))
+
+			Expect(gpsInfo.TimeUTC.UTC).To(Equal("2023-06-16T11:49:46.999997482Z"))
+			Expect(gpsInfo.TimeUTC.ValidUTC).To(BeTrue())
+			Expect(gpsInfo.TimeUTC.UTCStandard).To(Equal(3))
 
+			Expect(gpsInfo.LeapSeconds.CurrentLeapSeconds).To(Equal(18))
+			Expect(gpsInfo.TimePulse.QErr).To(Equal(-1544))
+			Expect(gpsInfo.MonHW.JamInd).To(Equal(12))
+			Expect(gpsInfo.MonHW.AGCCnt).To(Equal(6041))
 		})
 	})
 })
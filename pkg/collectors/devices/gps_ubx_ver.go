@@ -8,11 +8,8 @@ import (
 	"strings"
 	"time"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
-	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
@@ -64,43 +61,8 @@ var (
 	// ubxtool: Version 3.25.1~dev
 	gpsdVersion = regexp.MustCompile(`gpsd: (.* \(revision .*\))`)
 	// gpsd: 3.25.1~dev (revision release-3.25-109-g1a04cfab8)
-	gpsVerFetcher *fetcher.Fetcher
 )
 
-func init() {
-	gpsVerFetcherInst, err := fetcher.FetcherFactory(
-		[]*clients.Cmd{},
-		[]fetcher.AddCommandArgs{
-			{
-				Key:     "UBXMonVer",
-				Command: "ubxtool -t -p MON-VER -P 29.20",
-				Trim:    true,
-			},
-			{
-				Key:     "UBXVersion",
-				Command: "ubxtool -V",
-				Trim:    true,
-			},
-			{
-				Key:     "GPSDVersion",
-				Command: "gpsd --version",
-				Trim:    true,
-			},
-			{
-				Key:     "GNSSDevices",
-				Command: "ls -1 /dev | grep gnss", // using grep so we just get an empty string if there is nothing
-				Trim:    true,
-			},
-		},
-	)
-	if err != nil {
-		panic(fmt.Errorf("failed to setup GPSD Version fetcher %w", err))
-	}
-
-	gpsVerFetcherInst.SetPostProcessor(processGPSVer)
-	gpsVerFetcher = gpsVerFetcherInst
-}
-
 // findFirstCaptureGroup returns the first capture group of supplied regex.
 func findFirstCaptureGroup(input string, regex *regexp.Regexp, name string) (string, error) {
 	version := regex.FindStringSubmatch(input)
@@ -190,15 +152,12 @@ func processGPSVer(result map[string]string) (map[string]any, error) {
 	return processedResult, nil
 }
 
-// GetGPSVersions returns GPSVersions of the host
+// GetGPSVersions returns GPSVersions of the host from the receiver found on it
 func GetGPSVersions(ctx clients.ExecContext) (*GPSVersions, error) {
-	gpsVer := &GPSVersions{}
-
-	err := gpsVerFetcher.Fetch(ctx, gpsVer)
+	receiver, err := DetectGNSSReceiver(ctx)
 	if err != nil {
-		log.Debugf("failed to fetch gpsVer %s", err.Error())
-		return gpsVer, fmt.Errorf("failed to fetch gpsVer %w", err)
+		return &GPSVersions{}, err
 	}
 
-	return gpsVer, nil
+	return receiver.GetVersions(ctx) //nolint:wrapcheck // the receiver wraps its errors
 }
@@ -17,7 +17,7 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
 )
 
-var _ = Describe("GetGPSNav", func() {
+var _ = Describe("GetGPSVersions", func() {
 	var clientset *clients.Clientset
 	var response map[string][]byte
 	BeforeEach(func() { //nolint:dupl // this is test setup code
@@ -39,40 +39,53 @@ var _ = Describe("GetGPSNav", func() {
 		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
 	})
 
-	When("called GetGPSNav", func() {
-		It("should return a valid GPSNav", func() {
-			expectedInput := "echo '<UBXMonVer>';ubxtool -t -p MON-VER -P 29.20;echo '</UBXMonVer>';"
-			expectedInput += "echo '<UBXVersion>';ubxtool -V;echo '</UBXVersion>';"
-			expectedInput += "echo '<GPSDVersion>';gpsd --version;echo '</GPSDVersion>';"
-			expectedInput += "echo '<GNSSDevices>';ls -1 /dev | grep gnss;echo '</GNSSDevices>';"
-
-			expectedOutput := strings.Join([]string{
-				"<UBXMonVer>",
-				"1689260332.4728",
-				"UBX-MON-VER:",
-				"  swVersion EXT CORE 1.00 (3fda8e)",
-				"  hwVersion 00190000",
-				"  extension ROM BASE 0x118B2060",
-				"  extension FWVER=TIM 2.20",
-				"  extension PROTVER=29.20",
-				"  extension MOD=ZED-F9T",
-				"  extension GPS;GLO;GAL;BDS",
-				"  extension SBAS;QZSS",
-				"  extension NAVIC",
-				"",
-				"</UBXMonVer>",
-				"<UBXVersion>",
-				"ubxtool: Version 3.25.1~dev",
-				"</UBXVersion>",
-				"<GPSDVersion>",
-				"gpsd: 3.25.1~dev (revision release-3.25-109-g1a04cfab8)",
-				"</GPSDVersion>",
-				"<GNSSDevices>",
-				"gnss0",
-				"</GNSSDevices>",
-				"",
-			}, "\n")
-			response[expectedInput] = []byte(expectedOutput)
+	monVerOutput := func(firmware, protocol, module string) string {
+		return strings.Join([]string{
+			"1689260332.4728",
+			"UBX-MON-VER:",
+			"  swVersion EXT CORE 1.00 (3fda8e)",
+			"  hwVersion 00190000",
+			"  extension ROM BASE 0x118B2060",
+			"  extension FWVER=" + firmware,
+			"  extension PROTVER=" + protocol,
+			"  extension MOD=" + module,
+			"  extension GPS;GLO;GAL;BDS",
+			"  extension SBAS;QZSS",
+			"  extension NAVIC",
+			"",
+		}, "\n")
+	}
+
+	setResponses := func(firmware, protocol, module string) {
+		detectInput := "echo '<UBXMonVer>';ubxtool -t -p MON-VER;echo '</UBXMonVer>';"
+		response[detectInput] = []byte("<UBXMonVer>\n" + monVerOutput(firmware, protocol, module) + "\n</UBXMonVer>\n")
+
+		expectedInput := "echo '<UBXMonVer>';ubxtool -t -p MON-VER -P " + protocol + ";echo '</UBXMonVer>';"
+		expectedInput += "echo '<UBXVersion>';ubxtool -V;echo '</UBXVersion>';"
+		expectedInput += "echo '<GPSDVersion>';gpsd --version;echo '</GPSDVersion>';"
+		expectedInput += "echo '<GNSSDevices>';ls -1 /dev | grep gnss;echo '</GNSSDevices>';"
+
+		expectedOutput := strings.Join([]string{
+			"<UBXMonVer>",
+			monVerOutput(firmware, protocol, module),
+			"</UBXMonVer>",
+			"<UBXVersion>",
+			"ubxtool: Version 3.25.1~dev",
+			"</UBXVersion>",
+			"<GPSDVersion>",
+			"gpsd: 3.25.1~dev (revision release-3.25-109-g1a04cfab8)",
+			"</GPSDVersion>",
+			"<GNSSDevices>",
+			"gnss0",
+			"</GNSSDevices>",
+			"",
+		}, "\n")
+		response[expectedInput] = []byte(expectedOutput)
+	}
+
+	When("called GetGPSVersions", func() {
+		It("should return valid GPSVersions", func() {
+			setResponses("TIM 2.20", "29.20", "ZED-F9T")
 
 			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
 			Expect(err).NotTo(HaveOccurred())
@@ -86,7 +99,65 @@ var _ = Describe("GetGPSNav", func() {
 			Expect(gpsInfo.UBXVersion).To(Equal("3.25.1~dev"))
 			Expect(gpsInfo.GPSDVersion).To(Equal("3.25.1~dev (revision release-3.25-109-g1a04cfab8)"))
 			Expect(gpsInfo.GNSSDevices).To(Equal([]string{"/dev/gnss0"}))
+		})
+
+		It("should use the protocol version the receiver reports", func() {
+			setResponses("TIM 1.10", "22.00", "NEO-M8T")
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
+			Expect(err).NotTo(HaveOccurred())
+
+			receiver, err := devices.DetectGNSSReceiver(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(receiver.Vendor()).To(Equal(devices.UBloxVendor))
+			Expect(receiver.(*devices.UBloxReceiver).ProtocolVersion()).To(Equal("22.00"))
 
+			gpsInfo, err := receiver.GetVersions(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gpsInfo.ProtoVersion).To(Equal("22.00"))
+			Expect(gpsInfo.Module).To(Equal("NEO-M8T"))
 		})
+
+		It("should not detect a receiver which does not report its protocol version", func() {
+			detectInput := "echo '<UBXMonVer>';ubxtool -t -p MON-VER;echo '</UBXMonVer>';"
+			response[detectInput] = []byte("<UBXMonVer>\n" + monVerOutput("TIM 2.20", "", "ZED-F9T") + "\n</UBXMonVer>\n")
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = devices.DetectGNSSReceiver(ctx)
+			Expect(err).To(MatchError(ContainSubstring("did not report the protocol version")))
+		})
+
+		It("should fail when no receiver answers", func() {
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = devices.GetGPSVersions(ctx)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("GetGNSSModel", func() {
+	It("should know the minimum versions of supported receivers", func() {
+		model, ok := devices.GetGNSSModel("ZED-F9T")
+		Expect(ok).To(BeTrue())
+		Expect(model.Vendor).To(Equal(devices.UBloxVendor))
+		Expect(model.MinFirmwareVersion).To(Equal("2.20"))
+		Expect(model.MinProtocolVersion).To(Equal("29.20"))
+	})
+
+	It("should register new receiver models", func() {
+		_, ok := devices.GetGNSSModel("TEST-T1")
+		Expect(ok).To(BeFalse())
+
+		devices.RegisterGNSSModel(&devices.GNSSModel{
+			Vendor: "test", Module: "TEST-T1", MinFirmwareVersion: "1.0", MinProtocolVersion: "1.0",
+		})
+
+		model, ok := devices.GetGNSSModel("TEST-T1")
+		Expect(ok).To(BeTrue())
+		Expect(model.Vendor).To(Equal("test"))
 	})
 })
@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
+)
+
+// GPSDStreamCommand streams gpsd's JSON reports for every device
+var GPSDStreamCommand = []string{"gpspipe", "-w"}
+
+// GPSDTPV is the time-position-velocity report from gpsd
+type GPSDTPV struct {
+	Device      string  `json:"device"`
+	Time        string  `json:"time"`
+	Mode        int     `json:"mode"`
+	Status      int     `json:"status,omitempty"`
+	EPT         float64 `json:"ept,omitempty"`
+	Lat         float64 `json:"lat,omitempty"`
+	Lon         float64 `json:"lon,omitempty"`
+	AltHAE      float64 `json:"altHAE,omitempty"`
+	LeapSeconds int     `json:"leapseconds,omitempty"`
+}
+
+type GPSDSatellite struct {
+	PRN    int     `json:"PRN"`
+	GNSSID int     `json:"gnssid"`
+	SVID   int     `json:"svid"`
+	SS     float64 `json:"ss"`
+	El     float64 `json:"el"`
+	Az     float64 `json:"az"`
+	Used   bool    `json:"used"`
+}
+
+// GPSDSky is the satellites in view reported by gpsd
+type GPSDSky struct {
+	Device     string           `json:"device"`
+	Time       string           `json:"time,omitempty"`
+	Satellites []*GPSDSatellite `json:"satellites"`
+	NSat       int              `json:"nSat"`
+	USat       int              `json:"uSat"`
+}
+
+// GPSDClockOffset is a PPS or TOFF report, the offset of the system clock from the PPS edge or the receiver's time
+type GPSDClockOffset struct {
+	QErr      *int   `json:"qErr,omitempty"`
+	Class     string `json:"class"`
+	Device    string `json:"device"`
+	SHM       string `json:"shm,omitempty"`
+	RealSec   int64  `json:"real_sec"`
+	RealNsec  int64  `json:"real_nsec"`
+	ClockSec  int64  `json:"clock_sec"`
+	ClockNsec int64  `json:"clock_nsec"`
+	Precision int    `json:"precision"`
+}
+
+const (
+	gpsdClassTPV  = "TPV"
+	gpsdClassSKY  = "SKY"
+	gpsdClassPPS  = "PPS"
+	gpsdClassTOFF = "TOFF"
+)
+
+// ParseGPSDReport decodes one line of gpspipe -w output. It returns nil
+// for the reports which are not collected, such as VERSION and DEVICES.
+func ParseGPSDReport(line []byte) (callbacks.OutputType, error) {
+	header := struct {
+		Class string `json:"class"`
+	}{}
+
+	err := json.Unmarshal(line, &header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode gpsd report %w", err)
+	}
+
+	var report callbacks.OutputType
+
+	switch header.Class {
+	case gpsdClassTPV:
+		report = &GPSDTPV{}
+	case gpsdClassSKY:
+		report = &GPSDSky{}
+	case gpsdClassPPS, gpsdClassTOFF:
+		report = &GPSDClockOffset{}
+	default:
+		return nil, nil //nolint:nilnil // the report is not collected
+	}
+
+	err = json.Unmarshal(line, report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode gpsd %s report %w", header.Class, err)
+	}
+
+	if sky, ok := report.(*GPSDSky); ok {
+		sky.countSatellites()
+	}
+
+	return report, nil
+}
+
+func (tpv *GPSDTPV) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{{
+		ID: "gnss/gpsd-tpv",
+		Data: map[string]any{
+			"timestamp":   tpv.Time,
+			"device":      tpv.Device,
+			"mode":        tpv.Mode,
+			"status":      tpv.Status,
+			"ept":         tpv.EPT,
+			"leapseconds": tpv.LeapSeconds,
+		},
+	}}, nil
+}
+
+// GetMetrics returns the fix mode to be exposed as a metric
+func (tpv *GPSDTPV) GetMetrics() []*metrics.Sample {
+	return []*metrics.Sample{{
+		Name:   "gnss_gpsd_mode",
+		Help:   "Fix mode reported by gpsd TPV (1 no fix, 2 2D, 3 3D)",
+		Labels: map[string]string{"device": tpv.Device},
+		Value:  float64(tpv.Mode),
+	}}
+}
+
+// countSatellites fills in the satellite counts which older versions of gpsd do not report
+func (sky *GPSDSky) countSatellites() {
+	if sky.NSat == 0 {
+		sky.NSat = len(sky.Satellites)
+	}
+
+	if sky.USat == 0 {
+		for _, sat := range sky.Satellites {
+			if sat.Used {
+				sky.USat++
+			}
+		}
+	}
+}
+
+// meanUsedSS returns the mean signal strength of the satellites used in the fix
+func (sky *GPSDSky) meanUsedSS() float64 {
+	total := 0.0
+	used := 0
+
+	for _, sat := range sky.Satellites {
+		if sat.Used {
+			total += sat.SS
+			used++
+		}
+	}
+
+	if used == 0 {
+		return 0
+	}
+
+	return total / float64(used)
+}
+
+func (sky *GPSDSky) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{{
+		ID: "gnss/gpsd-sky",
+		Data: map[string]any{
+			"timestamp":  sky.Time,
+			"device":     sky.Device,
+			"nSat":       sky.NSat,
+			"uSat":       sky.USat,
+			"meanUsedSS": sky.meanUsedSS(),
+			"satellites": sky.Satellites,
+		},
+	}}, nil
+}
+
+// GetMetrics returns the satellite counts to be exposed as metrics
+func (sky *GPSDSky) GetMetrics() []*metrics.Sample {
+	labels := map[string]string{"device": sky.Device}
+
+	return []*metrics.Sample{
+		{
+			Name:   "gnss_gpsd_satellites_visible",
+			Help:   "Satellites in view reported by gpsd SKY",
+			Labels: labels,
+			Value:  float64(sky.NSat),
+		},
+		{
+			Name:   "gnss_gpsd_satellites_used",
+			Help:   "Satellites used in the fix reported by gpsd SKY",
+			Labels: labels,
+			Value:  float64(sky.USat),
+		},
+	}
+}
+
+// Offset returns the offset of the system clock in nanoseconds
+func (offset *GPSDClockOffset) Offset() int64 {
+	return (offset.ClockSec-offset.RealSec)*int64(time.Second) + offset.ClockNsec - offset.RealNsec
+}
+
+func (offset *GPSDClockOffset) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	data := map[string]any{
+		"timestamp": time.Unix(offset.RealSec, offset.RealNsec).UTC().Format(time.RFC3339Nano),
+		"device":    offset.Device,
+		"offset":    offset.Offset(),
+		"precision": offset.Precision,
+	}
+
+	if offset.QErr != nil {
+		data["qErr"] = *offset.QErr
+	}
+
+	id := "gnss/gpsd-toff"
+	if offset.Class == gpsdClassPPS {
+		id = "gnss/gpsd-pps"
+	}
+
+	return []*callbacks.AnalyserFormatType{{ID: id, Data: data}}, nil
+}
+
+// GetMetrics returns the clock offset to be exposed as a metric
+func (offset *GPSDClockOffset) GetMetrics() []*metrics.Sample {
+	name, help := "gnss_gpsd_toff_offset_ns", "Offset of the system clock from the receiver's time reported by gpsd TOFF"
+	if offset.Class == gpsdClassPPS {
+		name, help = "gnss_gpsd_pps_offset_ns", "Offset of the system clock from the PPS edge reported by gpsd PPS"
+	}
+
+	return []*metrics.Sample{{
+		Name:   name,
+		Help:   help,
+		Labels: map[string]string{"device": offset.Device},
+		Value:  float64(offset.Offset()),
+	}}
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+var _ = Describe("ParseGPSDReport", func() {
+	When("given a TPV report", func() {
+		It("should return the fix mode and time", func() {
+			report, err := devices.ParseGPSDReport([]byte(`{"class":"TPV","device":"/dev/ttyGNSS_1700_0",` +
+				`"mode":3,"time":"2023-06-16T11:49:47.000Z","leapseconds":18,"ept":0.005,"lat":51.5,"lon":-0.1}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			tpv, ok := report.(*devices.GPSDTPV)
+			Expect(ok).To(BeTrue())
+			Expect(tpv.Mode).To(Equal(3))
+			Expect(tpv.LeapSeconds).To(Equal(18))
+
+			formatted, err := tpv.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(formatted[0].ID).To(Equal("gnss/gpsd-tpv"))
+			Expect(formatted[0].Data).To(HaveKeyWithValue("timestamp", "2023-06-16T11:49:47.000Z"))
+		})
+	})
+	When("given a SKY report without satellite counts", func() {
+		It("should count the satellites", func() {
+			report, err := devices.ParseGPSDReport([]byte(`{"class":"SKY","device":"/dev/ttyGNSS_1700_0",` +
+				`"satellites":[{"PRN":2,"gnssid":0,"svid":2,"el":60.0,"az":120.0,"ss":42.0,"used":true},` +
+				`{"PRN":12,"gnssid":0,"svid":12,"el":31.0,"az":275.0,"ss":38.0,"used":true},` +
+				`{"PRN":75,"gnssid":2,"svid":11,"el":-5.0,"az":33.0,"ss":0.0,"used":false}]}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			sky, ok := report.(*devices.GPSDSky)
+			Expect(ok).To(BeTrue())
+			Expect(sky.NSat).To(Equal(3))
+			Expect(sky.USat).To(Equal(2))
+
+			formatted, err := sky.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(formatted[0].ID).To(Equal("gnss/gpsd-sky"))
+			Expect(formatted[0].Data).To(HaveKeyWithValue("meanUsedSS", 40.0))
+		})
+	})
+	When("given PPS and TOFF reports", func() {
+		It("should return the offset of the system clock", func() {
+			report, err := devices.ParseGPSDReport([]byte(`{"class":"PPS","device":"/dev/pps0",` +
+				`"real_sec":1686916187,"real_nsec":0,"clock_sec":1686916186,"clock_nsec":999999750,` +
+				`"precision":-20,"shm":"NTP2","qErr":-1544}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			pps, ok := report.(*devices.GPSDClockOffset)
+			Expect(ok).To(BeTrue())
+			Expect(pps.Offset()).To(Equal(int64(-250)))
+
+			formatted, err := pps.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(formatted[0].ID).To(Equal("gnss/gpsd-pps"))
+			Expect(formatted[0].Data).To(HaveKeyWithValue("timestamp", "2023-06-16T11:49:47Z"))
+			Expect(formatted[0].Data).To(HaveKeyWithValue("qErr", -1544))
+
+			report, err = devices.ParseGPSDReport([]byte(`{"class":"TOFF","device":"/dev/ttyGNSS_1700_0",` +
+				`"real_sec":1686916187,"real_nsec":0,"clock_sec":1686916187,"clock_nsec":1200,"precision":-1}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			toff, ok := report.(*devices.GPSDClockOffset)
+			Expect(ok).To(BeTrue())
+			Expect(toff.Offset()).To(Equal(int64(1200)))
+
+			formatted, err = toff.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(formatted[0].ID).To(Equal("gnss/gpsd-toff"))
+			Expect(formatted[0].Data).NotTo(HaveKey("qErr"))
+		})
+	})
+	When("given a report which is not collected", func() {
+		It("should return nil", func() {
+			report, err := devices.ParseGPSDReport([]byte(`{"class":"VERSION","release":"3.25","proto_major":3}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(BeNil())
+		})
+	})
+	When("given a line which is not JSON", func() {
+		It("should return an error", func() {
+			_, err := devices.ParseGPSDReport([]byte(`not json`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+)
+
+// SuggestedHelperPath is a path to give --helper-path to run this tool in the containers of the cluster
+const SuggestedHelperPath = "/usr/local/bin/vse-sync-collection-tools"
+
+const helperPresent = "present"
+
+// helperMachines maps the architectures this tool is built for to the machine uname -m reports
+var helperMachines = map[string]string{
+	"amd64":   "x86_64",
+	"arm64":   "aarch64",
+	"ppc64le": "ppc64le",
+	"s390x":   "s390x",
+}
+
+// checkHelper returns true if the tool is at helperPath in the container, otherwise the container's machine
+func checkHelper(ctx clients.ExecContext, helperPath string) (present bool, machine string, err error) {
+	fetcherInst, err := fetcher.FetcherFactory(
+		[]*clients.Cmd{},
+		[]fetcher.AddCommandArgs{
+			{
+				Key:     "helper",
+				Command: fmt.Sprintf("[ -x %s ] && echo %s || uname -m", helperPath, helperPresent),
+				Trim:    true,
+			},
+		},
+	)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to build fetcher to check for the helper %w", err)
+	}
+
+	type Helper struct {
+		Result string `fetcherKey:"helper"`
+	}
+
+	helper := Helper{}
+
+	err = fetcherInst.Fetch(ctx, &helper)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to check for the helper %w", err)
+	}
+
+	return helper.Result == helperPresent, helper.Result, nil
+}
+
+// copyHelper copies the running binary to helperPath if it can run on the container's machine
+func copyHelper(ctx clients.ExecContext, helperPath, machine string) error {
+	if runtime.GOOS != "linux" || helperMachines[runtime.GOARCH] != machine {
+		return fmt.Errorf("this tool is built for %s/%s so can not be run on %s", runtime.GOOS, runtime.GOARCH, machine)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find this tool's binary %w", err)
+	}
+
+	binary, err := os.Open(executable)
+	if err != nil {
+		return fmt.Errorf("failed to open this tool's binary %w", err)
+	}
+	defer binary.Close()
+
+	log.Warnf("writing this tool into the container at %s as --helper-path was given, "+
+		"it is left there after the collection", helperPath)
+
+	return clients.CopyToContainer(ctx, binary, helperPath) //nolint:wrapcheck // the error says where it failed
+}
+
+// EnsureHelper returns true if this tool is at helperPath in the container of ctx,
+// copying the running binary there first when it is missing. An empty helperPath disables the helper.
+func EnsureHelper(ctx clients.ExecContext, helperPath string) (bool, error) {
+	if helperPath == "" {
+		return false, nil
+	}
+
+	present, machine, err := checkHelper(ctx, helperPath)
+	if err != nil || present {
+		return present, err
+	}
+
+	// When replaying the copy is skipped and the check that followed it is replayed
+	if !clients.IsReplaying() {
+		err = copyHelper(ctx, helperPath, machine)
+		if err != nil {
+			return false, fmt.Errorf("failed to copy the helper to %s: %w", helperPath, err)
+		}
+	}
+
+	present, _, err = checkHelper(ctx, helperPath)
+
+	return present, err
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"bufio"
+	"net/url"
+	"runtime"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+var _ = Describe("EnsureHelper", func() {
+	var clientset *clients.Clientset
+	var response map[string][]byte
+	var copies int
+	checkInput := "echo '<helper>';[ -x /opt/vse ] && echo present || uname -m;echo '</helper>';"
+	BeforeEach(func() {
+		clientset = testutils.GetMockedClientSet(testPod)
+		response = make(map[string][]byte)
+		copies = 0
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			// a copy is the only exec whose command is not read from stdin
+			if strings.Contains(url.RawQuery, "chmod") {
+				copies++
+				response[checkInput] = []byte("<helper>\npresent\n</helper>\n")
+				return []byte(""), []byte(""), nil
+			}
+			reader := bufio.NewReader(options.Stdin)
+			cmd := ""
+			keepReading := true
+			var cmdSb strings.Builder
+			for keepReading {
+				line, prefix, _ := reader.ReadLine()
+				keepReading = prefix
+				cmdSb.WriteString(string(line))
+			}
+			cmd += cmdSb.String()
+			return response[cmd], []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	})
+
+	ensureHelper := func(result string) (bool, error) {
+		response[checkInput] = []byte("<helper>\n" + result + "\n</helper>\n")
+
+		ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
+		Expect(err).NotTo(HaveOccurred())
+
+		return devices.EnsureHelper(ctx, "/opt/vse")
+	}
+
+	It("should use the tool when it is already in the container", func() {
+		present, err := ensureHelper("present")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(present).To(BeTrue())
+		Expect(copies).To(Equal(0))
+	})
+
+	It("should copy the tool into a container which can run it", func() {
+		machine, ok := map[string]string{"amd64": "x86_64", "arm64": "aarch64"}[runtime.GOARCH]
+		if runtime.GOOS != "linux" || !ok {
+			Skip("the test binary can not be copied from " + runtime.GOOS + "/" + runtime.GOARCH)
+		}
+
+		present, err := ensureHelper(machine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(present).To(BeTrue())
+		Expect(copies).To(Equal(1))
+	})
+
+	It("should not copy the tool into a container it can not run in", func() {
+		present, err := ensureHelper("sparc64")
+		Expect(err).To(MatchError(ContainSubstring("can not be run on sparc64")))
+		Expect(present).To(BeFalse())
+		Expect(copies).To(Equal(0))
+	})
+})
@@ -0,0 +1,308 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
+)
+
+const (
+	nanosecondsPerSecond      = int64(1e9)
+	nanosecondsPerMillisecond = int64(1e6)
+)
+
+// phcCtlOffsetRegEx matches the result of phc_ctl's cmp, which measures with PTP_SYS_OFFSET_EXTENDED when it can,
+// along with the CLOCK_MONOTONIC seconds and milliseconds phc_ctl logs it at:
+// phc_ctl[357138.013]: offset from CLOCK_REALTIME is -37000000012ns
+var phcCtlOffsetRegEx = regexp.MustCompile(`(?:\[(\d+)\.(\d{3})\]: )?offset from CLOCK_REALTIME is (-?\d+)ns`)
+
+// PHCClock is the PTP hardware clock of a card and the interface it was found through
+type PHCClock struct {
+	Interface string `json:"interface"`
+	Device    string `json:"device"`
+}
+
+// PHCSysOffset is the offset of a PHC from CLOCK_REALTIME
+type PHCSysOffset struct {
+	PHCClock
+
+	Timestamp string `json:"timestamp"`
+	// Offset is CLOCK_REALTIME minus the PHC in nanoseconds, a PHC kept in TAI is a whole number of seconds ahead
+	Offset int64 `json:"offset"`
+	// Residual is Offset with the whole seconds between the timescales of the clocks taken off
+	Residual int64 `json:"residual"`
+}
+
+// PHCPairOffset is the offset of a follower card's PHC from the leader card's PHC
+type PHCPairOffset struct {
+	PHCClock
+
+	Timestamp       string `json:"timestamp"`
+	LeaderInterface string `json:"leaderInterface"`
+	LeaderDevice    string `json:"leaderDevice"`
+	// MeasurementGap is the nanoseconds from the leader's measurement to the follower's, from the millisecond
+	// timestamps phc_ctl logs, it is left out when phc_ctl did not log them
+	MeasurementGap *int64 `json:"measurementGap,omitempty"`
+	// Offset is the follower minus the leader in nanoseconds. It is derived from the offsets of both PHCs from
+	// CLOCK_REALTIME, measured one after the other, so it includes how far either drifted over MeasurementGap.
+	Offset int64 `json:"offset"`
+	// Derived is always true as the PHCs are not compared directly
+	Derived bool `json:"derived"`
+}
+
+// PHCOffsets is the offset of every PHC from CLOCK_REALTIME and of each follower PHC from the leader,
+// all measured by the same exec
+type PHCOffsets struct {
+	callbacks.Timed
+
+	Timestamp  string           `fetcherKey:"date"       json:"timestamp"`
+	SysOffsets []*PHCSysOffset  `fetcherKey:"sysOffsets" json:"sysOffsets"`
+	PHCOffsets []*PHCPairOffset `fetcherKey:"phcOffsets" json:"phcOffsets"`
+}
+
+// GetAnalyserFormat returns a phc/sys-offset record for every PHC and a phc/phc-offset record for every follower
+func (offsets *PHCOffsets) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted := make([]*callbacks.AnalyserFormatType, 0, len(offsets.SysOffsets)+len(offsets.PHCOffsets))
+
+	for _, sysOffset := range offsets.SysOffsets {
+		formatted = append(formatted, &callbacks.AnalyserFormatType{ID: "phc/sys-offset", Data: sysOffset})
+	}
+
+	for _, phcOffset := range offsets.PHCOffsets {
+		formatted = append(formatted, &callbacks.AnalyserFormatType{ID: "phc/phc-offset", Data: phcOffset})
+	}
+
+	return formatted, nil
+}
+
+// GetMetrics returns the offsets, which are told apart by their interface and device labels
+func (offsets *PHCOffsets) GetMetrics() []*metrics.Sample {
+	samples := make([]*metrics.Sample, 0)
+
+	for _, sysOffset := range offsets.SysOffsets {
+		labels := map[string]string{"interface": sysOffset.Interface, "device": sysOffset.Device}
+		samples = append(samples,
+			&metrics.Sample{
+				Name:   "phc_sys_offset_ns",
+				Help:   "CLOCK_REALTIME minus the PHC",
+				Labels: labels,
+				Value:  float64(sysOffset.Offset),
+			},
+			&metrics.Sample{
+				Name:   "phc_sys_offset_residual_ns",
+				Help:   "CLOCK_REALTIME minus the PHC without the whole seconds between their timescales",
+				Labels: labels,
+				Value:  float64(sysOffset.Residual),
+			},
+		)
+	}
+
+	for _, phcOffset := range offsets.PHCOffsets {
+		samples = append(samples, &metrics.Sample{
+			Name: "phc_phc_offset_ns",
+			Help: "The follower PHC minus the leader PHC",
+			Labels: map[string]string{
+				"interface":        phcOffset.Interface,
+				"device":           phcOffset.Device,
+				"leader_interface": phcOffset.LeaderInterface,
+			},
+			Value: float64(phcOffset.Offset),
+		})
+	}
+
+	return samples
+}
+
+// IsActive returns true if CLOCK_REALTIME or a follower PHC is beyond offsetThreshold
+func (offsets *PHCOffsets) IsActive(_ callbacks.OutputType, offsetThreshold float64) bool {
+	for _, sysOffset := range offsets.SysOffsets {
+		if math.Abs(float64(sysOffset.Residual)) > offsetThreshold {
+			return true
+		}
+	}
+
+	for _, phcOffset := range offsets.PHCOffsets {
+		if math.Abs(float64(phcOffset.Offset)) > offsetThreshold {
+			return true
+		}
+	}
+
+	return false
+}
+
+// residualOffset returns offset less the nearest whole number of seconds
+func residualOffset(offset int64) int64 {
+	return offset - int64(math.Round(float64(offset)/float64(nanosecondsPerSecond)))*nanosecondsPerSecond
+}
+
+var phcOffsetFetcher map[string]*fetcher.Fetcher
+
+func init() {
+	phcOffsetFetcher = make(map[string]*fetcher.Fetcher)
+}
+
+func phcFetcherKey(clocks []PHCClock) string {
+	devices := make([]string, 0, len(clocks))
+	for _, clock := range clocks {
+		devices = append(devices, clock.Device)
+	}
+
+	return strings.Join(devices, ",")
+}
+
+func phcOffsetKey(clock PHCClock) string {
+	return "phc-offset-" + filepath.Base(clock.Device)
+}
+
+// phcCtlMeasurement is an offset measured by phc_ctl and when it was logged
+type phcCtlMeasurement struct {
+	// loggedAt is the CLOCK_MONOTONIC nanoseconds phc_ctl logged the offset at, nil if it was not logged
+	loggedAt *int64
+	offset   int64
+}
+
+func parsePHCCtlOffset(output string) (phcCtlMeasurement, error) {
+	measurement := phcCtlMeasurement{}
+
+	match := phcCtlOffsetRegEx.FindStringSubmatch(output)
+	if match == nil {
+		return measurement, fmt.Errorf("no offset in phc_ctl output '%s'", output)
+	}
+
+	offset, err := strconv.ParseInt(match[3], 10, 64)
+	if err != nil {
+		return measurement, fmt.Errorf("failed to parse phc_ctl offset: %w", err)
+	}
+
+	measurement.offset = offset
+
+	if match[1] != "" {
+		seconds, secondsErr := strconv.ParseInt(match[1], 10, 64)
+		milliseconds, millisecondsErr := strconv.ParseInt(match[2], 10, 64)
+
+		if secondsErr == nil && millisecondsErr == nil {
+			loggedAt := seconds*nanosecondsPerSecond + milliseconds*nanosecondsPerMillisecond
+			measurement.loggedAt = &loggedAt
+		}
+	}
+
+	return measurement, nil
+}
+
+// measurementGap returns the nanoseconds from the leader's measurement to the follower's if both were logged
+func measurementGap(leader, follower phcCtlMeasurement) *int64 {
+	if leader.loggedAt == nil || follower.loggedAt == nil {
+		return nil
+	}
+
+	gap := *follower.loggedAt - *leader.loggedAt
+
+	return &gap
+}
+
+// buildPostProcessPHCOffsets derives the offset of each follower from the leader, the first clock,
+// from the offsets of both to CLOCK_REALTIME as they were measured back to back
+func buildPostProcessPHCOffsets(clocks []PHCClock) fetcher.PostProcessFuncType {
+	return func(result map[string]string) (map[string]any, error) {
+		measurements := make([]phcCtlMeasurement, 0, len(clocks))
+		sysOffsets := make([]*PHCSysOffset, 0, len(clocks))
+		phcOffsets := make([]*PHCPairOffset, 0, len(clocks))
+
+		for _, clock := range clocks {
+			measurement, err := parsePHCCtlOffset(result[phcOffsetKey(clock)])
+			if err != nil {
+				return nil, fmt.Errorf("failed to measure %s of %s: %w", clock.Device, clock.Interface, err)
+			}
+
+			measurements = append(measurements, measurement)
+			sysOffsets = append(sysOffsets, &PHCSysOffset{
+				PHCClock:  clock,
+				Timestamp: result["date"],
+				Offset:    measurement.offset,
+				Residual:  residualOffset(measurement.offset),
+			})
+		}
+
+		leader := sysOffsets[0]
+		for i, follower := range sysOffsets[1:] {
+			phcOffsets = append(phcOffsets, &PHCPairOffset{
+				PHCClock:        follower.PHCClock,
+				Timestamp:       follower.Timestamp,
+				LeaderInterface: leader.Interface,
+				LeaderDevice:    leader.Device,
+				MeasurementGap:  measurementGap(measurements[0], measurements[i+1]),
+				// (sys - leader) - (sys - follower)
+				Offset:  leader.Offset - follower.Offset,
+				Derived: true,
+			})
+		}
+
+		return map[string]any{"sysOffsets": sysOffsets, "phcOffsets": phcOffsets}, nil
+	}
+}
+
+// BuildPHCOffsetFetcher populates the fetcher which compares every PHC with CLOCK_REALTIME in one exec
+func BuildPHCOffsetFetcher(clocks []PHCClock) error {
+	if len(clocks) == 0 {
+		return errors.New("no PHCs to measure")
+	}
+
+	commands := make([]fetcher.AddCommandArgs, 0, len(clocks))
+
+	for _, clock := range clocks {
+		commands = append(commands, fetcher.AddCommandArgs{
+			Key:     phcOffsetKey(clock),
+			Command: fmt.Sprintf("phc_ctl %s cmp", clock.Device),
+			Trim:    true,
+		})
+	}
+
+	fetcherInst, err := fetcher.FetcherFactory([]*clients.Cmd{dateCmd}, commands)
+	if err != nil {
+		log.Errorf("failed to create fetcher for phc offsets: %s", err.Error())
+		return fmt.Errorf("failed to create fetcher for phc offsets: %w", err)
+	}
+
+	fetcherInst.SetPostProcessor(buildPostProcessPHCOffsets(clocks))
+	phcOffsetFetcher[phcFetcherKey(clocks)] = fetcherInst
+
+	return nil
+}
+
+// GetPHCOffsets returns the offset of every PHC from CLOCK_REALTIME and of every other PHC from the first
+func GetPHCOffsets(ctx clients.ExecContext, clocks []PHCClock) (*PHCOffsets, error) {
+	phcOffsets := &PHCOffsets{}
+
+	fetcherInst, fetchedInstanceOk := phcOffsetFetcher[phcFetcherKey(clocks)]
+	if !fetchedInstanceOk {
+		err := BuildPHCOffsetFetcher(clocks)
+		if err != nil {
+			return phcOffsets, err
+		}
+
+		fetcherInst, fetchedInstanceOk = phcOffsetFetcher[phcFetcherKey(clocks)]
+		if !fetchedInstanceOk {
+			return phcOffsets, errors.New("failed to create fetcher for phc offsets")
+		}
+	}
+
+	err := fetcherInst.Fetch(ctx, phcOffsets)
+	if err != nil {
+		return phcOffsets, fmt.Errorf("failed to fetch phc offsets: %w", err)
+	}
+
+	return phcOffsets, nil
+}
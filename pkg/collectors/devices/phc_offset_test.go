@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"bufio"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+var _ = Describe("GetPHCOffsets", func() {
+	var clientset *clients.Clientset
+	var response map[string][]byte
+	BeforeEach(func() { //nolint:dupl // this is test setup code
+		clientset = testutils.GetMockedClientSet(testPod)
+		response = make(map[string][]byte)
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			reader := bufio.NewReader(options.Stdin)
+			cmd := ""
+			keepReading := true
+			var cmdSb strings.Builder
+			for keepReading {
+				line, prefix, _ := reader.ReadLine()
+				keepReading = prefix
+				cmdSb.WriteString(string(line))
+			}
+			cmd += cmdSb.String()
+			return response[cmd], []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	})
+
+	clocks := []devices.PHCClock{
+		{Interface: "ens7f0", Device: "/dev/ptp2"},
+		{Interface: "ens8f0", Device: "/dev/ptp6"},
+	}
+
+	getOffsets := func(leaderOutput, followerOutput string) (*devices.PHCOffsets, error) {
+		expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
+		expectedInput += "echo '<phc-offset-ptp2>';phc_ctl /dev/ptp2 cmp;echo '</phc-offset-ptp2>';"
+		expectedInput += "echo '<phc-offset-ptp6>';phc_ctl /dev/ptp6 cmp;echo '</phc-offset-ptp6>';"
+
+		expectedOutput := "<date>\n1686916187.0584\n</date>\n"
+		expectedOutput += "<phc-offset-ptp2>\n" + leaderOutput + "\n</phc-offset-ptp2>\n"
+		expectedOutput += "<phc-offset-ptp6>\n" + followerOutput + "\n</phc-offset-ptp6>\n"
+
+		response[expectedInput] = []byte(expectedOutput)
+
+		ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
+		Expect(err).NotTo(HaveOccurred())
+
+		return devices.GetPHCOffsets(ctx, clocks)
+	}
+
+	When("called GetPHCOffsets", func() {
+		It("should return the offset of each PHC from CLOCK_REALTIME and from the leader", func() {
+			offsets, err := getOffsets(
+				"phc_ctl[357138.013]: offset from CLOCK_REALTIME is -37000000012ns",
+				"phc_ctl[357138.015]: offset from CLOCK_REALTIME is -36999999950ns",
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offsets.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+
+			Expect(offsets.SysOffsets).To(Equal([]*devices.PHCSysOffset{
+				{
+					PHCClock:  clocks[0],
+					Timestamp: "2023-06-16T11:49:47.0584Z",
+					Offset:    -37000000012,
+					Residual:  -12,
+				},
+				{
+					PHCClock:  clocks[1],
+					Timestamp: "2023-06-16T11:49:47.0584Z",
+					Offset:    -36999999950,
+					Residual:  50,
+				},
+			}))
+
+			measurementGap := int64(2000000)
+			Expect(offsets.PHCOffsets).To(Equal([]*devices.PHCPairOffset{
+				{
+					PHCClock:        clocks[1],
+					Timestamp:       "2023-06-16T11:49:47.0584Z",
+					LeaderInterface: "ens7f0",
+					LeaderDevice:    "/dev/ptp2",
+					MeasurementGap:  &measurementGap,
+					Offset:          -62,
+					Derived:         true,
+				},
+			}))
+
+			records, err := offsets.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(HaveLen(3))
+			Expect(records[0].ID).To(Equal("phc/sys-offset"))
+			Expect(records[2].ID).To(Equal("phc/phc-offset"))
+
+			Expect(offsets.IsActive(nil, 100)).To(BeFalse())
+			Expect(offsets.IsActive(nil, 50)).To(BeTrue())
+		})
+
+		It("should leave out the measurement gap when phc_ctl does not log the time", func() {
+			offsets, err := getOffsets(
+				"offset from CLOCK_REALTIME is -37000000012ns",
+				"phc_ctl[357138.015]: offset from CLOCK_REALTIME is -36999999950ns",
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offsets.PHCOffsets).To(HaveLen(1))
+			Expect(offsets.PHCOffsets[0].Offset).To(Equal(int64(-62)))
+			Expect(offsets.PHCOffsets[0].MeasurementGap).To(BeNil())
+		})
+
+		It("should fail when phc_ctl could not measure a PHC", func() {
+			_, err := getOffsets(
+				"phc_ctl[357138.013]: offset from CLOCK_REALTIME is -37000000012ns",
+				"",
+			)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("/dev/ptp6 of ens8f0"))
+		})
+	})
+
+	When("called GetMetrics", func() {
+		It("should label the offsets with the interface and device", func() {
+			offsets, err := getOffsets(
+				"phc_ctl[357138.013]: offset from CLOCK_REALTIME is 4ns",
+				"phc_ctl[357138.015]: offset from CLOCK_REALTIME is 10ns",
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			values := make(map[string]float64)
+			for _, sample := range offsets.GetMetrics() {
+				values[sample.Name+" "+sample.Labels["device"]] = sample.Value
+			}
+
+			Expect(values).To(Equal(map[string]float64{
+				"phc_sys_offset_ns /dev/ptp2":          4,
+				"phc_sys_offset_residual_ns /dev/ptp2": 4,
+				"phc_sys_offset_ns /dev/ptp6":          10,
+				"phc_sys_offset_residual_ns /dev/ptp6": 10,
+				"phc_phc_offset_ns /dev/ptp6":          -6,
+			}))
+		})
+	})
+})
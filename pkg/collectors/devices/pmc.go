@@ -12,9 +12,13 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
 )
 
 type PMCInfo struct {
+	callbacks.Timed
+	PTP4lInstance
+
 	Timestamp               string `fetcherKey:"date"                    json:"timestamp"`
 	TimeSource              string `fetcherKey:"timeSource"              json:"timeSource"`
 	ClockAccuracy           string `fetcherKey:"clockAccuracy"           json:"clockAccuracy"`
@@ -39,6 +43,44 @@ func (gmSetting *PMCInfo) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType,
 	return []*callbacks.AnalyserFormatType{&formatted}, nil
 }
 
+// GetMetrics returns the grandmaster settings to be exposed as metrics
+func (gmSetting *PMCInfo) GetMetrics() []*metrics.Sample {
+	values := []struct {
+		name  string
+		help  string
+		value int
+	}{
+		{"pmc_clock_class", "clockClass from GRANDMASTER_SETTINGS_NP", gmSetting.ClockClass},
+		{"pmc_current_utc_offset", "currentUtcOffset from GRANDMASTER_SETTINGS_NP", gmSetting.CurrentUtcOffset},
+		{"pmc_current_utc_offset_valid", "currentUtcOffsetValid from GRANDMASTER_SETTINGS_NP", gmSetting.CurrentUtcOffsetValid},
+		{"pmc_ptp_timescale", "ptpTimescale from GRANDMASTER_SETTINGS_NP", gmSetting.PtpTimescale},
+		{"pmc_time_traceable", "timeTraceable from GRANDMASTER_SETTINGS_NP", gmSetting.TimeTraceable},
+		{"pmc_frequency_traceable", "frequencyTraceable from GRANDMASTER_SETTINGS_NP", gmSetting.FrequencyTraceable},
+	}
+
+	samples := make([]*metrics.Sample, 0, len(values))
+	for _, v := range values {
+		samples = append(samples, &metrics.Sample{
+			Name:   v.name,
+			Help:   v.help,
+			Labels: map[string]string{"instance": gmSetting.Config},
+			Value:  float64(v.value),
+		})
+	}
+
+	return samples
+}
+
+// IsActive returns true if the grandmaster settings have changed, there is no offset to compare
+func (gmSetting *PMCInfo) IsActive(previous callbacks.OutputType, _ float64) bool {
+	last, ok := previous.(*PMCInfo)
+
+	return ok && (last.ClockClass != gmSetting.ClockClass ||
+		last.TimeSource != gmSetting.TimeSource ||
+		last.TimeTraceable != gmSetting.TimeTraceable ||
+		last.FrequencyTraceable != gmSetting.FrequencyTraceable)
+}
+
 // MapStringToInt converts map string to map int
 func MapStringToInt(inputMap map[string]string) (map[string]int, error) {
 	convertedMap := make(map[string]int)
@@ -55,6 +97,17 @@ func MapStringToInt(inputMap map[string]string) (map[string]int, error) {
 	return convertedMap, nil
 }
 
+const (
+	ptp4lConfigPath = "/var/run/ptp4l.0.config"
+
+	gmSettingsDataSet = "GRANDMASTER_SETTINGS_NP"
+)
+
+// pmcGetCommand returns the pmc command which gets dataSet from the ptp4l instance using configPath
+func pmcGetCommand(configPath, dataSet string) string {
+	return fmt.Sprintf("pmc -u -f %s  'GET %s'", configPath, dataSet)
+}
+
 var (
 	pmcFetcher *fetcher.Fetcher
 	pmcRegEx   = regexp.MustCompile(
@@ -90,22 +143,18 @@ func init() {
 	pmcFetcher.SetPostProcessor(processPMC)
 	pmcFetcher.AddCommand(getDateCommand())
 
-	err := pmcFetcher.AddNewCommand(
-		"PMC",
-		"pmc -u -f /var/run/ptp4l.0.config  'GET GRANDMASTER_SETTINGS_NP'",
-		true,
-	)
+	err := pmcFetcher.AddNewCommand("PMC", pmcGetCommand(ptp4lConfigPath, gmSettingsDataSet), true)
 	if err != nil {
 		panic(fmt.Errorf("failed to setup PMC fetcher %w", err))
 	}
 }
 
-func processPMC(result map[string]string) (map[string]any, error) { //nolint:funlen // allow slightly long function
-	processedResult := make(map[string]any)
-	match := pmcRegEx.FindStringSubmatch(result["PMC"])
+// parseGMSettings parses the response to GET GRANDMASTER_SETTINGS_NP
+func parseGMSettings(output string) (*PMCInfo, error) {
+	match := pmcRegEx.FindStringSubmatch(output)
 
 	if len(match) == 0 {
-		return processedResult, fmt.Errorf("unable to parse pmc output: %s", result["PMC"])
+		return nil, fmt.Errorf("unable to parse pmc output: %s", output)
 	}
 
 	valuesToConvert := map[string]string{
@@ -120,21 +169,44 @@ func processPMC(result map[string]string) (map[string]any, error) { //nolint:fun
 	}
 
 	convertedMap, err := MapStringToInt(valuesToConvert)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PMCInfo{
+		TimeSource:              match[11],
+		ClockAccuracy:           match[2],
+		OffsetScaledLogVariance: match[3],
+		ClockClass:              convertedMap["clockClass"],
+		CurrentUtcOffset:        convertedMap["currentUtcOffset"],
+		Leap61:                  convertedMap["leap61"],
+		Leap59:                  convertedMap["leap59"],
+		CurrentUtcOffsetValid:   convertedMap["currentUtcOffsetValid"],
+		PtpTimescale:            convertedMap["ptpTimescale"],
+		TimeTraceable:           convertedMap["timeTraceable"],
+		FrequencyTraceable:      convertedMap["frequencyTraceable"],
+	}, nil
+}
+
+func processPMC(result map[string]string) (map[string]any, error) {
+	processedResult := make(map[string]any)
+
+	gmSettings, err := parseGMSettings(result["PMC"])
 	if err != nil {
 		return processedResult, err
 	}
 
-	processedResult["timeSource"] = match[11]
-	processedResult["clockAccuracy"] = match[2]
-	processedResult["offsetScaledLogVariance"] = match[3]
-	processedResult["clockClass"] = convertedMap["clockClass"]
-	processedResult["currentUtcOffset"] = convertedMap["currentUtcOffset"]
-	processedResult["leap61"] = convertedMap["leap61"]
-	processedResult["leap59"] = convertedMap["leap59"]
-	processedResult["currentUtcOffsetValid"] = convertedMap["currentUtcOffsetValid"]
-	processedResult["ptpTimescale"] = convertedMap["ptpTimescale"]
-	processedResult["timeTraceable"] = convertedMap["timeTraceable"]
-	processedResult["frequencyTraceable"] = convertedMap["frequencyTraceable"]
+	processedResult["timeSource"] = gmSettings.TimeSource
+	processedResult["clockAccuracy"] = gmSettings.ClockAccuracy
+	processedResult["offsetScaledLogVariance"] = gmSettings.OffsetScaledLogVariance
+	processedResult["clockClass"] = gmSettings.ClockClass
+	processedResult["currentUtcOffset"] = gmSettings.CurrentUtcOffset
+	processedResult["leap61"] = gmSettings.Leap61
+	processedResult["leap59"] = gmSettings.Leap59
+	processedResult["currentUtcOffsetValid"] = gmSettings.CurrentUtcOffsetValid
+	processedResult["ptpTimescale"] = gmSettings.PtpTimescale
+	processedResult["timeTraceable"] = gmSettings.TimeTraceable
+	processedResult["frequencyTraceable"] = gmSettings.FrequencyTraceable
 
 	return processedResult, nil
 }
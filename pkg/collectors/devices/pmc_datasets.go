@@ -0,0 +1,614 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	currentDataSet = "CURRENT_DATA_SET"
+	parentDataSet  = "PARENT_DATA_SET"
+	portDataSet    = "PORT_DATA_SET"
+	timeStatusNP   = "TIME_STATUS_NP"
+
+	pmcResponseMarker = "RESPONSE MANAGEMENT"
+)
+
+// portStates are the values of the IEEE 1588 portState enumeration, linuxptp adds GRAND_MASTER
+var portStates = map[string]float64{
+	"INITIALIZING": 1,
+	"FAULTY":       2,
+	"DISABLED":     3,
+	"LISTENING":    4,
+	"PRE_MASTER":   5,
+	"MASTER":       6,
+	"PASSIVE":      7,
+	"UNCALIBRATED": 8,
+	"SLAVE":        9,
+	"GRAND_MASTER": 10,
+}
+
+// PMCCurrentDataSet is the response to GET CURRENT_DATA_SET
+type PMCCurrentDataSet struct {
+	PTP4lInstance
+
+	Timestamp        string  `json:"timestamp"`
+	StepsRemoved     int     `json:"stepsRemoved"`
+	OffsetFromMaster float64 `json:"offsetFromMaster"`
+	MeanPathDelay    float64 `json:"meanPathDelay"`
+}
+
+// PMCParentDataSet is the response to GET PARENT_DATA_SET
+type PMCParentDataSet struct {
+	PTP4lInstance
+
+	Timestamp                          string `json:"timestamp"`
+	ParentPortIdentity                 string `json:"parentPortIdentity"`
+	GrandmasterIdentity                string `json:"grandmasterIdentity"`
+	GrandmasterClockAccuracy           string `json:"grandmasterClockAccuracy"`
+	GrandmasterOffsetScaledLogVariance string `json:"grandmasterOffsetScaledLogVariance"`
+	GrandmasterClockClass              int    `json:"grandmasterClockClass"`
+	GrandmasterPriority1               int    `json:"grandmasterPriority1"`
+	GrandmasterPriority2               int    `json:"grandmasterPriority2"`
+}
+
+// PMCPortDataSet is the response of one port to GET PORT_DATA_SET
+type PMCPortDataSet struct {
+	PTP4lInstance
+
+	Timestamp              string `json:"timestamp"`
+	PortIdentity           string `json:"portIdentity"`
+	PortState              string `json:"portState"`
+	LogMinDelayReqInterval int    `json:"logMinDelayReqInterval"`
+	LogAnnounceInterval    int    `json:"logAnnounceInterval"`
+	AnnounceReceiptTimeout int    `json:"announceReceiptTimeout"`
+	LogSyncInterval        int    `json:"logSyncInterval"`
+	DelayMechanism         int    `json:"delayMechanism"`
+	VersionNumber          int    `json:"versionNumber"`
+}
+
+// PMCTimeStatus is the response to GET TIME_STATUS_NP
+type PMCTimeStatus struct {
+	PTP4lInstance
+
+	Timestamp                  string  `json:"timestamp"`
+	GMIdentity                 string  `json:"gmIdentity"`
+	MasterOffset               int64   `json:"masterOffset"`
+	IngressTime                int64   `json:"ingressTime"`
+	CumulativeScaledRateOffset float64 `json:"cumulativeScaledRateOffset"`
+	GMPresent                  bool    `json:"gmPresent"`
+}
+
+// PMCDataSets is the grandmaster settings of a ptp4l instance along with the datasets needed to follow a boundary clock,
+// a dataset ptp4l did not answer is left out
+type PMCDataSets struct {
+	GMSettings *PMCInfo           `json:"gmSettings"`
+	Current    *PMCCurrentDataSet `json:"currentDataSet,omitempty"`
+	Parent     *PMCParentDataSet  `json:"parentDataSet,omitempty"`
+	TimeStatus *PMCTimeStatus     `json:"timeStatus,omitempty"`
+	Instance   *PTP4lInstance     `json:"ptp4lInstance"`
+	Ports      []*PMCPortDataSet  `json:"portDataSets,omitempty"`
+	Timestamp  string             `json:"timestamp"`
+}
+
+// PMCInstances is the datasets of every ptp4l instance taken by the same exec
+type PMCInstances struct {
+	callbacks.Timed
+
+	Timestamp string         `fetcherKey:"date"      json:"timestamp"`
+	Instances []*PMCDataSets `fetcherKey:"instances" json:"instances"`
+}
+
+// GetAnalyserFormat returns the records of every instance
+func (pmcInstances *PMCInstances) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted := make([]*callbacks.AnalyserFormatType, 0)
+
+	for _, dataSets := range pmcInstances.Instances {
+		instanceFormatted, err := dataSets.GetAnalyserFormat()
+		if err != nil {
+			return formatted, err
+		}
+
+		formatted = append(formatted, instanceFormatted...)
+	}
+
+	return formatted, nil
+}
+
+// GetMetrics returns the samples of every instance, which are told apart by their instance label
+func (pmcInstances *PMCInstances) GetMetrics() []*metrics.Sample {
+	samples := make([]*metrics.Sample, 0)
+
+	for _, dataSets := range pmcInstances.Instances {
+		samples = append(samples, dataSets.GetMetrics()...)
+	}
+
+	return samples
+}
+
+// IsActive returns true if any of the instances is active compared to the same instance in previous
+func (pmcInstances *PMCInstances) IsActive(previous callbacks.OutputType, offsetThreshold float64) bool {
+	lastInstances := make(map[string]*PMCDataSets)
+
+	if last, ok := previous.(*PMCInstances); ok {
+		for _, dataSets := range last.Instances {
+			lastInstances[dataSets.Instance.Config] = dataSets
+		}
+	}
+
+	for _, dataSets := range pmcInstances.Instances {
+		var lastDataSets callbacks.OutputType
+		if found, ok := lastInstances[dataSets.Instance.Config]; ok {
+			lastDataSets = found
+		}
+
+		if dataSets.IsActive(lastDataSets, offsetThreshold) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetAnalyserFormat returns a record for each dataset and for each port
+func (dataSets *PMCDataSets) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted, err := dataSets.GMSettings.GetAnalyserFormat()
+	if err != nil {
+		return formatted, err
+	}
+
+	if dataSets.Current != nil {
+		formatted = append(formatted, &callbacks.AnalyserFormatType{ID: "phc/current-ds", Data: dataSets.Current})
+	}
+
+	if dataSets.Parent != nil {
+		formatted = append(formatted, &callbacks.AnalyserFormatType{ID: "phc/parent-ds", Data: dataSets.Parent})
+	}
+
+	for _, port := range dataSets.Ports {
+		formatted = append(formatted, &callbacks.AnalyserFormatType{ID: "phc/port-ds", Data: port})
+	}
+
+	if dataSets.TimeStatus != nil {
+		formatted = append(formatted, &callbacks.AnalyserFormatType{ID: "phc/time-status", Data: dataSets.TimeStatus})
+	}
+
+	return formatted, nil
+}
+
+// GetMetrics returns the grandmaster settings and datasets to be exposed as metrics
+func (dataSets *PMCDataSets) GetMetrics() []*metrics.Sample {
+	samples := dataSets.GMSettings.GetMetrics()
+
+	addSample := func(name, help string, value float64) {
+		samples = append(samples, &metrics.Sample{
+			Name:   name,
+			Help:   help,
+			Labels: map[string]string{"instance": dataSets.Instance.Config},
+			Value:  value,
+		})
+	}
+
+	if dataSets.Current != nil {
+		addSample("pmc_offset_from_master_ns", "offsetFromMaster from CURRENT_DATA_SET", dataSets.Current.OffsetFromMaster)
+		addSample("pmc_mean_path_delay_ns", "meanPathDelay from CURRENT_DATA_SET", dataSets.Current.MeanPathDelay)
+		addSample("pmc_steps_removed", "stepsRemoved from CURRENT_DATA_SET", float64(dataSets.Current.StepsRemoved))
+	}
+
+	if dataSets.Parent != nil {
+		addSample("pmc_grandmaster_clock_class", "gm.ClockClass from PARENT_DATA_SET",
+			float64(dataSets.Parent.GrandmasterClockClass))
+	}
+
+	if dataSets.TimeStatus != nil {
+		gmPresent := 0.0
+		if dataSets.TimeStatus.GMPresent {
+			gmPresent = 1
+		}
+
+		addSample("pmc_master_offset_ns", "master_offset from TIME_STATUS_NP", float64(dataSets.TimeStatus.MasterOffset))
+		addSample("pmc_gm_present", "gmPresent from TIME_STATUS_NP", gmPresent)
+	}
+
+	for _, port := range dataSets.Ports {
+		state, ok := portStates[port.PortState]
+		if !ok {
+			continue
+		}
+
+		samples = append(samples, &metrics.Sample{
+			Name: "pmc_port_state",
+			Help: "portState from PORT_DATA_SET (1 initializing, 2 faulty, 3 disabled, 4 listening, 5 pre master, " +
+				"6 master, 7 passive, 8 uncalibrated, 9 slave, 10 grand master)",
+			Labels: map[string]string{"instance": dataSets.Instance.Config, "port": port.PortIdentity},
+			Value:  state,
+		})
+	}
+
+	return samples
+}
+
+// IsActive returns true if the grandmaster settings, grandmaster or a port state have changed
+// or the offset from the master is beyond offsetThreshold
+func (dataSets *PMCDataSets) IsActive(previous callbacks.OutputType, offsetThreshold float64) bool {
+	if dataSets.Current != nil && math.Abs(dataSets.Current.OffsetFromMaster) > offsetThreshold {
+		return true
+	}
+
+	last, ok := previous.(*PMCDataSets)
+	if !ok {
+		return false
+	}
+
+	if last.GMSettings != nil && dataSets.GMSettings.IsActive(last.GMSettings, offsetThreshold) {
+		return true
+	}
+
+	if last.Parent != nil && dataSets.Parent != nil &&
+		last.Parent.GrandmasterIdentity != dataSets.Parent.GrandmasterIdentity {
+		return true
+	}
+
+	lastStates := make(map[string]string)
+	for _, port := range last.Ports {
+		lastStates[port.PortIdentity] = port.PortState
+	}
+
+	for _, port := range dataSets.Ports {
+		if lastState, found := lastStates[port.PortIdentity]; found && lastState != port.PortState {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parsePMCResponses returns the fields of each response to GET dataSet in output,
+// PORT_DATA_SET has a response for each port
+func parsePMCResponses(output, dataSet string) []map[string]string {
+	responses := make([]map[string]string, 0)
+
+	var response map[string]string
+
+	for line := range strings.SplitSeq(output, "\n") {
+		fields := strings.Fields(line)
+
+		if strings.Contains(line, pmcResponseMarker) {
+			response = nil
+
+			if fields[len(fields)-1] == dataSet {
+				response = make(map[string]string)
+				responses = append(responses, response)
+			}
+
+			continue
+		}
+
+		if response == nil || len(fields) < 2 { //nolint:mnd // name and value
+			continue
+		}
+
+		response[fields[0]] = strings.Join(fields[1:], " ")
+	}
+
+	return responses
+}
+
+// pmcFields converts the fields of a response, keeping the first error
+type pmcFields struct {
+	err    error
+	values map[string]string
+}
+
+func (fields *pmcFields) string(name string) string {
+	value, ok := fields.values[name]
+	if !ok && fields.err == nil {
+		fields.err = fmt.Errorf("missing %s", name)
+	}
+
+	return value
+}
+
+func (fields *pmcFields) int(name string) int {
+	value, err := strconv.Atoi(fields.string(name))
+	if err != nil && fields.err == nil {
+		fields.err = fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return value
+}
+
+func (fields *pmcFields) int64(name string) int64 {
+	value, err := strconv.ParseInt(fields.string(name), 10, 64)
+	if err != nil && fields.err == nil {
+		fields.err = fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return value
+}
+
+func (fields *pmcFields) float(name string) float64 {
+	value, err := strconv.ParseFloat(fields.string(name), 64)
+	if err != nil && fields.err == nil {
+		fields.err = fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return value
+}
+
+func (fields *pmcFields) bool(name string) bool {
+	value, err := strconv.ParseBool(fields.string(name))
+	if err != nil && fields.err == nil {
+		fields.err = fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return value
+}
+
+// parsePMCResponse returns the fields of the only response to GET dataSet in output
+func parsePMCResponse(output, dataSet string) (*pmcFields, error) {
+	responses := parsePMCResponses(output, dataSet)
+	if len(responses) != 1 {
+		return nil, fmt.Errorf("expected one %s response but found %d", dataSet, len(responses))
+	}
+
+	return &pmcFields{values: responses[0]}, nil
+}
+
+func parseCurrentDataSet(output, timestamp string) (*PMCCurrentDataSet, error) {
+	fields, err := parsePMCResponse(output, currentDataSet)
+	if err != nil {
+		return nil, err
+	}
+
+	current := &PMCCurrentDataSet{
+		Timestamp:        timestamp,
+		StepsRemoved:     fields.int("stepsRemoved"),
+		OffsetFromMaster: fields.float("offsetFromMaster"),
+		MeanPathDelay:    fields.float("meanPathDelay"),
+	}
+
+	return current, fields.err
+}
+
+func parseParentDataSet(output, timestamp string) (*PMCParentDataSet, error) {
+	fields, err := parsePMCResponse(output, parentDataSet)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := &PMCParentDataSet{
+		Timestamp:                          timestamp,
+		ParentPortIdentity:                 fields.string("parentPortIdentity"),
+		GrandmasterIdentity:                fields.string("grandmasterIdentity"),
+		GrandmasterClockAccuracy:           fields.string("gm.ClockAccuracy"),
+		GrandmasterOffsetScaledLogVariance: fields.string("gm.OffsetScaledLogVariance"),
+		GrandmasterClockClass:              fields.int("gm.ClockClass"),
+		GrandmasterPriority1:               fields.int("grandmasterPriority1"),
+		GrandmasterPriority2:               fields.int("grandmasterPriority2"),
+	}
+
+	return parent, fields.err
+}
+
+func parsePortDataSets(output, timestamp string) ([]*PMCPortDataSet, error) {
+	responses := parsePMCResponses(output, portDataSet)
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("found no %s responses", portDataSet)
+	}
+
+	ports := make([]*PMCPortDataSet, 0, len(responses))
+
+	for _, response := range responses {
+		fields := &pmcFields{values: response}
+		port := &PMCPortDataSet{
+			Timestamp:              timestamp,
+			PortIdentity:           fields.string("portIdentity"),
+			PortState:              fields.string("portState"),
+			LogMinDelayReqInterval: fields.int("logMinDelayReqInterval"),
+			LogAnnounceInterval:    fields.int("logAnnounceInterval"),
+			AnnounceReceiptTimeout: fields.int("announceReceiptTimeout"),
+			LogSyncInterval:        fields.int("logSyncInterval"),
+			DelayMechanism:         fields.int("delayMechanism"),
+			VersionNumber:          fields.int("versionNumber"),
+		}
+
+		if fields.err != nil {
+			return nil, fields.err
+		}
+
+		ports = append(ports, port)
+	}
+
+	return ports, nil
+}
+
+func parseTimeStatus(output, timestamp string) (*PMCTimeStatus, error) {
+	fields, err := parsePMCResponse(output, timeStatusNP)
+	if err != nil {
+		return nil, err
+	}
+
+	timeStatus := &PMCTimeStatus{
+		Timestamp:                  timestamp,
+		GMIdentity:                 fields.string("gmIdentity"),
+		MasterOffset:               fields.int64("master_offset"),
+		IngressTime:                fields.int64("ingress_time"),
+		CumulativeScaledRateOffset: fields.float("cumulativeScaledRateOffset"),
+		GMPresent:                  fields.bool("gmPresent"),
+	}
+
+	return timeStatus, fields.err
+}
+
+var pmcInstancesFetcher map[string]*fetcher.Fetcher
+
+func init() {
+	pmcInstancesFetcher = make(map[string]*fetcher.Fetcher)
+}
+
+func instancesFetcherKey(instances []*PTP4lInstance, client PMCClient) string {
+	configs := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		configs = append(configs, instance.Config)
+	}
+
+	return client.HelperPath + ":" + strings.Join(configs, ",")
+}
+
+// BuildPMCInstancesFetcher populates the fetcher which gets the datasets of all of the instances in one exec
+// using client
+func BuildPMCInstancesFetcher(instances []*PTP4lInstance, client PMCClient) error {
+	fetcherInst := fetcher.NewFetcher()
+	fetcherInst.AddCommand(getDateCommand())
+
+	for _, instance := range instances {
+		for _, command := range []struct{ suffix, dataSet string }{
+			{"", gmSettingsDataSet},
+			{"-current", currentDataSet},
+			{"-parent", parentDataSet},
+			{"-port", portDataSet},
+			{"-time-status-np", timeStatusNP},
+		} {
+			err := fetcherInst.AddNewCommand(
+				instance.fetcherKey()+command.suffix,
+				client.getCommand(instance.ConfigPath(), command.dataSet),
+				true,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to setup PMC datasets fetcher %w", err)
+			}
+		}
+	}
+
+	fetcherInst.SetPostProcessor(buildPostProcessPMCInstances(instances, client.parsers()))
+	pmcInstancesFetcher[instancesFetcherKey(instances, client)] = fetcherInst
+
+	return nil
+}
+
+// parsePMCDataSets requires the grandmaster settings of the instance, any other dataset which can not be parsed is left out
+func parsePMCDataSets(result map[string]string, instance *PTP4lInstance, parsers *pmcParsers) (*PMCDataSets, error) {
+	key := instance.fetcherKey()
+	timestamp := result["date"]
+
+	gmSettings, err := parsers.gmSettings(result[key])
+	if err != nil {
+		return nil, err
+	}
+
+	gmSettings.Timestamp = timestamp
+	gmSettings.PTP4lInstance = *instance
+	dataSets := &PMCDataSets{GMSettings: gmSettings, Instance: instance, Timestamp: timestamp}
+
+	errs := make([]error, 0)
+
+	if current, parseErr := parsers.current(result[key+"-current"], timestamp); parseErr == nil {
+		current.PTP4lInstance = *instance
+		dataSets.Current = current
+	} else {
+		errs = append(errs, parseErr)
+	}
+
+	if parent, parseErr := parsers.parent(result[key+"-parent"], timestamp); parseErr == nil {
+		parent.PTP4lInstance = *instance
+		dataSets.Parent = parent
+	} else {
+		errs = append(errs, parseErr)
+	}
+
+	if ports, parseErr := parsers.ports(result[key+"-port"], timestamp); parseErr == nil {
+		for _, port := range ports {
+			port.PTP4lInstance = *instance
+		}
+
+		dataSets.Ports = ports
+	} else {
+		errs = append(errs, parseErr)
+	}
+
+	if timeStatus, parseErr := parsers.timeStatus(result[key+"-time-status-np"], timestamp); parseErr == nil {
+		timeStatus.PTP4lInstance = *instance
+		dataSets.TimeStatus = timeStatus
+	} else {
+		errs = append(errs, parseErr)
+	}
+
+	if len(errs) > 0 {
+		log.Warnf("leaving out PMC datasets of %s: %s", instance.Config, utils.MakeCompositeError("", errs).Error())
+	}
+
+	return dataSets, nil
+}
+
+// buildPostProcessPMCInstances leaves out an instance whose grandmaster settings can not be parsed
+// unless none of them can
+func buildPostProcessPMCInstances(instances []*PTP4lInstance, parsers *pmcParsers) fetcher.PostProcessFuncType {
+	return func(result map[string]string) (map[string]any, error) {
+		processedResult := make(map[string]any)
+		parsed := make([]*PMCDataSets, 0, len(instances))
+		errs := make([]error, 0)
+
+		for _, instance := range instances {
+			dataSets, err := parsePMCDataSets(result, instance, parsers)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", instance.Config, err))
+				continue
+			}
+
+			parsed = append(parsed, dataSets)
+		}
+
+		if len(parsed) == 0 {
+			return processedResult, utils.MakeCompositeError("failed to parse the PMC output of every ptp4l instance", errs) //nolint:wrapcheck // this just combines errors
+		}
+
+		if len(errs) > 0 {
+			log.Warnf("leaving out ptp4l instances: %s", utils.MakeCompositeError("", errs).Error())
+		}
+
+		processedResult["instances"] = parsed
+
+		return processedResult, nil
+	}
+}
+
+// GetPMCInstances returns the grandmaster settings along with the current, parent, port and time status datasets
+// of each ptp4l instance using client
+func GetPMCInstances(ctx clients.ExecContext, instances []*PTP4lInstance, client PMCClient) (*PMCInstances, error) {
+	pmcInstances := &PMCInstances{}
+
+	fetcherInst, fetchedInstanceOk := pmcInstancesFetcher[instancesFetcherKey(instances, client)]
+	if !fetchedInstanceOk {
+		err := BuildPMCInstancesFetcher(instances, client)
+		if err != nil {
+			return pmcInstances, err
+		}
+
+		fetcherInst, fetchedInstanceOk = pmcInstancesFetcher[instancesFetcherKey(instances, client)]
+		if !fetchedInstanceOk {
+			return pmcInstances, errors.New("failed to create fetcher for the PMC datasets")
+		}
+	}
+
+	err := fetcherInst.Fetch(ctx, pmcInstances)
+	if err != nil {
+		log.Debugf("failed to fetch PMC datasets %s", err.Error())
+		return pmcInstances, fmt.Errorf("failed to fetch PMC datasets %w", err)
+	}
+
+	return pmcInstances, nil
+}
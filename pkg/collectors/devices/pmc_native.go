@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/ptpmgmt"
+)
+
+// PMCClient is how the management datasets are got from ptp4l
+type PMCClient struct {
+	// HelperPath is the path of this tool in the PTP daemon container, it is empty for linuxptp's pmc
+	HelperPath string
+}
+
+// PMCClientBinary execs linuxptp's pmc and parses its text output
+var PMCClientBinary = PMCClient{}
+
+// NewPMCClientNative returns the client which execs this tool's pmc command at helperPath,
+// which talks to ptp4l's socket itself and prints JSON
+func NewPMCClientNative(helperPath string) PMCClient {
+	return PMCClient{HelperPath: helperPath}
+}
+
+// IsNative returns true if the client is this tool rather than linuxptp's pmc
+func (client PMCClient) IsNative() bool {
+	return client.HelperPath != ""
+}
+
+func (client PMCClient) String() string {
+	if client.IsNative() {
+		return "native"
+	}
+
+	return "pmc"
+}
+
+// pmcParsers parse the output of a PMCClient
+type pmcParsers struct {
+	gmSettings func(output string) (*PMCInfo, error)
+	current    func(output, timestamp string) (*PMCCurrentDataSet, error)
+	parent     func(output, timestamp string) (*PMCParentDataSet, error)
+	ports      func(output, timestamp string) ([]*PMCPortDataSet, error)
+	timeStatus func(output, timestamp string) (*PMCTimeStatus, error)
+}
+
+var (
+	pmcTextParsers = &pmcParsers{
+		gmSettings: parseGMSettings,
+		current:    parseCurrentDataSet,
+		parent:     parseParentDataSet,
+		ports:      parsePortDataSets,
+		timeStatus: parseTimeStatus,
+	}
+	pmcNativeParsers = &pmcParsers{
+		gmSettings: parseNativeGMSettings,
+		current:    parseNativeCurrentDataSet,
+		parent:     parseNativeParentDataSet,
+		ports:      parseNativePortDataSets,
+		timeStatus: parseNativeTimeStatus,
+	}
+)
+
+func (client PMCClient) getCommand(configPath, dataSet string) string {
+	if client.IsNative() {
+		return fmt.Sprintf("%s pmc -f %s 'GET %s'", client.HelperPath, configPath, dataSet)
+	}
+
+	return pmcGetCommand(configPath, dataSet)
+}
+
+func (client PMCClient) parsers() *pmcParsers {
+	if client.IsNative() {
+		return pmcNativeParsers
+	}
+
+	return pmcTextParsers
+}
+
+// parseNativeResponses returns the data of each response to GET id in the JSON lines of output
+func parseNativeResponses[T any](output string, id ptpmgmt.ManagementID) ([]T, error) {
+	dataSets := make([]T, 0)
+
+	for line := range strings.SplitSeq(output, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "{") {
+			continue
+		}
+
+		response := &ptpmgmt.Response{}
+
+		err := json.Unmarshal([]byte(line), response)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s response: %w", id, err)
+		}
+
+		if response.ManagementID != id {
+			continue
+		}
+
+		dataSet, ok := response.Data.(T)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %T in %s response", response.Data, id)
+		}
+
+		dataSets = append(dataSets, dataSet)
+	}
+
+	return dataSets, nil
+}
+
+// parseNativeResponse returns the data of the only response to GET id in output
+func parseNativeResponse[T any](output string, id ptpmgmt.ManagementID) (T, error) {
+	dataSets, err := parseNativeResponses[T](output, id)
+	if err == nil && len(dataSets) != 1 {
+		err = fmt.Errorf("expected one %s response but found %d", id, len(dataSets))
+	}
+
+	if err != nil {
+		var none T
+		return none, err
+	}
+
+	return dataSets[0], nil
+}
+
+func boolToInt(value bool) int {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+func parseNativeGMSettings(output string) (*PMCInfo, error) {
+	gmSettings, err := parseNativeResponse[*ptpmgmt.GrandmasterSettings](output, ptpmgmt.GrandmasterSettingsNP)
+	if err != nil {
+		return nil, err
+	}
+
+	// formatted as pmc prints them
+	return &PMCInfo{
+		TimeSource:              fmt.Sprintf("0x%02x", gmSettings.TimeSource),
+		ClockAccuracy:           fmt.Sprintf("0x%02x", gmSettings.ClockAccuracy),
+		OffsetScaledLogVariance: fmt.Sprintf("0x%04x", gmSettings.OffsetScaledLogVariance),
+		ClockClass:              int(gmSettings.ClockClass),
+		CurrentUtcOffset:        int(gmSettings.CurrentUtcOffset),
+		Leap61:                  boolToInt(gmSettings.Leap61),
+		Leap59:                  boolToInt(gmSettings.Leap59),
+		CurrentUtcOffsetValid:   boolToInt(gmSettings.CurrentUtcOffsetValid),
+		PtpTimescale:            boolToInt(gmSettings.PtpTimescale),
+		TimeTraceable:           boolToInt(gmSettings.TimeTraceable),
+		FrequencyTraceable:      boolToInt(gmSettings.FrequencyTraceable),
+	}, nil
+}
+
+func parseNativeCurrentDataSet(output, timestamp string) (*PMCCurrentDataSet, error) {
+	current, err := parseNativeResponse[*ptpmgmt.CurrentDS](output, ptpmgmt.CurrentDataSet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PMCCurrentDataSet{
+		Timestamp:        timestamp,
+		StepsRemoved:     int(current.StepsRemoved),
+		OffsetFromMaster: current.OffsetFromMaster,
+		MeanPathDelay:    current.MeanPathDelay,
+	}, nil
+}
+
+func parseNativeParentDataSet(output, timestamp string) (*PMCParentDataSet, error) {
+	parent, err := parseNativeResponse[*ptpmgmt.ParentDS](output, ptpmgmt.ParentDataSet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PMCParentDataSet{
+		Timestamp:                          timestamp,
+		ParentPortIdentity:                 parent.ParentPortIdentity,
+		GrandmasterIdentity:                parent.GrandmasterIdentity,
+		GrandmasterClockAccuracy:           fmt.Sprintf("0x%02x", parent.GrandmasterClockQuality.ClockAccuracy),
+		GrandmasterOffsetScaledLogVariance: fmt.Sprintf("0x%04x", parent.GrandmasterClockQuality.OffsetScaledLogVariance),
+		GrandmasterClockClass:              int(parent.GrandmasterClockQuality.ClockClass),
+		GrandmasterPriority1:               int(parent.GrandmasterPriority1),
+		GrandmasterPriority2:               int(parent.GrandmasterPriority2),
+	}, nil
+}
+
+func parseNativePortDataSets(output, timestamp string) ([]*PMCPortDataSet, error) {
+	responses, err := parseNativeResponses[*ptpmgmt.PortDS](output, ptpmgmt.PortDataSet)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("found no %s responses", portDataSet)
+	}
+
+	ports := make([]*PMCPortDataSet, 0, len(responses))
+
+	for _, port := range responses {
+		ports = append(ports, &PMCPortDataSet{
+			Timestamp:              timestamp,
+			PortIdentity:           port.PortIdentity,
+			PortState:              port.PortState,
+			LogMinDelayReqInterval: int(port.LogMinDelayReqInterval),
+			LogAnnounceInterval:    int(port.LogAnnounceInterval),
+			AnnounceReceiptTimeout: int(port.AnnounceReceiptTimeout),
+			LogSyncInterval:        int(port.LogSyncInterval),
+			DelayMechanism:         int(port.DelayMechanism),
+			VersionNumber:          int(port.VersionNumber),
+		})
+	}
+
+	return ports, nil
+}
+
+func parseNativeTimeStatus(output, timestamp string) (*PMCTimeStatus, error) {
+	timeStatus, err := parseNativeResponse[*ptpmgmt.TimeStatus](output, ptpmgmt.TimeStatusNP)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PMCTimeStatus{
+		Timestamp:                  timestamp,
+		GMIdentity:                 timeStatus.GMIdentity,
+		MasterOffset:               timeStatus.MasterOffset,
+		IngressTime:                timeStatus.IngressTime,
+		CumulativeScaledRateOffset: timeStatus.CumulativeScaledRateOffset,
+		GMPresent:                  timeStatus.GMPresent,
+	}, nil
+}
@@ -4,6 +4,7 @@ package devices_test
 
 import (
 	"bufio"
+	"encoding/json"
 	"net/url"
 	"strings"
 
@@ -86,4 +87,318 @@ var _ = Describe("GetPMC", func() {
 
 		})
 	})
+
+	When("called GetPMCInstances", func() {
+		pmcCommands := func(config string) string {
+			key := "PMC-" + strings.ReplaceAll(strings.TrimSuffix(config, ".config"), ".", "-")
+			commands := ""
+
+			for _, command := range [][2]string{
+				{key, "GRANDMASTER_SETTINGS_NP"},
+				{key + "-current", "CURRENT_DATA_SET"},
+				{key + "-parent", "PARENT_DATA_SET"},
+				{key + "-port", "PORT_DATA_SET"},
+				{key + "-time-status-np", "TIME_STATUS_NP"},
+			} {
+				commands += "echo '<" + command[0] + ">';pmc -u -f /var/run/" + config + "  'GET " + command[1] + "';" +
+					"echo '</" + command[0] + ">';"
+			}
+
+			return commands
+		}
+
+		portResponse := func(port, state string) []string {
+			return []string{
+				"	507c6f.fffe.30fbe8-" + port + " seq 0 RESPONSE MANAGEMENT PORT_DATA_SET ",
+				"		portIdentity            507c6f.fffe.30fbe8-" + port,
+				"		portState               " + state,
+				"		logMinDelayReqInterval  -4",
+				"		peerMeanPathDelay       0",
+				"		logAnnounceInterval     1",
+				"		announceReceiptTimeout  3",
+				"		logSyncInterval         -4",
+				"		delayMechanism          1",
+				"		logMinPdelayReqInterval 0",
+				"		versionNumber           2",
+			}
+		}
+
+		pmcOutput := func(key string, portDataSet []string) []string {
+			lines := []string{
+				"<" + key + ">",
+				"sending: GET GRANDMASTER_SETTINGS_NP",
+				"	507c6f.fffe.30fbe8-0 seq 0 RESPONSE MANAGEMENT GRANDMASTER_SETTINGS_NP",
+				"		clockClass              248",
+				"		clockAccuracy           0xfe",
+				"		offsetScaledLogVariance 0xffff",
+				"		currentUtcOffset        37",
+				"		leap61                  0",
+				"		leap59                  0",
+				"		currentUtcOffsetValid   0",
+				"		ptpTimescale            1",
+				"		timeTraceable           0",
+				"		frequencyTraceable      0",
+				"		timeSource              0xa0",
+				"</" + key + ">",
+				"<" + key + "-current>",
+				"sending: GET CURRENT_DATA_SET",
+				"	507c6f.fffe.30fbe8-0 seq 0 RESPONSE MANAGEMENT CURRENT_DATA_SET ",
+				"		stepsRemoved     1",
+				"		offsetFromMaster -2.0",
+				"		meanPathDelay    112.0",
+				"</" + key + "-current>",
+				"<" + key + "-parent>",
+				"sending: GET PARENT_DATA_SET",
+				"	507c6f.fffe.30fbe8-0 seq 0 RESPONSE MANAGEMENT PARENT_DATA_SET ",
+				"		parentPortIdentity                    ec4670.fffe.0a7fe1-1",
+				"		parentStats                           0",
+				"		observedParentOffsetScaledLogVariance 0xffff",
+				"		observedParentClockPhaseChangeRate    0x7fffffff",
+				"		grandmasterPriority1                  128",
+				"		gm.ClockClass                         6",
+				"		gm.ClockAccuracy                      0x21",
+				"		gm.OffsetScaledLogVariance            0x4e5d",
+				"		grandmasterPriority2                  128",
+				"		grandmasterIdentity                   507c6f.fffe.1fb1a8",
+				"</" + key + "-parent>",
+				"<" + key + "-port>",
+				"sending: GET PORT_DATA_SET",
+			}
+			lines = append(lines, portDataSet...)
+
+			return append(lines,
+				"</"+key+"-port>",
+				"<"+key+"-time-status-np>",
+				"sending: GET TIME_STATUS_NP",
+				"	507c6f.fffe.30fbe8-0 seq 0 RESPONSE MANAGEMENT TIME_STATUS_NP ",
+				"		master_offset              -2",
+				"		ingress_time               1686916187058400000",
+				"		cumulativeScaledRateOffset +0.000000000",
+				"		scaledLastGmPhaseChange    0",
+				"		gmTimeBaseIndicator        0",
+				"		lastGmPhaseChange          0x0000'0000000000000000.0000",
+				"		gmPresent                  true",
+				"		gmIdentity                 507c6f.fffe.1fb1a8",
+				"</"+key+"-time-status-np>",
+			)
+		}
+
+		getInstances := func(instances []*devices.PTP4lInstance, outputs ...[]string) *devices.PMCInstances {
+			expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
+			for _, instance := range instances {
+				expectedInput += pmcCommands(instance.Config)
+			}
+
+			lines := []string{"<date>", "1686916187.0584", "</date>"}
+			for _, output := range outputs {
+				lines = append(lines, output...)
+			}
+
+			response[expectedInput] = []byte(strings.Join(lines, "\n"))
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
+			Expect(err).NotTo(HaveOccurred())
+
+			pmcInstances, err := devices.GetPMCInstances(ctx, instances, devices.PMCClientBinary)
+			Expect(err).NotTo(HaveOccurred())
+
+			return pmcInstances
+		}
+
+		It("should parse each dataset and each port", func() {
+			pmcInstances := getInstances(
+				[]*devices.PTP4lInstance{devices.DefaultPTP4lInstance},
+				pmcOutput("PMC-ptp4l-0", append(portResponse("1", "SLAVE"), portResponse("2", "MASTER")...)),
+			)
+			Expect(pmcInstances.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(pmcInstances.Instances).To(HaveLen(1))
+
+			dataSets := pmcInstances.Instances[0]
+			Expect(dataSets.GMSettings.ClockClass).To(Equal(248))
+			Expect(dataSets.GMSettings.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+
+			Expect(dataSets.Current.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(dataSets.Current.StepsRemoved).To(Equal(1))
+			Expect(dataSets.Current.OffsetFromMaster).To(BeNumerically("==", -2))
+			Expect(dataSets.Current.MeanPathDelay).To(BeNumerically("==", 112))
+			Expect(dataSets.Parent.ParentPortIdentity).To(Equal("ec4670.fffe.0a7fe1-1"))
+			Expect(dataSets.Parent.GrandmasterIdentity).To(Equal("507c6f.fffe.1fb1a8"))
+			Expect(dataSets.Parent.GrandmasterClockClass).To(Equal(6))
+			Expect(dataSets.Parent.GrandmasterClockAccuracy).To(Equal("0x21"))
+			Expect(dataSets.Parent.GrandmasterPriority2).To(Equal(128))
+
+			Expect(dataSets.Ports).To(HaveLen(2))
+			Expect(dataSets.Ports[0].PortIdentity).To(Equal("507c6f.fffe.30fbe8-1"))
+			Expect(dataSets.Ports[0].PortState).To(Equal("SLAVE"))
+			Expect(dataSets.Ports[0].LogSyncInterval).To(Equal(-4))
+			Expect(dataSets.Ports[1].PortState).To(Equal("MASTER"))
+
+			Expect(dataSets.TimeStatus.MasterOffset).To(BeNumerically("==", -2))
+			Expect(dataSets.TimeStatus.IngressTime).To(BeNumerically("==", 1686916187058400000))
+			Expect(dataSets.TimeStatus.GMPresent).To(BeTrue())
+			Expect(dataSets.TimeStatus.GMIdentity).To(Equal("507c6f.fffe.1fb1a8"))
+
+			records, err := pmcInstances.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+
+			ids := make([]string, 0, len(records))
+			for _, record := range records {
+				ids = append(ids, record.ID)
+			}
+
+			Expect(ids).To(Equal([]string{
+				"phc/gm-settings", "phc/current-ds", "phc/parent-ds", "phc/port-ds", "phc/port-ds", "phc/time-status",
+			}))
+		})
+
+		It("should tag the records of each instance with its config and interfaces", func() {
+			upstream := &devices.PTP4lInstance{Config: "ptp4l.0.config", Interfaces: []string{"ens7f0"}}
+			downstream := &devices.PTP4lInstance{Config: "ptp4l.1.config", Interfaces: []string{"ens7f1", "ens8f0"}}
+
+			pmcInstances := getInstances(
+				[]*devices.PTP4lInstance{upstream, downstream},
+				pmcOutput("PMC-ptp4l-0", portResponse("1", "SLAVE")),
+				pmcOutput("PMC-ptp4l-1", append(portResponse("1", "MASTER"), portResponse("2", "MASTER")...)),
+			)
+			Expect(pmcInstances.Instances).To(HaveLen(2))
+			Expect(pmcInstances.Instances[1].Instance).To(Equal(downstream))
+			Expect(pmcInstances.Instances[1].Ports).To(HaveLen(2))
+
+			data, err := json.Marshal(pmcInstances.Instances[1].Ports[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`"instance":"ptp4l.1.config","interfaces":["ens7f1","ens8f0"]`))
+
+			data, err = json.Marshal(pmcInstances.Instances[0].GMSettings)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`"instance":"ptp4l.0.config","interfaces":["ens7f0"]`))
+
+			instanceLabels := make(map[string]bool)
+			for _, sample := range pmcInstances.GetMetrics() {
+				instanceLabels[sample.Labels["instance"]] = true
+			}
+
+			Expect(instanceLabels).To(Equal(map[string]bool{"ptp4l.0.config": true, "ptp4l.1.config": true}))
+		})
+
+		It("should leave out a dataset which ptp4l did not answer", func() {
+			instance := &devices.PTP4lInstance{Config: "ptp4l.2.config"}
+			pmcInstances := getInstances([]*devices.PTP4lInstance{instance}, pmcOutput("PMC-ptp4l-2", []string{}))
+			Expect(pmcInstances.Instances[0].Ports).To(BeEmpty())
+			Expect(pmcInstances.Instances[0].Current).NotTo(BeNil())
+		})
+
+		It("should report port state changes and large offsets", func() {
+			slave := &devices.PMCDataSets{
+				GMSettings: &devices.PMCInfo{},
+				Current:    &devices.PMCCurrentDataSet{OffsetFromMaster: 5},
+				Ports:      []*devices.PMCPortDataSet{{PortIdentity: "a-1", PortState: "SLAVE"}},
+			}
+			listening := &devices.PMCDataSets{
+				GMSettings: &devices.PMCInfo{},
+				Current:    &devices.PMCCurrentDataSet{OffsetFromMaster: 5},
+				Ports:      []*devices.PMCPortDataSet{{PortIdentity: "a-1", PortState: "LISTENING"}},
+			}
+			drifting := &devices.PMCDataSets{
+				GMSettings: &devices.PMCInfo{},
+				Current:    &devices.PMCCurrentDataSet{OffsetFromMaster: -500},
+			}
+
+			Expect(slave.IsActive(nil, 100)).To(BeFalse())
+			Expect(slave.IsActive(slave, 100)).To(BeFalse())
+			Expect(listening.IsActive(slave, 100)).To(BeTrue())
+			Expect(drifting.IsActive(nil, 100)).To(BeTrue())
+		})
+	})
+
+	When("called GetPMCInstances with the native client", func() {
+		It("should decode the JSON printed by the pmc command of this tool", func() {
+			nativeResponses := []struct{ key, dataSet, output string }{
+				{"PMC-ptp4l-0", "GRANDMASTER_SETTINGS_NP", `{"managementId":"GRANDMASTER_SETTINGS_NP",` +
+					`"portIdentity":"507c6f.fffe.30fbe8-0","data":{"clockClass":6,"clockAccuracy":33,` +
+					`"offsetScaledLogVariance":20061,"currentUtcOffset":37,"leap61":false,"leap59":false,` +
+					`"currentUtcOffsetValid":true,"ptpTimescale":true,"timeTraceable":true,"frequencyTraceable":true,` +
+					`"timeSource":32}}`},
+				{"PMC-ptp4l-0-current", "CURRENT_DATA_SET", `{"managementId":"CURRENT_DATA_SET",` +
+					`"portIdentity":"507c6f.fffe.30fbe8-0",` +
+					`"data":{"offsetFromMaster":-2.5,"meanPathDelay":112,"stepsRemoved":1}}`},
+				{"PMC-ptp4l-0-parent", "PARENT_DATA_SET", `{"managementId":"PARENT_DATA_SET",` +
+					`"portIdentity":"507c6f.fffe.30fbe8-0","data":{"parentPortIdentity":"ec4670.fffe.0a7fe1-1",` +
+					`"grandmasterIdentity":"507c6f.fffe.1fb1a8","grandmasterClockQuality":{"clockClass":6,` +
+					`"clockAccuracy":33,"offsetScaledLogVariance":20061},"grandmasterPriority1":128,` +
+					`"grandmasterPriority2":128}}`},
+				{"PMC-ptp4l-0-port", "PORT_DATA_SET", `{"managementId":"PORT_DATA_SET",` +
+					`"portIdentity":"507c6f.fffe.30fbe8-1","data":{"portIdentity":"507c6f.fffe.30fbe8-1",` +
+					`"portState":"SLAVE","logSyncInterval":-4,"delayMechanism":1,"versionNumber":2}}` + "\n" +
+					`{"managementId":"PORT_DATA_SET","portIdentity":"507c6f.fffe.30fbe8-2",` +
+					`"data":{"portIdentity":"507c6f.fffe.30fbe8-2","portState":"MASTER"}}`},
+				{"PMC-ptp4l-0-time-status-np", "TIME_STATUS_NP", ""},
+			}
+
+			expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
+			expectedOutput := "<date>\n1686916187.0584\n</date>\n"
+
+			for _, nativeResponse := range nativeResponses {
+				expectedInput += "echo '<" + nativeResponse.key + ">';" + devices.SuggestedHelperPath +
+					" pmc -f /var/run/ptp4l.0.config 'GET " + nativeResponse.dataSet + "';echo '</" + nativeResponse.key + ">';"
+				expectedOutput += "<" + nativeResponse.key + ">\n" + nativeResponse.output + "\n</" + nativeResponse.key + ">\n"
+			}
+
+			response[expectedInput] = []byte(expectedOutput)
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
+			Expect(err).NotTo(HaveOccurred())
+
+			pmcInstances, err := devices.GetPMCInstances(
+				ctx,
+				[]*devices.PTP4lInstance{devices.DefaultPTP4lInstance},
+				devices.NewPMCClientNative(devices.SuggestedHelperPath),
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pmcInstances.Instances).To(HaveLen(1))
+
+			dataSets := pmcInstances.Instances[0]
+			Expect(dataSets.GMSettings.ClockClass).To(Equal(6))
+			Expect(dataSets.GMSettings.ClockAccuracy).To(Equal("0x21"))
+			Expect(dataSets.GMSettings.OffsetScaledLogVariance).To(Equal("0x4e5d"))
+			Expect(dataSets.GMSettings.TimeSource).To(Equal("0x20"))
+			Expect(dataSets.GMSettings.CurrentUtcOffsetValid).To(Equal(1))
+			Expect(dataSets.GMSettings.Leap61).To(Equal(0))
+			Expect(dataSets.GMSettings.Config).To(Equal("ptp4l.0.config"))
+
+			Expect(dataSets.Current.OffsetFromMaster).To(BeNumerically("==", -2.5))
+			Expect(dataSets.Current.StepsRemoved).To(Equal(1))
+			Expect(dataSets.Current.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(dataSets.Parent.GrandmasterClockAccuracy).To(Equal("0x21"))
+			Expect(dataSets.Parent.GrandmasterClockClass).To(Equal(6))
+			Expect(dataSets.Ports).To(HaveLen(2))
+			Expect(dataSets.Ports[0].PortState).To(Equal("SLAVE"))
+			Expect(dataSets.Ports[0].LogSyncInterval).To(Equal(-4))
+			Expect(dataSets.Ports[1].PortState).To(Equal("MASTER"))
+			Expect(dataSets.TimeStatus).To(BeNil())
+		})
+	})
+
+	When("called DiscoverPTP4lInstances", func() {
+		It("should return each config with its interfaces", func() {
+			expectedInput := "echo '<ptp4l-instances>';for f in /var/run/ptp4l.*.config; do [ -e \"$f\" ] && " +
+				"echo \"$f $(grep -o '^\\[[^]]*\\]' \"$f\" | tr -d '[]' | tr '\\n' ' ')\"; " +
+				"done;echo '</ptp4l-instances>';"
+			response[expectedInput] = []byte(strings.Join([]string{
+				"<ptp4l-instances>",
+				"/var/run/ptp4l.0.config global ens7f0 nmea ",
+				"/var/run/ptp4l.1.config global unicast_master_table ens7f1 ens8f0 ",
+				"</ptp4l-instances>",
+			}, "\n"))
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer", "TestNodeName")
+			Expect(err).NotTo(HaveOccurred())
+
+			instances, err := devices.DiscoverPTP4lInstances(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instances).To(Equal([]*devices.PTP4lInstance{
+				{Config: "ptp4l.0.config", Interfaces: []string{"ens7f0"}},
+				{Config: "ptp4l.1.config", Interfaces: []string{"ens7f1", "ens8f0"}},
+			}))
+		})
+	})
 })
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+)
+
+const (
+	ptp4lConfigDir = "/var/run/"
+
+	// listPTP4lInstancesCommand prints each ptp4l config the daemon has written followed by the sections in it
+	listPTP4lInstancesCommand = `for f in ` + ptp4lConfigDir + `ptp4l.*.config; do [ -e "$f" ] && ` +
+		`echo "$f $(grep -o '^\[[^]]*\]' "$f" | tr -d '[]' | tr '\n' ' ')"; done`
+)
+
+// ptp4lNonInterfaceSections are the sections of a linuxptp config which are not named after an interface
+var ptp4lNonInterfaceSections = map[string]bool{
+	"global":               true,
+	"nmea":                 true,
+	"unicast_master_table": true,
+}
+
+// PTP4lInstance is a ptp4l process started by the linuxptp daemon, named after its config
+type PTP4lInstance struct {
+	Config     string   `json:"instance,omitempty"`
+	Interfaces []string `json:"interfaces,omitempty"`
+}
+
+// DefaultPTP4lInstance is the instance of the daemon's first profile
+var DefaultPTP4lInstance = &PTP4lInstance{Config: path.Base(ptp4lConfigPath)}
+
+// ConfigPath returns where the instance's config is in the linuxptp daemon container
+func (instance *PTP4lInstance) ConfigPath() string {
+	return ptp4lConfigDir + instance.Config
+}
+
+// fetcherKey returns a key for the instance's commands, such as PMC-ptp4l-0 for ptp4l.0.config
+func (instance *PTP4lInstance) fetcherKey() string {
+	return "PMC-" + strings.ReplaceAll(strings.TrimSuffix(instance.Config, ".config"), ".", "-")
+}
+
+// parsePTP4lInstances parses the output of listPTP4lInstancesCommand
+func parsePTP4lInstances(output string) []*PTP4lInstance {
+	instances := make([]*PTP4lInstance, 0)
+
+	for line := range strings.SplitSeq(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		interfaces := make([]string, 0, len(fields)-1)
+
+		for _, section := range fields[1:] {
+			if !ptp4lNonInterfaceSections[section] {
+				interfaces = append(interfaces, section)
+			}
+		}
+
+		instances = append(instances, &PTP4lInstance{
+			Config:     path.Base(fields[0]),
+			Interfaces: interfaces,
+		})
+	}
+
+	return instances
+}
+
+// DiscoverPTP4lInstances returns every ptp4l instance from the configs the linuxptp daemon has written for its profiles
+func DiscoverPTP4lInstances(ctx clients.ExecContext) ([]*PTP4lInstance, error) {
+	fetcherInst, err := fetcher.FetcherFactory(
+		[]*clients.Cmd{},
+		[]fetcher.AddCommandArgs{
+			{
+				Key:     "ptp4l-instances",
+				Command: listPTP4lInstancesCommand,
+				Trim:    true,
+			},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build fetcher to discover the ptp4l instances %w", err)
+	}
+
+	type Instances struct {
+		Instances string `fetcherKey:"ptp4l-instances"`
+	}
+
+	found := Instances{}
+
+	err = fetcherInst.Fetch(ctx, &found)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the ptp4l instances %w", err)
+	}
+
+	return parsePTP4lInstances(found.Instances), nil
+}
@@ -9,12 +9,14 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
 type DPLLFilesystemCollector struct {
 	*baseCollector
 
 	ctx           clients.ExecContext
+	layout        *devices.DPLLFilesystemLayout
 	interfaceName string
 }
 
@@ -26,7 +28,7 @@ const (
 // polls for the dpll info then passes it to the callback
 func dpllFSPoller(dpll *DPLLFilesystemCollector) func() (callbacks.OutputType, error) {
 	return func() (callbacks.OutputType, error) {
-		return devices.GetDevDPLLFilesystemInfo(dpll.ctx, dpll.interfaceName) //nolint:wrapcheck //no point wrapping this
+		return devices.GetDevDPLLFilesystemInfo(dpll.ctx, dpll.interfaceName, dpll.layout) //nolint:wrapcheck //no point wrapping this
 	}
 }
 
@@ -37,14 +39,25 @@ func NewDPLLFilesystemCollector(constructor *CollectionConstructor) (Collector,
 		return &DPLLFilesystemCollector{}, fmt.Errorf("failed to create DPLLFilesystemCollector: %w", err)
 	}
 
-	err = devices.BuildFilesystemDPLLInfoFetcher(constructor.PTPInterface)
+	layout, err := devices.DetectDPLLFilesystemLayout(ctx, constructor.PTPInterface)
+	if err != nil {
+		return &DPLLFilesystemCollector{}, fmt.Errorf("failed to detect the DPLL filesystem layout %w", err)
+	}
+
+	if layout == nil {
+		return &DPLLFilesystemCollector{}, utils.NewRequirementsNotMetError(
+			fmt.Errorf("%s does not expose its DPLLs in a known filesystem layout", constructor.PTPInterface),
+		)
+	}
+
+	err = devices.BuildFilesystemDPLLInfoFetcher(constructor.PTPInterface, layout)
 	if err != nil {
 		return &DPLLFilesystemCollector{}, fmt.Errorf("failed to build fetcher for DPLLInfo %w", err)
 	}
 
 	collector := &DPLLFilesystemCollector{
 		baseCollector: newBaseCollector(
-			constructor.PollInterval,
+			constructor.GetPollInterval(DPLLCollectorName),
 			false,
 			constructor.Callback,
 			DPLLFilesystemCollectorName,
@@ -52,8 +65,11 @@ func NewDPLLFilesystemCollector(constructor *CollectionConstructor) (Collector,
 		),
 		interfaceName: constructor.PTPInterface,
 		ctx:           ctx,
+		layout:        layout,
 	}
 	collector.poller = dpllFSPoller(collector)
+	collector.enableAdaptivePolling(constructor.Adaptive)
+	collector.boundSampleLatency(constructor.SampleLatency)
 
 	return collector, nil
 }
@@ -11,16 +11,24 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/detect"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
 type DPLLNetlinkCollector struct {
 	*baseCollector
 
-	ctx               *clients.ContainerCreationExecContext
+	ctx               clients.ExecContext
+	debugPod          clients.PodExecContext
+	clientset         *clients.Clientset
 	interfaceName     string
+	nodeName          string
+	clockType         string
+	helperPath        string
+	cards             []devices.NetlinkParameters
 	params            devices.NetlinkParameters
 	unmanagedDebugPod bool
+	allCards          bool
 }
 
 const (
@@ -32,15 +40,17 @@ const (
 func (dpll *DPLLNetlinkCollector) Start() error {
 	dpll.running = true
 
-	err := dpll.ctx.CreatePodAndWait()
-	if err != nil {
-		return fmt.Errorf("dpll netlink collector failed to start pod: %w", err)
+	if dpll.debugPod != nil {
+		err := dpll.debugPod.CreatePodAndWait()
+		if err != nil {
+			return fmt.Errorf("dpll netlink collector failed to start pod: %w", err)
+		}
 	}
 
 	log.Debug("dpll.interfaceName: ", dpll.interfaceName)
 	log.Debug("dpll.ctx: ", dpll.ctx)
 
-	netlinkParams, err := devices.GetNetlinkParameters(dpll.ctx, dpll.interfaceName)
+	netlinkParams, err := devices.GetNetlinkParameters(dpll.ctx, dpll.interfaceName, dpll.helperPath)
 	if err != nil {
 		return fmt.Errorf("dpll netlink collector failed to find clock id: %w", err)
 	}
@@ -53,13 +63,90 @@ func (dpll *DPLLNetlinkCollector) Start() error {
 	}
 
 	dpll.params = netlinkParams
+	dpll.cards = []devices.NetlinkParameters{netlinkParams}
+
+	if dpll.allCards {
+		dpll.addDetectedCards()
+	}
 
 	return nil
 }
 
+// addDetectedCards adds the clock of every other card detect finds so they are collected alongside
+// the card of the interface, a card which can not be collected is skipped rather than failing the collector
+func (dpll *DPLLNetlinkCollector) addDetectedCards() {
+	daemonCtx, err := contexts.GetPTPDaemonContext(dpll.clientset, dpll.nodeName)
+	if err != nil {
+		log.Warnf("only collecting the DPLL of %s as failed to detect the other cards: %s", dpll.interfaceName, err.Error())
+		return
+	}
+
+	interfaces, err := detect.DetectInterfaces(daemonCtx, dpll.clockType)
+	if err != nil {
+		log.Warnf("failed to detect some of the cards: %s", err.Error())
+	}
+
+	seenClockIDs := map[uint64]bool{dpll.params.ClockID: true}
+
+	for _, iface := range interfaces {
+		netlinkParams, err := devices.GetNetlinkParameters(dpll.ctx, iface.Name, dpll.helperPath)
+		if err != nil {
+			log.Warnf("not collecting the DPLL of %s: %s", iface.Name, err.Error())
+			continue
+		}
+
+		if seenClockIDs[netlinkParams.ClockID] {
+			continue
+		}
+
+		seenClockIDs[netlinkParams.ClockID] = true
+		dpll.cards = append(dpll.cards, netlinkParams)
+		log.Infof("collecting the DPLL of %s with clock ID %d", iface.Name, netlinkParams.ClockID)
+	}
+}
+
+// getNetlinkContext returns where the DPLL netlink requests are made and the helper path they are made with.
+// When this tool can be run from --helper-path they are made in the PTP daemon container, otherwise they are made
+// with ynl in the netlink debug pod which is also returned so that the collector can create and delete it.
+func getNetlinkContext(
+	constructor *CollectionConstructor,
+) (ctx clients.ExecContext, debugPod clients.PodExecContext, helperPath string, err error) {
+	if constructor.HelperPath != "" {
+		ctx, err = contexts.GetPTPDaemonContext(constructor.Clientset, constructor.PTPNodeName)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("failed to create the PTP daemon context: %w", err)
+		}
+
+		present, helperErr := devices.EnsureHelper(ctx, constructor.HelperPath)
+		if present {
+			return ctx, nil, constructor.HelperPath, nil
+		}
+
+		if helperErr != nil {
+			log.Warnf("falling back to ynl in the netlink debug pod for the DPLL netlink requests as %s", helperErr.Error())
+		}
+	}
+
+	debugPod, err = contexts.GetNetlinkContext(
+		constructor.Clientset,
+		constructor.PTPNodeName,
+		constructor.UnmanagedDebugPod,
+		constructor.DebugPodPerNode,
+	)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to create the netlink debug pod context: %w", err)
+	}
+
+	return debugPod, debugPod, "", nil
+}
+
 // polls for the dpll info then passes it to the callback
 func dpllNetlinkPoller(dpll *DPLLNetlinkCollector) func() (callbacks.OutputType, error) {
 	return func() (callbacks.OutputType, error) {
+		if len(dpll.cards) > 1 {
+			return devices.GetDevDPLLNetlinkCards(dpll.ctx, dpll.cards) //nolint:wrapcheck //no point wrapping this
+		}
+
 		return devices.GetDevDPLLNetlinkInfo(dpll.ctx, dpll.params) //nolint:wrapcheck //no point wrapping this
 	}
 }
@@ -86,7 +173,11 @@ func (dpll *DPLLNetlinkCollector) Poll(resultsChan chan PollResult, wg *utils.Wa
 func (dpll *DPLLNetlinkCollector) CleanUp() error {
 	dpll.running = false
 
-	err := dpll.ctx.DeletePodAndWait()
+	if dpll.debugPod == nil {
+		return nil
+	}
+
+	err := dpll.debugPod.DeletePodAndWait()
 	if err != nil {
 		return fmt.Errorf("dpll netlink collector failed to clean up: %w", err)
 	}
@@ -96,18 +187,14 @@ func (dpll *DPLLNetlinkCollector) CleanUp() error {
 
 // Returns a new DPLLNetlinkCollector from the CollectionConstuctor Factory
 func NewDPLLNetlinkCollector(constructor *CollectionConstructor) (Collector, error) {
-	ctx, err := contexts.GetNetlinkContext(
-		constructor.Clientset,
-		constructor.PTPNodeName,
-		constructor.UnmanagedDebugPod,
-	)
+	ctx, debugPod, helperPath, err := getNetlinkContext(constructor)
 	if err != nil {
 		return &DPLLNetlinkCollector{}, fmt.Errorf("failed to create DPLLNetlinkCollector: %w", err)
 	}
 
 	collector := &DPLLNetlinkCollector{
 		baseCollector: newBaseCollector(
-			constructor.PollInterval,
+			constructor.GetPollInterval(DPLLCollectorName),
 			false,
 			constructor.Callback,
 			DPLLNetlinkCollectorName,
@@ -115,9 +202,17 @@ func NewDPLLNetlinkCollector(constructor *CollectionConstructor) (Collector, err
 		),
 		interfaceName:     constructor.PTPInterface,
 		ctx:               ctx,
+		debugPod:          debugPod,
+		clientset:         constructor.Clientset,
+		nodeName:          constructor.PTPNodeName,
+		clockType:         constructor.ClockType,
+		helperPath:        helperPath,
 		unmanagedDebugPod: constructor.UnmanagedDebugPod,
+		allCards:          constructor.DPLLAllCards,
 	}
 	collector.poller = dpllNetlinkPoller(collector)
+	collector.enableAdaptivePolling(constructor.Adaptive)
+	collector.boundSampleLatency(constructor.SampleLatency)
 
 	err = collector.Start()
 	if err != nil {
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	DPLLEventsCollectorName = "DPLL-Events"
+	dpllEventsKey           = "dpll-events"
+)
+
+var errDPLLMonitorExited = errors.New("dpll monitor exited")
+
+// DPLLEventsCollector streams the DPLL netlink device and pin notifications and writes each one as it arrives,
+// so transitions shorter than the DPLL collector's poll interval are not missed.
+type DPLLEventsCollector struct {
+	*streamCollector
+}
+
+func parseDPLLEventLine(line []byte) (callbacks.OutputType, error) {
+	return devices.ParseDPLLEvent(line) //nolint:wrapcheck // the error says which notification failed
+}
+
+// Returns a new DPLLEventsCollector from the CollectionConstuctor Factory
+func NewDPLLEventsCollector(constructor *CollectionConstructor) (Collector, error) {
+	if constructor.Clientset == nil {
+		return &DPLLEventsCollector{}, utils.NewRequirementsNotMetError(
+			errors.New("dpll events collector requires a connection to the cluster"),
+		)
+	}
+
+	ctx, err := contexts.GetPTPDaemonStreamContext(constructor.Clientset, constructor.PTPNodeName)
+	if err != nil {
+		return &DPLLEventsCollector{}, fmt.Errorf("failed to create DPLLEventsCollector: %w", err)
+	}
+
+	// The monitor is this tool's dpll command so it is only run when the user has agreed to it being in the container
+	if constructor.HelperPath == "" {
+		return &DPLLEventsCollector{}, utils.NewRequirementsNotMetError(
+			errors.New("dpll events collector requires --helper-path to run this tool as its monitor"),
+		)
+	}
+
+	execCtx, err := contexts.GetPTPDaemonContext(constructor.Clientset, constructor.PTPNodeName)
+	if err != nil {
+		return &DPLLEventsCollector{}, fmt.Errorf("failed to create DPLLEventsCollector: %w", err)
+	}
+
+	present, err := devices.EnsureHelper(execCtx, constructor.HelperPath)
+	if !present {
+		if err == nil {
+			err = fmt.Errorf("%s is not in the %s container", constructor.HelperPath, contexts.PTPContainer)
+		}
+
+		return &DPLLEventsCollector{}, utils.NewRequirementsNotMetError(
+			fmt.Errorf("dpll events collector requires this tool as its monitor: %w", err),
+		)
+	}
+
+	collector := &DPLLEventsCollector{
+		streamCollector: newStreamCollector(
+			newBaseCollector(
+				constructor.GetPollInterval(DPLLEventsCollectorName),
+				false,
+				constructor.Callback,
+				DPLLEventsCollectorName,
+				dpllEventsKey,
+			),
+			ctx,
+			devices.DPLLEventStreamCommand(constructor.HelperPath),
+			parseDPLLEventLine,
+			errDPLLMonitorExited,
+		),
+	}
+
+	return collector, nil
+}
+
+func init() {
+	RegisterCollector(DPLLEventsCollectorName, NewDPLLEventsCollector, optional)
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors
+
+import (
+	"fmt"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	DPLLPinsCollectorName = "DPLL-Pins"
+	DPLLPinsInfo          = "dpll-pins"
+)
+
+// DPLLPinsCollector records the pin table of every DPLL device on the node
+type DPLLPinsCollector struct {
+	*baseCollector
+
+	ctx        clients.ExecContext
+	debugPod   clients.PodExecContext
+	helperPath string
+}
+
+// Start sets up the collector so it is ready to be polled
+func (dpll *DPLLPinsCollector) Start() error {
+	dpll.running = true
+
+	if dpll.debugPod != nil {
+		err := dpll.debugPod.CreatePodAndWait()
+		if err != nil {
+			return fmt.Errorf("dpll pins collector failed to start pod: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// polls for the dpll pins then passes them to the callback
+func dpllPinsPoller(dpll *DPLLPinsCollector) func() (callbacks.OutputType, error) {
+	return func() (callbacks.OutputType, error) {
+		return devices.GetDPLLPins(dpll.ctx, dpll.helperPath) //nolint:wrapcheck //no point wrapping this
+	}
+}
+
+// Poll collects information from the cluster then
+// calls the callback.Call to allow that to persist it
+func (dpll *DPLLPinsCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	defer wg.Done()
+
+	errorsToReturn := make([]error, 0)
+
+	err := dpll.poll()
+	if err != nil {
+		errorsToReturn = append(errorsToReturn, err)
+	}
+
+	resultsChan <- PollResult{
+		CollectorName: DPLLPinsCollectorName,
+		Errors:        errorsToReturn,
+	}
+}
+
+// CleanUp stops a running collector
+func (dpll *DPLLPinsCollector) CleanUp() error {
+	dpll.running = false
+
+	if dpll.debugPod == nil {
+		return nil
+	}
+
+	err := dpll.debugPod.DeletePodAndWait()
+	if err != nil {
+		return fmt.Errorf("dpll pins collector failed to clean up: %w", err)
+	}
+
+	return nil
+}
+
+// Returns a new DPLLPinsCollector from the CollectionConstuctor Factory
+func NewDPLLPinsCollector(constructor *CollectionConstructor) (Collector, error) {
+	ctx, debugPod, helperPath, err := getNetlinkContext(constructor)
+	if err != nil {
+		return &DPLLPinsCollector{}, fmt.Errorf("failed to create DPLLPinsCollector: %w", err)
+	}
+
+	collector := &DPLLPinsCollector{
+		baseCollector: newBaseCollector(
+			constructor.GetPollInterval(DPLLPinsCollectorName),
+			false,
+			constructor.Callback,
+			DPLLPinsCollectorName,
+			DPLLPinsInfo,
+		),
+		ctx:        ctx,
+		debugPod:   debugPod,
+		helperPath: helperPath,
+	}
+	collector.poller = dpllPinsPoller(collector)
+
+	err = collector.Start()
+	if err != nil {
+		collector.CleanUp()
+	}
+
+	return collector, err
+}
+
+func init() {
+	RegisterCollector(DPLLPinsCollectorName, NewDPLLPinsCollector, optional)
+}
@@ -4,6 +4,9 @@ package collectors //nolint:dupl // new collector
 
 import (
 	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
@@ -16,16 +19,106 @@ var (
 	gpsNavKey        = "gpsNav"
 )
 
+// How the GNSS collector reads the receiver
+const (
+	GNSSDecoderAuto   = "auto"
+	GNSSDecoderBinary = "binary"
+	GNSSDecoderText   = "text"
+)
+
 type GPSCollector struct {
 	*baseCollector
 
-	ctx           clients.ExecContext
-	interfaceName string
+	ctx              clients.ExecContext
+	receiver         devices.GNSSReceiver
+	interfaceName    string
+	decoder          string
+	decoderMu        sync.Mutex
+	receiverMu       sync.Mutex
+	assumingReceiver bool
+}
+
+// getDecoder returns the decoder, which polls running at the same time may be settling
+func (gps *GPSCollector) getDecoder() string {
+	gps.decoderMu.Lock()
+	defer gps.decoderMu.Unlock()
+
+	return gps.decoder
+}
+
+// settleDecoder switches the auto decoder to decoder. Only the first poll to finish settles it,
+// so a poll which started before a failed capture can not switch the collector back to binary.
+func (gps *GPSCollector) settleDecoder(decoder string) {
+	gps.decoderMu.Lock()
+	defer gps.decoderMu.Unlock()
+
+	if gps.decoder == GNSSDecoderAuto {
+		gps.decoder = decoder
+	}
 }
 
+// gnssReceiver returns the receiver on the node, finding it on first use. While it can not be found
+// the default receiver is used and it is looked for again on the next poll.
+func (gps *GPSCollector) gnssReceiver() (devices.GNSSReceiver, error) {
+	gps.receiverMu.Lock()
+	defer gps.receiverMu.Unlock()
+
+	if gps.receiver != nil {
+		return gps.receiver, nil
+	}
+
+	receiver, err := devices.DetectGNSSReceiver(gps.ctx)
+	if err == nil {
+		gps.receiver = receiver
+		return receiver, nil
+	}
+
+	if !gps.assumingReceiver {
+		log.Warnf("assuming a %s receiver using protocol version %s until it is detected: %s",
+			devices.UBloxVendor, devices.DefaultUBXProtocolVersion, err.Error())
+		gps.assumingReceiver = true
+	}
+
+	receiver, err = devices.DefaultGNSSReceiver()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GNSS receiver %w", err)
+	}
+
+	return receiver, nil
+}
+
+// gpsNavPoller decodes a raw capture unless the text decoder was chosen or the receiver can not be captured.
+// With the auto decoder a failure to decode the first capture, for example because
+// the ubxtool on the node can not save raw data, switches to the text decoder for good.
 func gpsNavPoller(gps *GPSCollector) func() (callbacks.OutputType, error) {
 	return func() (callbacks.OutputType, error) {
-		return devices.GetGPSNav(gps.ctx) //nolint:wrapcheck //no point wrapping this
+		receiver, err := gps.gnssReceiver()
+		if err != nil {
+			return nil, err
+		}
+
+		decoder := gps.getDecoder()
+
+		rawReceiver, canCapture := receiver.(devices.RawGNSSReceiver)
+		if decoder == GNSSDecoderText || !canCapture {
+			return receiver.GetNav(gps.ctx) //nolint:wrapcheck //no point wrapping this
+		}
+
+		gpsNav, err := rawReceiver.GetNavBinary(gps.ctx)
+		if decoder != GNSSDecoderAuto {
+			return gpsNav, err //nolint:wrapcheck //no point wrapping this
+		}
+
+		if err != nil {
+			log.Warnf("failed to decode the raw UBX capture, falling back to ubxtool's text output: %s", err.Error())
+			gps.settleDecoder(GNSSDecoderText)
+
+			return receiver.GetNav(gps.ctx) //nolint:wrapcheck //no point wrapping this
+		}
+
+		gps.settleDecoder(GNSSDecoderBinary)
+
+		return gpsNav, nil
 	}
 }
 
@@ -36,9 +129,14 @@ func NewGPSCollector(constructor *CollectionConstructor) (Collector, error) {
 		return &GPSCollector{}, fmt.Errorf("failed to create DPLLCollector: %w", err)
 	}
 
+	decoder := constructor.GNSSDecoder
+	if decoder == "" {
+		decoder = GNSSDecoderAuto
+	}
+
 	collector := &GPSCollector{
 		baseCollector: newBaseCollector(
-			constructor.PollInterval,
+			constructor.GetPollInterval(GPSCollectorName),
 			false,
 			constructor.Callback,
 			GPSCollectorName,
@@ -46,8 +144,11 @@ func NewGPSCollector(constructor *CollectionConstructor) (Collector, error) {
 		),
 		ctx:           ctx,
 		interfaceName: constructor.PTPInterface,
+		decoder:       decoder,
 	}
 	collector.poller = gpsNavPoller(collector)
+	collector.enableAdaptivePolling(constructor.Adaptive)
+	collector.boundSampleLatency(constructor.SampleLatency)
 
 	return collector, nil
 }
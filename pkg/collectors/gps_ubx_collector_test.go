@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors //nolint:testpackage // testing the poller of an unexported collector
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+// fakeRawReceiver counts the reads of each decoder, the first binary read waits for release
+// when it is set so that it finishes after the reads which start after it
+type fakeRawReceiver struct {
+	release     chan struct{}
+	binaryErr   error
+	binaryReads atomic.Int32
+	textReads   atomic.Int32
+}
+
+func (receiver *fakeRawReceiver) Vendor() string {
+	return devices.UBloxVendor
+}
+
+func (receiver *fakeRawReceiver) GetVersions(_ clients.ExecContext) (*devices.GPSVersions, error) {
+	return &devices.GPSVersions{}, nil
+}
+
+func (receiver *fakeRawReceiver) GetNav(_ clients.ExecContext) (*devices.GPSDetails, error) {
+	receiver.textReads.Add(1)
+	return &devices.GPSDetails{}, nil
+}
+
+func (receiver *fakeRawReceiver) GetNavBinary(_ clients.ExecContext) (*devices.GPSDetails, error) {
+	if receiver.binaryReads.Add(1) == 1 && receiver.release != nil {
+		<-receiver.release
+		return &devices.GPSDetails{}, nil
+	}
+
+	return &devices.GPSDetails{}, receiver.binaryErr
+}
+
+// runningPolls is the number of polls of a collector the runner can have running at once
+const runningPolls = 3
+
+var _ = Describe("gpsNavPoller", func() {
+	var receiver *fakeRawReceiver
+	var gps *GPSCollector
+
+	BeforeEach(func() {
+		receiver = &fakeRawReceiver{binaryErr: errors.New("ubxtool can not save raw data")}
+		gps = &GPSCollector{receiver: receiver, decoder: GNSSDecoderAuto}
+	})
+
+	pollConcurrently := func(polls int) []error {
+		poller := gpsNavPoller(gps)
+		errs := make([]error, polls)
+
+		var wg sync.WaitGroup
+		for i := range polls {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = poller()
+			}()
+		}
+		wg.Wait()
+
+		return errs
+	}
+
+	When("concurrent polls fail to decode the raw capture", func() {
+		It("should switch the auto decoder to text", func() {
+			for _, err := range pollConcurrently(runningPolls) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(gps.getDecoder()).To(Equal(GNSSDecoderText))
+			Expect(receiver.textReads.Load()).To(Equal(int32(runningPolls)))
+		})
+	})
+
+	When("a poll decodes the raw capture after another failed to", func() {
+		It("should stay on the text decoder", func() {
+			receiver.release = make(chan struct{})
+			poller := gpsNavPoller(gps)
+
+			done := make(chan error)
+			go func() {
+				_, err := poller()
+				done <- err
+			}()
+			Eventually(receiver.binaryReads.Load).Should(Equal(int32(1)))
+
+			_, err := poller()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gps.getDecoder()).To(Equal(GNSSDecoderText))
+
+			close(receiver.release)
+			Expect(<-done).NotTo(HaveOccurred())
+			Expect(gps.getDecoder()).To(Equal(GNSSDecoderText))
+		})
+	})
+})
+
+func TestCollectors(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Collectors Suite")
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	GPSDCollectorName = "GPSD"
+	gpsdKey           = "gpsd"
+)
+
+var errGPSPipeExited = errors.New("gpspipe exited")
+
+// GPSDCollector streams the JSON reports of gpsd from gpspipe and writes each TPV, SKY, PPS and TOFF report
+// as it arrives. Unlike the GNSS collector it does not depend on the receiver being a u-blox module.
+type GPSDCollector struct {
+	*streamCollector
+}
+
+// Returns a new GPSDCollector from the CollectionConstuctor Factory
+func NewGPSDCollector(constructor *CollectionConstructor) (Collector, error) {
+	if constructor.Clientset == nil {
+		return &GPSDCollector{}, utils.NewRequirementsNotMetError(
+			errors.New("gpsd collector requires a connection to the cluster"),
+		)
+	}
+
+	ctx, err := contexts.GetGPSDContext(constructor.Clientset, constructor.PTPNodeName)
+	if err != nil {
+		return &GPSDCollector{}, fmt.Errorf("failed to create GPSDCollector: %w", err)
+	}
+
+	collector := &GPSDCollector{
+		streamCollector: newStreamCollector(
+			newBaseCollector(
+				constructor.GetPollInterval(GPSDCollectorName),
+				false,
+				constructor.Callback,
+				GPSDCollectorName,
+				gpsdKey,
+			),
+			ctx,
+			devices.GPSDStreamCommand,
+			devices.ParseGPSDReport,
+			errGPSPipeExited,
+		),
+	}
+
+	return collector, nil
+}
+
+func init() {
+	RegisterCollector(GPSDCollectorName, NewGPSDCollector, optional)
+}
@@ -5,6 +5,7 @@ package collectors
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -74,6 +75,7 @@ type LogsCollector struct {
 const (
 	LogsCollectorName = "Logs"
 	LogsInfo          = "log-line"
+	linuxPTPLogKey    = "linuxptp-log"
 )
 
 func (logs *LogsCollector) SetLastPoll(pollTime time.Time) {
@@ -104,6 +106,20 @@ func (logs *LogsCollector) writeLine(line *loglines.ProcessedLine, writer io.Str
 	}
 }
 
+// emitLinuxPTPRecord passes the offset or summary of a ptp4l, phc2sys or ts2phc line on to the callback
+// so they are in the same output as the other collectors
+func (logs *LogsCollector) emitLinuxPTPRecord(line *loglines.ProcessedLine) {
+	record, ok := loglines.ParseLinuxPTPLine(line)
+	if !ok {
+		return
+	}
+
+	err := logs.callback.Call(record, linuxPTPLogKey)
+	if err != nil {
+		log.Errorf("callback failed for linuxptp log record: %s", err.Error())
+	}
+}
+
 //nolint:cyclop // allow this to be a little complicated
 func (logs *LogsCollector) processSlices() {
 	logs.wg.Add(1)
@@ -162,11 +178,13 @@ func (logs *LogsCollector) writeToLogFile() {
 			for len(logs.lines) > 0 {
 				line := <-logs.lines
 				logs.writeLine(line, fileHandle)
+				logs.emitLinuxPTPRecord(line)
 			}
 
 			return
 		case line := <-logs.lines:
 			logs.writeLine(line, fileHandle)
+			logs.emitLinuxPTPRecord(line)
 		default:
 			time.Sleep(time.Nanosecond)
 		}
@@ -296,6 +314,18 @@ func (logs *LogsCollector) CleanUp() error {
 
 // Returns a new LogsCollector from the CollectionConstuctor Factory
 func NewLogsCollector(constructor *CollectionConstructor) (Collector, error) {
+	if constructor.Clientset == nil {
+		return &LogsCollector{}, utils.NewRequirementsNotMetError(
+			errors.New("logs collector requires a connection to the cluster"),
+		)
+	}
+
+	if constructor.LogsOutputFile == "" {
+		return &LogsCollector{}, utils.NewRequirementsNotMetError(
+			fmt.Errorf("logs collector for node %s has no logs output file", constructor.PTPNodeName),
+		)
+	}
+
 	collector := LogsCollector{
 		baseCollector: newBaseCollector(
 			logPollInterval,
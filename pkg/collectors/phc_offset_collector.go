@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/detect"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	PHCOffsetCollectorName = "PHC-Offset"
+	PHCOffsetInfo          = "phc-offset"
+)
+
+// PHCOffsetCollector measures the PHC of every card against CLOCK_REALTIME and against the PHC of the primary card
+type PHCOffsetCollector struct {
+	*baseCollector
+
+	ctx    clients.ExecContext
+	clocks []devices.PHCClock
+}
+
+func phcOffsetPoller(phc *PHCOffsetCollector) func() (callbacks.OutputType, error) {
+	return func() (callbacks.OutputType, error) {
+		return devices.GetPHCOffsets(phc.ctx, phc.clocks) //nolint:wrapcheck //no point wrapping this
+	}
+}
+
+// Poll collects information from the cluster then
+// calls the callback.Call to allow that to persist it
+func (phc *PHCOffsetCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	defer wg.Done()
+
+	errorsToReturn := make([]error, 0)
+
+	err := phc.poll()
+	if err != nil {
+		errorsToReturn = append(errorsToReturn, err)
+	}
+
+	resultsChan <- PollResult{
+		CollectorName: PHCOffsetCollectorName,
+		Errors:        errorsToReturn,
+	}
+}
+
+// Returns a new PHCOffsetCollector based on values in the CollectionConstructor
+func NewPHCOffsetCollector(constructor *CollectionConstructor) (Collector, error) {
+	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset, constructor.PTPNodeName)
+	if err != nil {
+		return &PHCOffsetCollector{}, fmt.Errorf("failed to create PHCOffsetCollector: %w", err)
+	}
+
+	interfaces, err := detect.DetectInterfaces(ctx, constructor.ClockType)
+	if err != nil {
+		log.Warnf("failed to detect some of the cards: %s", err.Error())
+	}
+
+	// detect puts the primary card first so it is the leader the other PHCs are compared with
+	clocks := make([]devices.PHCClock, 0, len(interfaces))
+
+	for _, iface := range interfaces {
+		if iface.PTPClockDevicePath == "" {
+			log.Warnf("not measuring the PHC of %s as it has no PTP clock device", iface.Name)
+			continue
+		}
+
+		clocks = append(clocks, devices.PHCClock{Interface: iface.Name, Device: iface.PTPClockDevicePath})
+		log.Infof("measuring %s of %s", iface.PTPClockDevicePath, iface.Name)
+	}
+
+	if len(clocks) == 0 {
+		return &PHCOffsetCollector{}, utils.NewRequirementsNotMetError(
+			errors.New("phc offset collector found no PTP clock devices"),
+		)
+	}
+
+	collector := &PHCOffsetCollector{
+		baseCollector: newBaseCollector(
+			constructor.GetPollInterval(PHCOffsetCollectorName),
+			false,
+			constructor.Callback,
+			PHCOffsetCollectorName,
+			PHCOffsetInfo,
+		),
+		ctx:    ctx,
+		clocks: clocks,
+	}
+	collector.poller = phcOffsetPoller(collector)
+	collector.enableAdaptivePolling(constructor.Adaptive)
+	collector.boundSampleLatency(constructor.SampleLatency)
+
+	return collector, nil
+}
+
+func init() {
+	RegisterCollector(PHCOffsetCollectorName, NewPHCOffsetCollector, optional)
+}
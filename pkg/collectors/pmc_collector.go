@@ -5,6 +5,8 @@ package collectors //nolint:dupl // new collector
 import (
 	"fmt"
 
+	log "github.com/sirupsen/logrus"
+
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
@@ -20,12 +22,14 @@ const (
 type PMCCollector struct {
 	*baseCollector
 
-	ctx clients.ExecContext
+	ctx       clients.ExecContext
+	instances []*devices.PTP4lInstance
+	client    devices.PMCClient
 }
 
 func pmcPoller(pmc *PMCCollector) func() (callbacks.OutputType, error) {
 	return func() (callbacks.OutputType, error) {
-		return devices.GetPMC(pmc.ctx) //nolint:wrapcheck //no point wrapping this
+		return devices.GetPMCInstances(pmc.ctx, pmc.instances, pmc.client) //nolint:wrapcheck //no point wrapping this
 	}
 }
 
@@ -54,17 +58,45 @@ func NewPMCCollector(constructor *CollectionConstructor) (Collector, error) {
 		return &PMCCollector{}, fmt.Errorf("failed to create PMCCollector: %w", err)
 	}
 
+	instances, err := devices.DiscoverPTP4lInstances(ctx)
+	if err != nil {
+		log.Warnf("failed to discover the ptp4l instances: %s", err.Error())
+	}
+
+	if len(instances) == 0 {
+		log.Warnf("found no ptp4l instances so only querying %s", devices.DefaultPTP4lInstance.Config)
+		instances = []*devices.PTP4lInstance{devices.DefaultPTP4lInstance}
+	}
+
+	// parse pmc's output unless this tool has been given with --helper-path to talk to ptp4l from Go
+	client := devices.NewPMCClientNative(constructor.HelperPath)
+
+	helperPresent, err := devices.EnsureHelper(ctx, constructor.HelperPath)
+	if !helperPresent {
+		if err != nil {
+			log.Warnf("falling back to pmc as %s", err.Error())
+		}
+
+		client = devices.PMCClientBinary
+	}
+
+	log.Infof("using the %s PMC client", client)
+
 	collector := &PMCCollector{
 		baseCollector: newBaseCollector(
-			constructor.PollInterval,
+			constructor.GetPollInterval(PMCCollectorName),
 			false,
 			constructor.Callback,
 			PMCCollectorName,
 			PMCInfo,
 		),
-		ctx: ctx,
+		ctx:       ctx,
+		instances: instances,
+		client:    client,
 	}
 	collector.poller = pmcPoller(collector)
+	collector.enableAdaptivePolling(constructor.Adaptive)
+	collector.boundSampleLatency(constructor.SampleLatency)
 
 	return collector, nil
 }
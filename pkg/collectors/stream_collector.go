@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	streamErrorChanLength = 100
+	streamMaxLineSize     = 1024 * 1024
+)
+
+// streamLineParser returns the output for a line of a streamed command, or nil if the line should be skipped
+type streamLineParser func(line []byte) (callbacks.OutputType, error)
+
+// streamCollector runs a command which prints a line for each record and writes each one as it arrives.
+// Polling does not collect anything, it reports the errors seen on the stream since the last poll.
+type streamCollector struct {
+	*baseCollector
+
+	ctx       clients.StreamContext
+	exitedErr error
+	cancel    context.CancelFunc
+	errors    chan error
+	parseLine streamLineParser
+	command   []string
+	wg        sync.WaitGroup
+}
+
+func newStreamCollector(
+	base *baseCollector,
+	ctx clients.StreamContext,
+	command []string,
+	parseLine streamLineParser,
+	exitedErr error,
+) *streamCollector {
+	return &streamCollector{
+		baseCollector: base,
+		ctx:           ctx,
+		command:       command,
+		parseLine:     parseLine,
+		exitedErr:     exitedErr,
+		errors:        make(chan error, streamErrorChanLength),
+	}
+}
+
+// Start begins streaming
+func (stream *streamCollector) Start() error {
+	streamCtx, cancel := context.WithCancel(context.Background())
+	stream.cancel = cancel
+	stream.running = true
+
+	stream.wg.Add(1)
+
+	go stream.stream(streamCtx)
+
+	return nil
+}
+
+// stream runs the command until the collector is cleaned up, restarting it after a poll interval if it exits
+func (stream *streamCollector) stream(ctx context.Context) {
+	defer stream.wg.Done()
+
+	for {
+		err := stream.streamOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			err = stream.exitedErr
+		}
+
+		stream.reportError(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(stream.pollInterval):
+		}
+	}
+}
+
+func (stream *streamCollector) streamOnce(ctx context.Context) error {
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	go func() {
+		writer.CloseWithError(stream.ctx.StreamCommand(ctx, stream.command, writer))
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), streamMaxLineSize)
+
+	for scanner.Scan() {
+		output, err := stream.parseLine(scanner.Bytes())
+		if err != nil {
+			stream.reportError(err)
+			continue
+		}
+
+		if output == nil {
+			continue
+		}
+
+		err = stream.callback.Call(output, stream.callbackTag)
+		if err != nil {
+			stream.reportError(fmt.Errorf("callback failed %w", err))
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return fmt.Errorf("failed to stream %s %w", stream.callbackTag, err)
+	}
+
+	return nil
+}
+
+// reportError keeps err to be returned by the next poll
+func (stream *streamCollector) reportError(err error) {
+	select {
+	case stream.errors <- err:
+	default:
+		log.Warnf("dropping %s error as too many are waiting to be reported: %s", stream.name, err.Error())
+	}
+}
+
+// Poll returns the errors seen on the stream since the last poll
+func (stream *streamCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	defer wg.Done()
+
+	errorsToReturn := make([]error, 0)
+
+	// Overlapping polls share the errors so each takes what is waiting without blocking
+	for draining := true; draining; {
+		select {
+		case err := <-stream.errors:
+			errorsToReturn = append(errorsToReturn, err)
+		default:
+			draining = false
+		}
+	}
+
+	resultsChan <- PollResult{
+		CollectorName: stream.name,
+		Errors:        errorsToReturn,
+	}
+}
+
+// CleanUp stops the stream
+func (stream *streamCollector) CleanUp() error {
+	if stream.cancel != nil {
+		stream.cancel()
+	}
+
+	stream.wg.Wait()
+	stream.running = false
+
+	return nil
+}
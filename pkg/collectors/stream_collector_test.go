@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors //nolint:testpackage // testing the polls of the unexported stream collector
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+var _ = Describe("streamCollector", func() {
+	When("polls overlap", func() {
+		It("should report each error once and not block", func() {
+			stream := newStreamCollector(
+				&baseCollector{name: "test"}, nil, []string{}, nil, errors.New("exited"),
+			)
+
+			const reported = 10
+			for i := range reported {
+				stream.reportError(fmt.Errorf("error %d", i))
+			}
+
+			results := make(chan PollResult, runningPolls)
+			polls := utils.WaitGroupCount{}
+
+			for range runningPolls {
+				polls.Add(1)
+				go stream.Poll(results, &polls)
+			}
+
+			Eventually(polls.GetCount).Should(BeZero())
+
+			received := 0
+			for range runningPolls {
+				received += len((<-results).Errors)
+			}
+			Expect(received).To(Equal(reported))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/constants"
+)
+
+// Target is a node and PTP interface to run a set of collectors against
+type Target struct {
+	NodeName  string
+	Interface string
+	ClockType string
+}
+
+// ParseTarget parses a target in the form "<node>:<interface>[:<clock type>]",
+// the clock type defaults to GM
+func ParseTarget(value string) (Target, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" { //nolint:mnd // node, interface and clock type
+		return Target{}, fmt.Errorf("target %s must be in the form <node>:<interface>[:<clock type>]", value)
+	}
+
+	target := Target{
+		NodeName:  parts[0],
+		Interface: parts[1],
+		ClockType: constants.ClockTypeGM,
+	}
+
+	if len(parts) == 3 { //nolint:mnd // node, interface and clock type
+		target.ClockType = strings.ToUpper(parts[2])
+	}
+
+	if target.ClockType != constants.ClockTypeGM && target.ClockType != constants.ClockTypeBC {
+		return Target{}, fmt.Errorf(
+			"target %s has invalid clock type %s, must be either %s or %s",
+			value, parts[2], constants.ClockTypeGM, constants.ClockTypeBC,
+		)
+	}
+
+	return target, nil
+}
+
+func (target Target) String() string {
+	return target.NodeName + "/" + target.Interface
+}
+
+// ForTargets returns a constructor for each target, each of which tags its output with
+// the node and interface it was collected from. If no targets were given the
+// constructor is returned as is.
+func (constructor *CollectionConstructor) ForTargets() []*CollectionConstructor {
+	if len(constructor.Targets) == 0 {
+		return []*CollectionConstructor{constructor}
+	}
+
+	constructors := make([]*CollectionConstructor, 0, len(constructor.Targets))
+	seenNodes := make(map[string]bool)
+	// Each node needs its own debug pod only when collecting from more than one node
+	debugPodPerNode := slices.ContainsFunc(constructor.Targets, func(target Target) bool {
+		return target.NodeName != constructor.Targets[0].NodeName
+	})
+
+	for _, target := range constructor.Targets {
+		targetConstructor := *constructor
+		targetConstructor.Targets = nil
+		targetConstructor.Target = &target
+		targetConstructor.PTPNodeName = target.NodeName
+		targetConstructor.PTPInterface = target.Interface
+		targetConstructor.ClockType = target.ClockType
+		targetConstructor.DebugPodPerNode = debugPodPerNode
+		targetConstructor.Callback = callbacks.NewTargetedCallback(constructor.Callback, target.NodeName, target.Interface)
+
+		// Logs are collected per node so only collect them once for each node
+		if seenNodes[target.NodeName] || constructor.LogsOutputFile == "" {
+			targetConstructor.LogsOutputFile = ""
+		} else {
+			targetConstructor.LogsOutputFile = constructor.LogsOutputFile + "." + target.NodeName
+		}
+
+		seenNodes[target.NodeName] = true
+
+		constructors = append(constructors, &targetConstructor)
+	}
+
+	return constructors
+}
+
+// InstanceName returns the name of a collector within this constructors target
+func (constructor *CollectionConstructor) InstanceName(collectorName string) string {
+	if constructor.Target == nil {
+		return collectorName
+	}
+
+	return collectorName + "@" + constructor.Target.String()
+}
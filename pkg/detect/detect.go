@@ -63,15 +63,28 @@ func sortAndDeduplicateInterfaces(interfaces []DetectedInterface) []DetectedInte
 }
 
 func Detect(kubeConfig, ptpNodeName string, outputAsJSON bool, clockType string) {
-	clientset, err := clients.GetClientset(kubeConfig)
-	utils.IfErrorExitOrPanic(err)
+	var clientset *clients.Clientset
+
+	if !clients.IsReplaying() {
+		var err error
+
+		clientset, err = clients.GetClientset(kubeConfig)
+		utils.IfErrorExitOrPanic(err)
+	}
+
 	ctx, err := contexts.GetPTPDaemonContext(clientset, ptpNodeName)
 	utils.IfErrorExitOrPanic(err)
-	interfaces, err := checkPTPConfig(ctx, clockType)
+	interfaces, err := DetectInterfaces(ctx, clockType)
 	utils.IfErrorExitOrPanic(err)
 	output(os.Stdout, interfaces, outputAsJSON)
 }
 
+// DetectInterfaces returns the PTP interfaces found in the linuxptp daemon's configs,
+// with one interface per PTP clock device and the primary interface first
+func DetectInterfaces(ctx clients.ExecContext, clockType string) ([]DetectedInterface, error) {
+	return checkPTPConfig(ctx, clockType)
+}
+
 func output(outWriter io.Writer, interfaces []DetectedInterface, outputAsJSON bool) {
 	if outputAsJSON {
 		out, err := json.MarshalIndent(interfaces, "", "  ")
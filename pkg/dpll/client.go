@@ -0,0 +1,319 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package dpll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrReceiveTimeout is returned by Conn.Receive when nothing arrived before its timeout
+var ErrReceiveTimeout = errors.New("timed out waiting for a netlink message")
+
+// Conn is a generic netlink socket
+type Conn interface {
+	// Send writes a single request to the kernel
+	Send(data []byte) error
+	// Receive returns the next datagram from the kernel and the kernel's timestamp of it,
+	// which is zero if it is not known, or ErrReceiveTimeout
+	Receive() ([]byte, time.Time, error)
+	// JoinGroup subscribes to a multicast group
+	JoinGroup(groupID uint32) error
+	Close() error
+}
+
+// Client makes DPLL requests over a Conn
+type Client struct {
+	conn     Conn
+	groups   map[string]uint32
+	mu       sync.Mutex
+	sequence uint32
+	familyID uint16
+}
+
+// NewClient returns a Client which has looked up the DPLL family over conn
+func NewClient(conn Conn) (*Client, error) {
+	client := &Client{conn: conn, groups: make(map[string]uint32)}
+
+	err := client.resolveFamily()
+	if err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Close closes the underlying Conn
+func (client *Client) Close() error {
+	return client.conn.Close() //nolint:wrapcheck // the error is returned as is to the caller
+}
+
+// request sends a message and returns the replies to it, up to the end of a dump or the ack
+func (client *Client) request(familyID uint16, cmd, version uint8, flags uint16, attrs []byte) ([]*Message, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	// a dump ends with NLMSG_DONE, anything else is acked
+	if flags&flagDump == 0 {
+		flags |= flagAck
+	}
+
+	client.sequence++
+	header := &Header{
+		Type:     familyID,
+		Flags:    flagRequest | flags,
+		Sequence: client.sequence,
+		Command:  cmd,
+		Version:  version,
+	}
+
+	err := client.conn.Send(EncodeMessage(header, attrs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", CommandName(cmd), err)
+	}
+
+	replies := make([]*Message, 0)
+
+	for {
+		data, _, err := client.conn.Receive()
+		if err != nil {
+			return replies, fmt.Errorf("failed to receive reply: %w", err)
+		}
+
+		msgs, err := ParseMessages(data)
+		if err != nil {
+			return replies, err
+		}
+
+		for _, msg := range msgs {
+			if msg.Header.Sequence != header.Sequence {
+				// a reply to an earlier request which was abandoned
+				continue
+			}
+
+			switch {
+			case msg.IsError() && msg.Error != 0:
+				return replies, fmt.Errorf("request failed: %w", msg.Error)
+			case msg.IsError():
+				return replies, nil
+			case msg.IsDone() && msg.Error != 0:
+				return replies, fmt.Errorf("dump failed: %w", msg.Error)
+			case msg.IsDone():
+				return replies, nil
+			default:
+				replies = append(replies, msg)
+			}
+		}
+	}
+}
+
+func (client *Client) resolveFamily() error {
+	encoder := &AttributeEncoder{}
+	encoder.String(ctrlAttrFamilyName, FamilyName)
+
+	replies, err := client.request(ctrlFamilyID, ctrlCmdGetFamily, 1, 0, encoder.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to find the %s netlink family: %w", FamilyName, err)
+	}
+
+	if len(replies) == 0 {
+		return fmt.Errorf("no reply when looking up the %s netlink family", FamilyName)
+	}
+
+	attrs, err := ParseAttributes(replies[0].Attributes)
+	if err != nil {
+		return fmt.Errorf("failed to parse the %s netlink family: %w", FamilyName, err)
+	}
+
+	for _, attr := range attrs {
+		switch attr.Type {
+		case ctrlAttrFamilyID:
+			client.familyID, err = attr.Uint16()
+			if err != nil {
+				return err
+			}
+		case ctrlAttrMcastGroups:
+			err = client.parseGroups(attr)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if client.familyID == 0 {
+		return fmt.Errorf("the %s netlink family has no ID", FamilyName)
+	}
+
+	return nil
+}
+
+func (client *Client) parseGroups(groupsAttr *Attribute) error {
+	groups, err := ParseAttributes(groupsAttr.Data)
+	if err != nil {
+		return fmt.Errorf("failed to parse multicast groups: %w", err)
+	}
+
+	for _, group := range groups {
+		attrs, err := ParseAttributes(group.Data)
+		if err != nil {
+			return fmt.Errorf("failed to parse multicast group: %w", err)
+		}
+
+		var (
+			name string
+			id   uint32
+		)
+
+		for _, attr := range attrs {
+			switch attr.Type {
+			case ctrlAttrMcastGrpName:
+				name = attr.String()
+			case ctrlAttrMcastGrpID:
+				id, err = attr.Uint32()
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		client.groups[name] = id
+	}
+
+	return nil
+}
+
+func (client *Client) dpllRequest(cmd uint8, flags uint16, attrs []byte) ([]*Message, error) {
+	return client.request(client.familyID, cmd, FamilyVersion, flags, attrs)
+}
+
+func idAttribute(attrType uint16, id uint32) []byte {
+	encoder := &AttributeEncoder{}
+	encoder.Uint32(attrType, id)
+
+	return encoder.Bytes()
+}
+
+// GetDevice returns the DPLL device with id
+func (client *Client) GetDevice(id uint32) (*Device, error) {
+	replies, err := client.dpllRequest(CmdDeviceGet, 0, idAttribute(attrID, id))
+	if err != nil {
+		return nil, fmt.Errorf("device-get %d: %w", id, err)
+	}
+
+	if len(replies) == 0 {
+		return nil, fmt.Errorf("device-get %d: no reply", id)
+	}
+
+	return DecodeDevice(replies[0].Attributes)
+}
+
+// DumpDevices returns every DPLL device
+func (client *Client) DumpDevices() ([]*Device, error) {
+	replies, err := client.dpllRequest(CmdDeviceGet, flagDump, nil)
+	if err != nil {
+		return nil, fmt.Errorf("device-get dump: %w", err)
+	}
+
+	devices := make([]*Device, 0, len(replies))
+
+	for _, reply := range replies {
+		device, err := DecodeDevice(reply.Attributes)
+		if err != nil {
+			return devices, err
+		}
+
+		devices = append(devices, device)
+	}
+
+	return devices, nil
+}
+
+// GetPin returns the DPLL pin with id
+func (client *Client) GetPin(id uint32) (*Pin, error) {
+	replies, err := client.dpllRequest(CmdPinGet, 0, idAttribute(attrPinID, id))
+	if err != nil {
+		return nil, fmt.Errorf("pin-get %d: %w", id, err)
+	}
+
+	if len(replies) == 0 {
+		return nil, fmt.Errorf("pin-get %d: no reply", id)
+	}
+
+	return DecodePin(replies[0].Attributes)
+}
+
+// DumpPins returns every DPLL pin
+func (client *Client) DumpPins() ([]*Pin, error) {
+	replies, err := client.dpllRequest(CmdPinGet, flagDump, nil)
+	if err != nil {
+		return nil, fmt.Errorf("pin-get dump: %w", err)
+	}
+
+	pins := make([]*Pin, 0, len(replies))
+
+	for _, reply := range replies {
+		pin, err := DecodePin(reply.Attributes)
+		if err != nil {
+			return pins, err
+		}
+
+		pins = append(pins, pin)
+	}
+
+	return pins, nil
+}
+
+// Monitor subscribes to the monitor group and passes each notification to handler
+// until ctx is done or the Conn fails. Notifications carry the kernel's timestamp of the
+// datagram they arrived in, or the time they were read if the Conn does not provide one.
+func (client *Client) Monitor(ctx context.Context, handler func(*Notification)) error {
+	groupID, ok := client.groups[MonitorGroup]
+	if !ok {
+		return fmt.Errorf("the %s netlink family has no %s group", FamilyName, MonitorGroup)
+	}
+
+	err := client.conn.JoinGroup(groupID)
+	if err != nil {
+		return fmt.Errorf("failed to join the %s group: %w", MonitorGroup, err)
+	}
+
+	for ctx.Err() == nil {
+		data, timestamp, err := client.conn.Receive()
+		if errors.Is(err, ErrReceiveTimeout) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to receive notification: %w", err)
+		}
+
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+
+		msgs, err := ParseMessages(data)
+		if err != nil {
+			return err
+		}
+
+		for _, msg := range msgs {
+			if msg.IsError() || msg.IsDone() {
+				continue
+			}
+
+			notification, err := DecodeNotification(msg)
+			if err != nil {
+				return err
+			}
+
+			notification.Timestamp = timestamp
+
+			handler(notification)
+		}
+	}
+
+	return nil
+}
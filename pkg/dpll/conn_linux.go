@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+//go:build linux
+
+package dpll
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+)
+
+const (
+	solNetlink           = 270
+	netlinkAddMembership = 1
+
+	// receiveBufferSize is large enough for any single dump datagram
+	receiveBufferSize = 64 * 1024
+	receiveTimeout    = time.Second
+	// controlBufferSize is large enough for the SCM_TIMESTAMPNS control message
+	controlBufferSize = 64
+)
+
+// socketConn is a generic netlink socket
+type socketConn struct {
+	buffer  []byte
+	control []byte
+	fd      int
+}
+
+// Dial opens a generic netlink socket and returns a Client for the DPLL family on it
+func Dial() (*Client, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_GENERIC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open netlink socket: %w", err)
+	}
+
+	conn := &socketConn{fd: fd, buffer: make([]byte, receiveBufferSize), control: make([]byte, controlBufferSize)}
+
+	err = syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to bind netlink socket: %w", err)
+	}
+
+	timeout := syscall.NsecToTimeval(receiveTimeout.Nanoseconds())
+
+	err = syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &timeout)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set netlink receive timeout: %w", err)
+	}
+
+	// ask the kernel to timestamp each datagram so a notification's time does not include how long it waited to be read
+	err = syscall.SetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_TIMESTAMPNS, 1)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to enable netlink timestamps: %w", err)
+	}
+
+	client, err := NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (conn *socketConn) Send(data []byte) error {
+	return syscall.Sendto(conn.fd, data, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}) //nolint:wrapcheck // wrapped by the client
+}
+
+func (conn *socketConn) Receive() ([]byte, time.Time, error) {
+	n, controlLength, _, _, err := syscall.Recvmsg(conn.fd, conn.buffer, conn.control, 0)
+	if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) {
+		return nil, time.Time{}, ErrReceiveTimeout
+	}
+
+	if err != nil {
+		return nil, time.Time{}, err //nolint:wrapcheck // wrapped by the client
+	}
+
+	data := make([]byte, n)
+	copy(data, conn.buffer[:n])
+
+	return data, parseTimestamp(conn.control[:controlLength]), nil
+}
+
+// parseTimestamp returns the time from an SCM_TIMESTAMPNS control message, or the zero time if there is none
+func parseTimestamp(control []byte) time.Time {
+	msgs, err := syscall.ParseSocketControlMessage(control)
+	if err != nil {
+		return time.Time{}
+	}
+
+	for _, msg := range msgs {
+		if msg.Header.Level != syscall.SOL_SOCKET || msg.Header.Type != syscall.SCM_TIMESTAMPNS {
+			continue
+		}
+
+		// a struct timespec of the native word size
+		switch len(msg.Data) {
+		case 16: //nolint:mnd // two 64 bit words
+			return time.Unix(int64(byteOrder.Uint64(msg.Data)), int64(byteOrder.Uint64(msg.Data[8:]))) //nolint:gosec // a timespec
+		case 8: //nolint:mnd // two 32 bit words
+			return time.Unix(int64(int32(byteOrder.Uint32(msg.Data))), int64(byteOrder.Uint32(msg.Data[4:]))) //nolint:gosec // a timespec
+		}
+	}
+
+	return time.Time{}
+}
+
+func (conn *socketConn) JoinGroup(groupID uint32) error {
+	return syscall.SetsockoptInt(conn.fd, solNetlink, netlinkAddMembership, int(groupID)) //nolint:wrapcheck // wrapped by the client
+}
+
+func (conn *socketConn) Close() error {
+	return syscall.Close(conn.fd) //nolint:wrapcheck // the error is returned as is to the caller
+}
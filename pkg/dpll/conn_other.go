@@ -0,0 +1,12 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+//go:build !linux
+
+package dpll
+
+import "errors"
+
+// Dial is only supported on Linux where the DPLL netlink family exists
+func Dial() (*Client, error) {
+	return nil, errors.New("DPLL netlink is only available on Linux")
+}
@@ -0,0 +1,230 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package dpll_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/dpll"
+)
+
+const clockID = uint64(5799633565435100136)
+
+var queuedAt = time.Date(2023, 6, 16, 11, 49, 47, 58400000, time.UTC)
+
+// fakeConn replays datagrams laid out as the kernel sends them and keeps the requests it is sent
+type fakeConn struct {
+	sent      [][]byte
+	datagrams [][]byte
+	groups    []uint32
+}
+
+func newFakeConn(fixtures ...string) *fakeConn {
+	conn := &fakeConn{}
+
+	for _, fixture := range append([]string{"ctrl_get_family.bin"}, fixtures...) {
+		data, err := os.ReadFile("test_files/" + fixture)
+		Expect(err).NotTo(HaveOccurred())
+
+		conn.datagrams = append(conn.datagrams, data)
+	}
+
+	return conn
+}
+
+func (conn *fakeConn) Send(data []byte) error {
+	conn.sent = append(conn.sent, data)
+	return nil
+}
+
+func (conn *fakeConn) Receive() ([]byte, time.Time, error) {
+	if len(conn.datagrams) == 0 {
+		return nil, time.Time{}, io.EOF
+	}
+
+	data := conn.datagrams[0]
+	conn.datagrams = conn.datagrams[1:]
+
+	return data, queuedAt.Add(time.Duration(len(conn.datagrams)) * time.Millisecond), nil
+}
+
+func (conn *fakeConn) JoinGroup(groupID uint32) error {
+	conn.groups = append(conn.groups, groupID)
+	return nil
+}
+
+func (conn *fakeConn) Close() error {
+	return nil
+}
+
+func newClient(conn *fakeConn) *dpll.Client {
+	client, err := dpll.NewClient(conn)
+	Expect(err).NotTo(HaveOccurred())
+
+	return client
+}
+
+var _ = Describe("Attributes", func() {
+	It("should encode padded attributes and parse them back", func() {
+		encoder := &dpll.AttributeEncoder{}
+		encoder.String(2, "dpll")
+		encoder.Uint16(1, 0x25)
+		encoder.Uint32(3, 7)
+
+		data := encoder.Bytes()
+		Expect(data).To(HaveLen(12 + 8 + 8))
+
+		attrs, err := dpll.ParseAttributes(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attrs).To(HaveLen(3))
+		Expect(attrs[0].String()).To(Equal("dpll"))
+		Expect(attrs[1].Uint16()).To(BeNumerically("==", 0x25))
+		Expect(attrs[2].Uint32()).To(BeNumerically("==", 7))
+	})
+
+	It("should reject truncated attributes", func() {
+		_, err := dpll.ParseAttributes([]byte{0x10, 0x00, 0x01, 0x00, 0x01})
+		Expect(err).To(MatchError(dpll.ErrTruncatedAttribute))
+
+		_, err = dpll.ParseMessages([]byte{0x40, 0x00, 0x00, 0x00})
+		Expect(err).To(MatchError(dpll.ErrTruncatedMessage))
+	})
+})
+
+var _ = Describe("Client", func() {
+	It("should encode requests as the kernel expects and return the errno of a failure", func() {
+		conn := newFakeConn("pin_get_enodev.bin")
+		client := newClient(conn)
+
+		_, err := client.GetPin(3)
+		Expect(err).To(MatchError(syscall.ENODEV))
+
+		expected, err := os.ReadFile("test_files/pin_get_request.bin")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.sent).To(HaveLen(2))
+		Expect(conn.sent[1]).To(Equal(expected))
+	})
+
+	It("should dump the devices", func() {
+		devices, err := newClient(newFakeConn("device_dump.bin")).DumpDevices()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(devices).To(HaveLen(2))
+
+		Expect(devices[0].ID).To(BeNumerically("==", 0))
+		Expect(devices[0].ModuleName).To(Equal("ice"))
+		Expect(devices[0].ClockID).To(Equal(clockID))
+		Expect(devices[0].Mode).To(Equal("automatic"))
+		Expect(devices[0].ModeSupported).To(Equal([]string{"automatic"}))
+		Expect(devices[0].LockStatus).To(Equal("locked-ho-acq"))
+		Expect(devices[0].LockStatusError).To(Equal("none"))
+		Expect(devices[0].Type).To(Equal("eec"))
+		Expect(devices[0].ClockQualityLevel).To(Equal([]string{"itu-opt1-prtc"}))
+		Expect(devices[1].Type).To(Equal("pps"))
+	})
+
+	It("should dump the pins with their parents", func() {
+		pins, err := newClient(newFakeConn("pin_dump.bin")).DumpPins()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pins).To(HaveLen(2))
+
+		gnss := pins[0]
+		Expect(gnss.ID).To(BeNumerically("==", 3))
+		Expect(gnss.BoardLabel).To(Equal("GNSS-1PPS"))
+		Expect(gnss.Type).To(Equal("gnss"))
+		Expect(gnss.ClockID).To(Equal(clockID))
+		Expect(*gnss.Frequency).To(BeNumerically("==", 1))
+		Expect(gnss.FrequencySupported).To(Equal([]*dpll.FrequencyRange{{Min: 1, Max: 1}}))
+		Expect(gnss.Capabilities).To(Equal([]string{"priority-can-change", "state-can-change"}))
+		Expect(*gnss.PhaseAdjustMin).To(BeNumerically("==", -16723))
+		Expect(*gnss.PhaseAdjustMax).To(BeNumerically("==", 16723))
+		Expect(gnss.ParentDevice).To(HaveLen(2))
+		Expect(gnss.ParentDevice[0].ParentID).To(BeNumerically("==", 0))
+		Expect(gnss.ParentDevice[0].Direction).To(Equal("input"))
+		Expect(gnss.ParentDevice[0].State).To(Equal("connected"))
+		Expect(*gnss.ParentDevice[0].Prio).To(BeNumerically("==", 0))
+		Expect(gnss.ParentDevice[0].PhaseOffset).To(BeNumerically("==", -1234000))
+		Expect(gnss.ParentDevice[1].PhaseOffset).To(BeNumerically("==", 56000))
+
+		synce := pins[1]
+		Expect(synce.Type).To(Equal("synce-eth-port"))
+		Expect(synce.ParentDevice).To(BeEmpty())
+		Expect(synce.ParentPin).To(Equal([]*dpll.PinParentPin{{ParentID: 2, State: "connected"}}))
+	})
+
+	It("should write the same json as ynl", func() {
+		pins, err := newClient(newFakeConn("pin_dump.bin")).DumpPins()
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := json.Marshal(pins[1])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(MatchJSON(`{
+			"id": 13, "clock-id": 5799633565435100136, "module-name": "ice", "type": "synce-eth-port",
+			"capabilities": ["state-can-change"], "parent-pin": [{"parent-id": 2, "state": "connected"}]
+		}`))
+	})
+
+	It("should pass notifications from the monitor group to the handler", func() {
+		conn := newFakeConn("device_change_ntf.bin", "pin_change_ntf.bin")
+		notifications := make([]*dpll.Notification, 0)
+
+		err := newClient(conn).Monitor(context.Background(), func(notification *dpll.Notification) {
+			notifications = append(notifications, notification)
+		})
+		Expect(err).To(MatchError(io.EOF))
+		Expect(conn.groups).To(Equal([]uint32{9}))
+
+		Expect(notifications).To(HaveLen(2))
+		Expect(notifications[0].Name).To(Equal("device-change-ntf"))
+		Expect(notifications[0].Device.LockStatus).To(Equal("holdover"))
+		Expect(notifications[1].Name).To(Equal("pin-change-ntf"))
+		Expect(notifications[1].Pin.ParentDevice[0].State).To(Equal("disconnected"))
+		Expect(notifications[0].Timestamp).To(Equal(queuedAt.Add(time.Millisecond)))
+		Expect(notifications[1].Timestamp).To(Equal(queuedAt))
+	})
+})
+
+// pciConfig returns a PCIe config space with an AER capability followed by the device serial number capability
+func pciConfig(serialNumber uint64) []byte {
+	config := make([]byte, 4096)
+	binary.LittleEndian.PutUint32(config[0x100:], 0x150<<20|1<<16|0x0001)
+	binary.LittleEndian.PutUint32(config[0x150:], 1<<16|0x0003)
+	binary.LittleEndian.PutUint64(config[0x154:], serialNumber)
+
+	return config
+}
+
+var _ = Describe("SerialNumberFromConfig", func() {
+	It("should return the serial number as lspci shows it without the dashes", func() {
+		serialNumber, err := dpll.SerialNumberFromConfig(pciConfig(0x507c6fffff30fbe8))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(serialNumber).To(Equal("507c6fffff30fbe8"))
+	})
+
+	It("should fail when only the first 256 bytes of the config could be read", func() {
+		_, err := dpll.SerialNumberFromConfig(pciConfig(0x507c6fffff30fbe8)[:256])
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail when the capabilities loop without a serial number", func() {
+		config := make([]byte, 4096)
+		binary.LittleEndian.PutUint32(config[0x100:], 0x100<<20|1<<16|0x0001)
+
+		_, err := dpll.SerialNumberFromConfig(config)
+		Expect(err).To(MatchError(ContainSubstring("no serial number")))
+	})
+})
+
+func TestDPLL(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DPLL Netlink Suite")
+}
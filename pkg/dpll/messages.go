@@ -0,0 +1,311 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package dpll
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The json names are those used by the spec so the output matches ynl's
+
+// Device is a DPLL device as returned by device-get
+//
+//nolint:tagliatelle // the names are from the spec
+type Device struct {
+	ModuleName        string   `json:"module-name"`
+	Mode              string   `json:"mode,omitempty"`
+	LockStatus        string   `json:"lock-status,omitempty"`
+	LockStatusError   string   `json:"lock-status-error,omitempty"`
+	Type              string   `json:"type,omitempty"`
+	ModeSupported     []string `json:"mode-supported,omitempty"`
+	ClockQualityLevel []string `json:"clock-quality-level,omitempty"`
+	Temp              *int32   `json:"temp,omitempty"`
+	ClockID           uint64   `json:"clock-id"`
+	ID                uint32   `json:"id"`
+}
+
+// FrequencyRange is a range of frequencies supported by a pin
+//
+//nolint:tagliatelle // the names are from the spec
+type FrequencyRange struct {
+	Min uint64 `json:"frequency-min"`
+	Max uint64 `json:"frequency-max"`
+}
+
+// PinParentDevice is the relationship between a pin and a DPLL device
+//
+//nolint:tagliatelle // the names are from the spec
+type PinParentDevice struct {
+	Direction   string  `json:"direction,omitempty"`
+	State       string  `json:"state,omitempty"`
+	Prio        *uint32 `json:"prio,omitempty"`
+	PhaseOffset int64   `json:"phase-offset"`
+	ParentID    uint32  `json:"parent-id"`
+}
+
+// PinParentPin is the relationship between a pin and the MUX pin it is connected through
+//
+//nolint:tagliatelle // the names are from the spec
+type PinParentPin struct {
+	State    string `json:"state,omitempty"`
+	ParentID uint32 `json:"parent-id"`
+}
+
+// Pin is a DPLL pin as returned by pin-get
+//
+//nolint:tagliatelle // the names are from the spec
+type Pin struct {
+	ModuleName                string             `json:"module-name"`
+	BoardLabel                string             `json:"board-label,omitempty"`
+	PanelLabel                string             `json:"panel-label,omitempty"`
+	PackageLabel              string             `json:"package-label,omitempty"`
+	Type                      string             `json:"type,omitempty"`
+	Capabilities              []string           `json:"capabilities"`
+	FrequencySupported        []*FrequencyRange  `json:"frequency-supported,omitempty"`
+	ParentDevice              []*PinParentDevice `json:"parent-device,omitempty"`
+	ParentPin                 []*PinParentPin    `json:"parent-pin,omitempty"`
+	Frequency                 *uint64            `json:"frequency,omitempty"`
+	FractionalFrequencyOffset *int64             `json:"fractional-frequency-offset,omitempty"`
+	PhaseAdjust               *int32             `json:"phase-adjust,omitempty"`
+	PhaseAdjustMin            *int32             `json:"phase-adjust-min,omitempty"`
+	PhaseAdjustMax            *int32             `json:"phase-adjust-max,omitempty"`
+	ClockID                   uint64             `json:"clock-id"`
+	ID                        uint32             `json:"id"`
+}
+
+// Notification is a message from the monitor multicast group
+type Notification struct {
+	Timestamp time.Time `json:"timestamp"`
+	Device    *Device   `json:"-"`
+	Pin       *Pin      `json:"-"`
+	// Name is the name of the notification, such as pin-change-ntf
+	Name string `json:"name"`
+	// Msg is whichever of Device or Pin the notification carries
+	Msg any `json:"msg"`
+}
+
+// attributeDecoder collects the errors found while reading the values of attributes
+type attributeDecoder struct {
+	err error
+}
+
+func (decoder *attributeDecoder) uint32(attr *Attribute) uint32 {
+	value, err := attr.Uint32()
+	decoder.err = errors.Join(decoder.err, err)
+
+	return value
+}
+
+func (decoder *attributeDecoder) uint64(attr *Attribute) uint64 {
+	value, err := attr.Uint64()
+	decoder.err = errors.Join(decoder.err, err)
+
+	return value
+}
+
+func (decoder *attributeDecoder) int32(attr *Attribute) int32 {
+	value, err := attr.Int32()
+	decoder.err = errors.Join(decoder.err, err)
+
+	return value
+}
+
+func (decoder *attributeDecoder) int64(attr *Attribute) int64 {
+	value, err := attr.Int64()
+	decoder.err = errors.Join(decoder.err, err)
+
+	return value
+}
+
+func (decoder *attributeDecoder) nested(attr *Attribute) []*Attribute {
+	attrs, err := ParseAttributes(attr.Data)
+	decoder.err = errors.Join(decoder.err, err)
+
+	return attrs
+}
+
+// DecodeDevice returns the device described by the attributes of a device message
+func DecodeDevice(data []byte) (*Device, error) {
+	attrs, err := ParseAttributes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse device: %w", err)
+	}
+
+	device := &Device{}
+	decoder := &attributeDecoder{}
+
+	for _, attr := range attrs {
+		switch attr.Type {
+		case attrID:
+			device.ID = decoder.uint32(attr)
+		case attrModuleName:
+			device.ModuleName = attr.String()
+		case attrClockID:
+			device.ClockID = decoder.uint64(attr)
+		case attrMode:
+			device.Mode = enumName(modeNames, decoder.uint32(attr))
+		case attrModeSupported:
+			device.ModeSupported = append(device.ModeSupported, enumName(modeNames, decoder.uint32(attr)))
+		case attrLockStatus:
+			device.LockStatus = enumName(lockStatusNames, decoder.uint32(attr))
+		case attrLockStatusError:
+			device.LockStatusError = enumName(lockStatusErrorNames, decoder.uint32(attr))
+		case attrTemp:
+			temp := decoder.int32(attr)
+			device.Temp = &temp
+		case attrType:
+			device.Type = enumName(typeNames, decoder.uint32(attr))
+		case attrClockQualityLevel:
+			device.ClockQualityLevel = append(device.ClockQualityLevel,
+				enumName(clockQualityNames, decoder.uint32(attr)))
+		}
+	}
+
+	if decoder.err != nil {
+		return nil, fmt.Errorf("failed to decode device: %w", decoder.err)
+	}
+
+	return device, nil
+}
+
+func decodeParentDevice(decoder *attributeDecoder, attrs []*Attribute) *PinParentDevice {
+	parent := &PinParentDevice{}
+
+	for _, attr := range attrs {
+		switch attr.Type {
+		case attrPinParentID:
+			parent.ParentID = decoder.uint32(attr)
+		case attrPinDirection:
+			parent.Direction = enumName(pinDirectionNames, decoder.uint32(attr))
+		case attrPinPrio:
+			prio := decoder.uint32(attr)
+			parent.Prio = &prio
+		case attrPinState:
+			parent.State = enumName(pinStateNames, decoder.uint32(attr))
+		case attrPinPhaseOffset:
+			parent.PhaseOffset = decoder.int64(attr)
+		}
+	}
+
+	return parent
+}
+
+func decodeParentPin(decoder *attributeDecoder, attrs []*Attribute) *PinParentPin {
+	parent := &PinParentPin{}
+
+	for _, attr := range attrs {
+		switch attr.Type {
+		case attrPinParentID:
+			parent.ParentID = decoder.uint32(attr)
+		case attrPinState:
+			parent.State = enumName(pinStateNames, decoder.uint32(attr))
+		}
+	}
+
+	return parent
+}
+
+func decodeFrequencyRange(decoder *attributeDecoder, attrs []*Attribute) *FrequencyRange {
+	frequencies := &FrequencyRange{}
+
+	for _, attr := range attrs {
+		switch attr.Type {
+		case attrPinFrequencyMin:
+			frequencies.Min = decoder.uint64(attr)
+		case attrPinFrequencyMax:
+			frequencies.Max = decoder.uint64(attr)
+		}
+	}
+
+	return frequencies
+}
+
+// DecodePin returns the pin described by the attributes of a pin message
+func DecodePin(data []byte) (*Pin, error) { //nolint:funlen,cyclop // there is a case for each attribute
+	attrs, err := ParseAttributes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pin: %w", err)
+	}
+
+	pin := &Pin{Capabilities: make([]string, 0)}
+	decoder := &attributeDecoder{}
+
+	for _, attr := range attrs {
+		switch attr.Type {
+		case attrPinID:
+			pin.ID = decoder.uint32(attr)
+		case attrPinModuleName:
+			pin.ModuleName = attr.String()
+		case attrPinClockID:
+			pin.ClockID = decoder.uint64(attr)
+		case attrPinBoardLabel:
+			pin.BoardLabel = attr.String()
+		case attrPinPanelLabel:
+			pin.PanelLabel = attr.String()
+		case attrPinPackageLabel:
+			pin.PackageLabel = attr.String()
+		case attrPinType:
+			pin.Type = enumName(pinTypeNames, decoder.uint32(attr))
+		case attrPinFrequency:
+			frequency := decoder.uint64(attr)
+			pin.Frequency = &frequency
+		case attrPinFrequencySupported:
+			pin.FrequencySupported = append(pin.FrequencySupported,
+				decodeFrequencyRange(decoder, decoder.nested(attr)))
+		case attrPinCapabilities:
+			pin.Capabilities = flagNames(pinCapabilityNames, decoder.uint32(attr))
+		case attrPinParentDevice:
+			pin.ParentDevice = append(pin.ParentDevice, decodeParentDevice(decoder, decoder.nested(attr)))
+		case attrPinParentPin:
+			pin.ParentPin = append(pin.ParentPin, decodeParentPin(decoder, decoder.nested(attr)))
+		case attrPinPhaseAdjustMin:
+			value := decoder.int32(attr)
+			pin.PhaseAdjustMin = &value
+		case attrPinPhaseAdjustMax:
+			value := decoder.int32(attr)
+			pin.PhaseAdjustMax = &value
+		case attrPinPhaseAdjust:
+			value := decoder.int32(attr)
+			pin.PhaseAdjust = &value
+		case attrPinFractionalFrequencyOffset:
+			value := decoder.int64(attr)
+			pin.FractionalFrequencyOffset = &value
+		}
+	}
+
+	if decoder.err != nil {
+		return nil, fmt.Errorf("failed to decode pin: %w", decoder.err)
+	}
+
+	return pin, nil
+}
+
+// DecodeNotification returns the device or pin carried by a message from the monitor group
+func DecodeNotification(msg *Message) (*Notification, error) {
+	notification := &Notification{Name: CommandName(msg.Header.Command)}
+
+	switch msg.Header.Command {
+	case CmdDeviceCreateNtf, CmdDeviceDeleteNtf, CmdDeviceChangeNtf:
+		device, err := DecodeDevice(msg.Attributes)
+		if err != nil {
+			return nil, err
+		}
+
+		notification.Device = device
+		notification.Msg = device
+	case CmdPinCreateNtf, CmdPinDeleteNtf, CmdPinChangeNtf:
+		pin, err := DecodePin(msg.Attributes)
+		if err != nil {
+			return nil, err
+		}
+
+		notification.Pin = pin
+		notification.Msg = pin
+	default:
+		return nil, fmt.Errorf("unexpected command %d from the monitor group", msg.Header.Command)
+	}
+
+	return notification, nil
+}
@@ -0,0 +1,284 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Package dpll is a generic netlink client for the kernel's DPLL subsystem
+package dpll
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+const (
+	// nlmsghdr is the length, type, flags, sequence number and port ID
+	netlinkHeaderLength = 16
+	// genlmsghdr is the command, version and two reserved bytes
+	genericHeaderLength   = 4
+	attributeHeaderLength = 4
+	netlinkAlignment      = 4
+
+	flagRequest = 0x1
+	flagAck     = 0x4
+	flagDump    = 0x300
+
+	typeError = 0x2
+	typeDone  = 0x3
+
+	attributeNested    = 0x8000
+	attributeByteOrder = 0x4000
+	attributeTypeMask  = ^uint16(attributeNested | attributeByteOrder)
+)
+
+var (
+	// netlink uses the byte order of the host
+	byteOrder = binary.NativeEndian
+
+	ErrTruncatedMessage   = errors.New("truncated netlink message")
+	ErrTruncatedAttribute = errors.New("truncated netlink attribute")
+)
+
+// Header is the netlink and generic netlink header of a message
+type Header struct {
+	Length   uint32
+	Sequence uint32
+	PortID   uint32
+	Type     uint16
+	Flags    uint16
+	Command  uint8
+	Version  uint8
+}
+
+// Message is a generic netlink message and its encoded attributes
+type Message struct {
+	Attributes []byte
+	Header     Header
+	// Error is the errno of an NLMSG_ERROR or NLMSG_DONE, zero for an ack
+	Error syscall.Errno
+}
+
+// IsDone returns true if the message ends a dump
+func (msg *Message) IsDone() bool {
+	return msg.Header.Type == typeDone
+}
+
+// IsError returns true if the message is an ack or an error
+func (msg *Message) IsError() bool {
+	return msg.Header.Type == typeError
+}
+
+// Attribute is a single netlink attribute with its flags removed from the type
+type Attribute struct {
+	Data   []byte
+	Type   uint16
+	Nested bool
+}
+
+func align(length int) int {
+	return (length + netlinkAlignment - 1) &^ (netlinkAlignment - 1)
+}
+
+// Uint8 returns the value of a u8 attribute
+func (attr *Attribute) Uint8() (uint8, error) {
+	if len(attr.Data) < 1 {
+		return 0, fmt.Errorf("attribute %d: %w", attr.Type, ErrTruncatedAttribute)
+	}
+
+	return attr.Data[0], nil
+}
+
+// Uint16 returns the value of a u16 attribute
+func (attr *Attribute) Uint16() (uint16, error) {
+	if len(attr.Data) < 2 { //nolint:mnd // size of a u16
+		return 0, fmt.Errorf("attribute %d: %w", attr.Type, ErrTruncatedAttribute)
+	}
+
+	return byteOrder.Uint16(attr.Data), nil
+}
+
+// Uint32 returns the value of a u32 attribute
+func (attr *Attribute) Uint32() (uint32, error) {
+	if len(attr.Data) < 4 { //nolint:mnd // size of a u32
+		return 0, fmt.Errorf("attribute %d: %w", attr.Type, ErrTruncatedAttribute)
+	}
+
+	return byteOrder.Uint32(attr.Data), nil
+}
+
+// Uint64 returns the value of a u64 attribute, or of a uint attribute which the kernel
+// sends as four bytes when the value fits
+func (attr *Attribute) Uint64() (uint64, error) {
+	switch {
+	case len(attr.Data) >= 8: //nolint:mnd // size of a u64
+		return byteOrder.Uint64(attr.Data), nil
+	case len(attr.Data) >= 4: //nolint:mnd // size of a u32
+		return uint64(byteOrder.Uint32(attr.Data)), nil
+	default:
+		return 0, fmt.Errorf("attribute %d: %w", attr.Type, ErrTruncatedAttribute)
+	}
+}
+
+// Int32 returns the value of an s32 attribute
+func (attr *Attribute) Int32() (int32, error) {
+	value, err := attr.Uint32()
+
+	return int32(value), err //nolint:gosec // the bits are reinterpreted as signed
+}
+
+// Int64 returns the value of an s64 attribute, or of a sint attribute which the kernel
+// sends as four bytes when the value fits
+func (attr *Attribute) Int64() (int64, error) {
+	if len(attr.Data) >= 4 && len(attr.Data) < 8 { //nolint:mnd // sizes of an s32 and an s64
+		value, err := attr.Int32()
+		return int64(value), err
+	}
+
+	value, err := attr.Uint64()
+
+	return int64(value), err //nolint:gosec // the bits are reinterpreted as signed
+}
+
+// String returns the value of a NUL terminated string attribute
+func (attr *Attribute) String() string {
+	for i, b := range attr.Data {
+		if b == 0 {
+			return string(attr.Data[:i])
+		}
+	}
+
+	return string(attr.Data)
+}
+
+// ParseAttributes splits data into its attributes
+func ParseAttributes(data []byte) ([]*Attribute, error) {
+	attrs := make([]*Attribute, 0)
+
+	for len(data) >= attributeHeaderLength {
+		length := int(byteOrder.Uint16(data))
+		attrType := byteOrder.Uint16(data[2:])
+
+		if length < attributeHeaderLength || length > len(data) {
+			return attrs, fmt.Errorf("attribute %d of length %d: %w", attrType&attributeTypeMask, length, ErrTruncatedAttribute)
+		}
+
+		attrs = append(attrs, &Attribute{
+			Type:   attrType & attributeTypeMask,
+			Nested: attrType&attributeNested != 0,
+			Data:   data[attributeHeaderLength:length],
+		})
+
+		if align(length) >= len(data) {
+			return attrs, nil
+		}
+
+		data = data[align(length):]
+	}
+
+	if len(data) != 0 {
+		return attrs, fmt.Errorf("%d trailing bytes: %w", len(data), ErrTruncatedAttribute)
+	}
+
+	return attrs, nil
+}
+
+// AttributeEncoder builds the attributes of a request
+type AttributeEncoder struct {
+	data []byte
+}
+
+func (encoder *AttributeEncoder) add(attrType uint16, value []byte) {
+	length := attributeHeaderLength + len(value)
+	attr := make([]byte, align(length))
+	byteOrder.PutUint16(attr, uint16(length)) //nolint:gosec // attributes are much smaller than 64KiB
+	byteOrder.PutUint16(attr[2:], attrType)
+	copy(attr[attributeHeaderLength:], value)
+
+	encoder.data = append(encoder.data, attr...)
+}
+
+// Uint16 adds a u16 attribute
+func (encoder *AttributeEncoder) Uint16(attrType, value uint16) {
+	encoder.add(attrType, byteOrder.AppendUint16(nil, value))
+}
+
+// Uint32 adds a u32 attribute
+func (encoder *AttributeEncoder) Uint32(attrType uint16, value uint32) {
+	encoder.add(attrType, byteOrder.AppendUint32(nil, value))
+}
+
+// String adds a NUL terminated string attribute
+func (encoder *AttributeEncoder) String(attrType uint16, value string) {
+	encoder.add(attrType, append([]byte(value), 0))
+}
+
+// Bytes returns the encoded attributes
+func (encoder *AttributeEncoder) Bytes() []byte {
+	return encoder.data
+}
+
+// EncodeMessage returns a generic netlink message ready to be sent to the kernel
+func EncodeMessage(header *Header, attrs []byte) []byte {
+	length := netlinkHeaderLength + genericHeaderLength + len(attrs)
+	msg := make([]byte, netlinkHeaderLength+genericHeaderLength, length)
+
+	byteOrder.PutUint32(msg, uint32(length)) //nolint:gosec // requests are much smaller than 4GiB
+	byteOrder.PutUint16(msg[4:], header.Type)
+	byteOrder.PutUint16(msg[6:], header.Flags)
+	byteOrder.PutUint32(msg[8:], header.Sequence)
+	byteOrder.PutUint32(msg[12:], header.PortID)
+	msg[16] = header.Command
+	msg[17] = header.Version
+
+	return append(msg, attrs...)
+}
+
+// ParseMessages splits a datagram received from the kernel into its messages
+func ParseMessages(data []byte) ([]*Message, error) {
+	msgs := make([]*Message, 0)
+
+	for len(data) >= netlinkHeaderLength {
+		header := Header{
+			Length:   byteOrder.Uint32(data),
+			Type:     byteOrder.Uint16(data[4:]),
+			Flags:    byteOrder.Uint16(data[6:]),
+			Sequence: byteOrder.Uint32(data[8:]),
+			PortID:   byteOrder.Uint32(data[12:]),
+		}
+
+		length := int(header.Length)
+		if length < netlinkHeaderLength || length > len(data) {
+			return msgs, fmt.Errorf("message of length %d in %d bytes: %w", length, len(data), ErrTruncatedMessage)
+		}
+
+		payload := data[netlinkHeaderLength:length]
+		msg := &Message{Header: header}
+
+		switch {
+		case header.Type == typeError || header.Type == typeDone:
+			// both start with the negated errno, errors then echo the request
+			if len(payload) >= 4 { //nolint:mnd // size of an s32
+				msg.Error = syscall.Errno(-int32(byteOrder.Uint32(payload))) //nolint:gosec // errnos are small
+			}
+		case len(payload) >= genericHeaderLength:
+			msg.Header.Command = payload[0]
+			msg.Header.Version = payload[1]
+			msg.Attributes = payload[genericHeaderLength:]
+		default:
+			return msgs, fmt.Errorf("message without a generic netlink header: %w", ErrTruncatedMessage)
+		}
+
+		msgs = append(msgs, msg)
+
+		if align(length) >= len(data) {
+			return msgs, nil
+		}
+
+		data = data[align(length):]
+	}
+
+	if len(data) != 0 {
+		return msgs, fmt.Errorf("%d trailing bytes: %w", len(data), ErrTruncatedMessage)
+	}
+
+	return msgs, nil
+}
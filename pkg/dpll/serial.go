@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package dpll
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// extendedCapabilitiesStart is the offset of the first PCIe extended capability in the config space
+	extendedCapabilitiesStart = 0x100
+	extendedCapabilityHeader  = 4
+	serialNumberCapabilityID  = 0x0003
+	serialNumberLength        = 8
+	capabilityIDMask          = 0xffff
+	capabilityNextShift       = 20
+	capabilityNextMask        = 0xffc
+)
+
+var errNoSerialNumber = errors.New("the device has no serial number capability")
+
+// SerialNumber returns the PCIe device serial number of the card of an interface,
+// its config space beyond the first 256 bytes can only be read with CAP_SYS_ADMIN
+func SerialNumber(interfaceName string) (string, error) {
+	configPath := filepath.Join("/sys/class/net", interfaceName, "device", "config")
+
+	config, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the PCI config of %s: %w", interfaceName, err)
+	}
+
+	serialNumber, err := SerialNumberFromConfig(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the serial number of %s: %w", interfaceName, err)
+	}
+
+	return serialNumber, nil
+}
+
+// SerialNumberFromConfig finds the device serial number capability in a PCIe config space and returns
+// the serial number as hex, which is how lspci shows it with the dashes removed
+func SerialNumberFromConfig(config []byte) (string, error) {
+	offset := extendedCapabilitiesStart
+
+	// Every capability takes at least its header so there can be no more than this many before a loop is certain
+	for range (len(config) - extendedCapabilitiesStart) / extendedCapabilityHeader {
+		if offset < extendedCapabilitiesStart || offset+extendedCapabilityHeader > len(config) {
+			break
+		}
+
+		header := binary.LittleEndian.Uint32(config[offset:])
+		if header == 0 || header == ^uint32(0) {
+			break
+		}
+
+		if header&capabilityIDMask == serialNumberCapabilityID {
+			start := offset + extendedCapabilityHeader
+			if start+serialNumberLength > len(config) {
+				return "", errors.New("the serial number capability is truncated")
+			}
+
+			return fmt.Sprintf("%016x", binary.LittleEndian.Uint64(config[start:])), nil
+		}
+
+		offset = int(header>>capabilityNextShift) & capabilityNextMask
+	}
+
+	return "", errNoSerialNumber
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package dpll
+
+import "strconv"
+
+// Values from Documentation/netlink/specs/dpll.yaml and the nlctrl family
+
+const (
+	FamilyName    = "dpll"
+	FamilyVersion = 1
+	MonitorGroup  = "monitor"
+
+	ctrlFamilyID         = 0x10
+	ctrlCmdGetFamily     = 3
+	ctrlAttrFamilyID     = 1
+	ctrlAttrFamilyName   = 2
+	ctrlAttrMcastGroups  = 7
+	ctrlAttrMcastGrpName = 1
+	ctrlAttrMcastGrpID   = 2
+)
+
+// Commands
+const (
+	CmdDeviceIDGet     = 1
+	CmdDeviceGet       = 2
+	CmdDeviceSet       = 3
+	CmdDeviceCreateNtf = 4
+	CmdDeviceDeleteNtf = 5
+	CmdDeviceChangeNtf = 6
+	CmdPinIDGet        = 7
+	CmdPinGet          = 8
+	CmdPinSet          = 9
+	CmdPinCreateNtf    = 10
+	CmdPinDeleteNtf    = 11
+	CmdPinChangeNtf    = 12
+)
+
+// Device attributes
+const (
+	attrID                = 1
+	attrModuleName        = 2
+	attrClockID           = 4
+	attrMode              = 5
+	attrModeSupported     = 6
+	attrLockStatus        = 7
+	attrTemp              = 8
+	attrType              = 9
+	attrLockStatusError   = 10
+	attrClockQualityLevel = 11
+)
+
+// Pin attributes
+const (
+	attrPinID                        = 1
+	attrPinParentID                  = 2
+	attrPinModuleName                = 3
+	attrPinClockID                   = 5
+	attrPinBoardLabel                = 6
+	attrPinPanelLabel                = 7
+	attrPinPackageLabel              = 8
+	attrPinType                      = 9
+	attrPinDirection                 = 10
+	attrPinFrequency                 = 11
+	attrPinFrequencySupported        = 12
+	attrPinFrequencyMin              = 13
+	attrPinFrequencyMax              = 14
+	attrPinPrio                      = 15
+	attrPinState                     = 16
+	attrPinCapabilities              = 17
+	attrPinParentDevice              = 18
+	attrPinParentPin                 = 19
+	attrPinPhaseAdjustMin            = 20
+	attrPinPhaseAdjustMax            = 21
+	attrPinPhaseAdjust               = 22
+	attrPinPhaseOffset               = 23
+	attrPinFractionalFrequencyOffset = 24
+)
+
+var (
+	commandNames = map[uint8]string{
+		CmdDeviceIDGet:     "device-id-get",
+		CmdDeviceGet:       "device-get",
+		CmdDeviceSet:       "device-set",
+		CmdDeviceCreateNtf: "device-create-ntf",
+		CmdDeviceDeleteNtf: "device-delete-ntf",
+		CmdDeviceChangeNtf: "device-change-ntf",
+		CmdPinIDGet:        "pin-id-get",
+		CmdPinGet:          "pin-get",
+		CmdPinSet:          "pin-set",
+		CmdPinCreateNtf:    "pin-create-ntf",
+		CmdPinDeleteNtf:    "pin-delete-ntf",
+		CmdPinChangeNtf:    "pin-change-ntf",
+	}
+	modeNames            = []string{"", "manual", "automatic"}
+	lockStatusNames      = []string{"", "unlocked", "locked", "locked-ho-acq", "holdover"}
+	lockStatusErrorNames = []string{"", "none", "undefined", "media-down", "fractional-frequency-offset-too-high"}
+	typeNames            = []string{"", "pps", "eec"}
+	clockQualityNames    = []string{
+		"", "itu-opt1-prc", "itu-opt1-ssu-a", "itu-opt1-ssu-b", "itu-opt1-eec1", "itu-opt1-prtc",
+		"itu-opt1-eprtc", "itu-opt1-eeec", "itu-opt1-eprc",
+	}
+	pinTypeNames      = []string{"", "mux", "ext", "synce-eth-port", "int-oscillator", "gnss"}
+	pinDirectionNames = []string{"", "input", "output"}
+	pinStateNames     = []string{"", "connected", "disconnected", "selectable"}
+	// pinCapabilityNames are the names of the bits of the capabilities flags
+	pinCapabilityNames = []string{"direction-can-change", "priority-can-change", "state-can-change"}
+)
+
+// CommandName returns the name of a command as it is in the spec, such as pin-change-ntf
+func CommandName(cmd uint8) string {
+	if name, ok := commandNames[cmd]; ok {
+		return name
+	}
+
+	return ""
+}
+
+// enumName returns the spec name of value, or the number itself if it is newer than this client
+func enumName(names []string, value uint32) string {
+	if int(value) < len(names) && names[value] != "" {
+		return names[value]
+	}
+
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+func flagNames(names []string, value uint32) []string {
+	flags := make([]string, 0)
+
+	for bit, name := range names {
+		if value&(1<<bit) != 0 {
+			flags = append(flags, name)
+		}
+	}
+
+	return flags
+}
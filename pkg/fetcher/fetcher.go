@@ -7,12 +7,18 @@ import (
 	"fmt"
 	"maps"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
+// timestampKey is the key of the remote timestamp used to work out the clock skew
+const timestampKey = "date"
+
 type PostProcessFuncType func(map[string]string) (map[string]any, error)
 
 type Fetcher struct {
@@ -59,11 +65,15 @@ func (inst *Fetcher) AddCommand(cmdInst *clients.Cmd) {
 // Fetch executes the commands on the container passed as the ctx and
 // use the results to populate pack
 func (inst *Fetcher) Fetch(ctx clients.ExecContext, pack any) error {
+	sentAt := time.Now()
+
 	runResult, err := runCommands(ctx, inst.cmdGrp)
 	if err != nil {
 		return err
 	}
 
+	timing := newSampleTiming(sentAt, time.Since(sentAt), runResult[timestampKey])
+
 	result := make(map[string]any)
 	for key, value := range runResult {
 		result[key] = value
@@ -83,9 +93,39 @@ func (inst *Fetcher) Fetch(ctx clients.ExecContext, pack any) error {
 		return fmt.Errorf("feching failed to unpack data %w", err)
 	}
 
+	if timed, ok := pack.(callbacks.TimedSample); ok {
+		timed.SetSampleTiming(timing)
+	}
+
 	return nil
 }
 
+// newSampleTiming works out the timing of a sample from its round trip.
+// The skew assumes the remote timestamp was taken half way through the
+// round trip so it is only accurate to within half of the latency.
+func newSampleTiming(sentAt time.Time, latency time.Duration, remoteTimestamp string) *callbacks.SampleTiming {
+	timing := &callbacks.SampleTiming{
+		SentAt:      sentAt,
+		ExecLatency: latency,
+	}
+
+	if remoteTimestamp == "" {
+		return timing
+	}
+
+	// The skew is the opposite of how far the local clock is ahead of the remote one
+	offset, err := utils.ClockOffset(remoteTimestamp, sentAt.Add(latency/2)) //nolint:mnd // the middle of the round trip
+	if err != nil {
+		log.Debugf("failed to work out the clock skew: %s", err.Error())
+		return timing
+	}
+
+	skew := -offset
+	timing.ClockSkew = &skew
+
+	return timing
+}
+
 // runCommands executes the commands on the container passed as the ctx
 // and extracts the results from the stdout
 func runCommands(ctx clients.ExecContext, cmdGrp clients.Cmder) (result map[string]string, err error) { //nolint:lll // allow slightly long function definition
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package fetcher //nolint:testpackage // testing internal functions
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("newSampleTiming", func() {
+	sentAt := time.Date(2023, 6, 16, 11, 49, 47, 0, time.UTC)
+
+	When("there is a remote timestamp", func() {
+		It("should measure the skew from the middle of the round trip", func() {
+			timing := newSampleTiming(sentAt, 10*time.Millisecond, "2023-06-16T11:49:47.015Z")
+			Expect(timing.ExecLatency).To(Equal(10 * time.Millisecond))
+			Expect(timing.ClockSkew).NotTo(BeNil())
+			Expect(*timing.ClockSkew).To(Equal(10 * time.Millisecond))
+		})
+	})
+	When("there is no remote timestamp", func() {
+		It("should only have the latency", func() {
+			timing := newSampleTiming(sentAt, 10*time.Millisecond, "")
+			Expect(timing.ExecLatency).To(Equal(10 * time.Millisecond))
+			Expect(timing.ClockSkew).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Interference looks for signs of GNSS jamming and spoofing in the values read from the receiver
+package interference
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+const (
+	KindJamming  = "jamming"
+	KindSpoofing = "spoofing"
+
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+	SeverityCleared  = "cleared"
+
+	CheckJamInd       = "jamInd"
+	CheckAGCDrop      = "agcDrop"
+	CheckNoiseRise    = "noiseRise"
+	CheckLowCNO       = "lowCno"
+	CheckCNODrop      = "cnoDrop"
+	CheckUniformCNO   = "uniformCno"
+	CheckPositionJump = "positionJump"
+	CheckTimeJump     = "timeJump"
+
+	rfMonID   = "gnss/rf-mon"
+	hwMonID   = "gnss/hw-mon"
+	satInfoID = "gnss/sat-info"
+	utcTimeID = "gnss/utc-time"
+	gpsdSkyID = "gnss/gpsd-sky"
+	gpsdTPVID = "gnss/gpsd-tpv"
+
+	percent       = 100
+	earthRadiusM  = 6371000
+	gpsdFixMode3D = 3
+)
+
+// Thresholds control when a value is taken as evidence of interference
+type Thresholds struct {
+	// JamIndWarning and JamIndCritical are the CW jamming indicator of UBX-MON-RF and UBX-MON-HW,
+	// which goes from 0 for no jamming to 255 for strong jamming
+	JamIndWarning  int
+	JamIndCritical int
+	// AGCDropPercent is how far the AGC count can fall below its usual value
+	AGCDropPercent float64
+	// NoiseRisePercent is how far the noise level can rise above its usual value
+	NoiseRisePercent float64
+	// MinMeanCNO is the lowest mean C/N0 in dB-Hz of the satellites used in the fix
+	MinMeanCNO float64
+	// CNODropDB is how far the mean C/N0 of the satellites used in the fix can fall below its usual value
+	CNODropDB float64
+	// UniformCNOStdDev is the spread of C/N0 below which the signals look as if they come from one transmitter
+	// rather than satellites spread across the sky, it needs at least MinUniformSatellites in the fix
+	UniformCNOStdDev     float64
+	MinUniformSatellites int
+	// PositionJump is how far in metres the fix can move between reports, the antenna is not expected to move
+	PositionJump float64
+	// TimeJump is how far the receiver's time can move against the system clock between reports
+	TimeJump time.Duration
+}
+
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		JamIndWarning:        50,  //nolint:mnd // u-blox suggest values above 50 are worth investigating
+		JamIndCritical:       150, //nolint:mnd // strong jamming
+		AGCDropPercent:       25,  //nolint:mnd // default threshold
+		NoiseRisePercent:     25,  //nolint:mnd // default threshold
+		MinMeanCNO:           30,  //nolint:mnd // a clear sky usually gives above 35 dB-Hz
+		CNODropDB:            6,   //nolint:mnd // default threshold
+		UniformCNOStdDev:     1,
+		MinUniformSatellites: 5,   //nolint:mnd // default threshold
+		PositionJump:         100, //nolint:mnd // default threshold
+		TimeJump:             5 * time.Second,
+	}
+}
+
+// Evidence is a value which suggests interference and the threshold it crossed
+type Evidence struct {
+	Baseline  *float64 `json:"baseline,omitempty"`
+	Check     string   `json:"check"`
+	RecordID  string   `json:"recordId"`
+	Detail    string   `json:"detail,omitempty"`
+	Kind      string   `json:"kind"`
+	Severity  string   `json:"severity"`
+	Value     float64  `json:"value"`
+	Threshold float64  `json:"threshold"`
+}
+
+func checkJamInd(recordID, detail string, jamInd int, thresholds *Thresholds) *Evidence {
+	evidence := &Evidence{
+		Check:    CheckJamInd,
+		RecordID: recordID,
+		Detail:   detail,
+		Kind:     KindJamming,
+		Value:    float64(jamInd),
+	}
+
+	switch {
+	case jamInd >= thresholds.JamIndCritical:
+		evidence.Severity = SeverityCritical
+		evidence.Threshold = float64(thresholds.JamIndCritical)
+	case jamInd >= thresholds.JamIndWarning:
+		evidence.Severity = SeverityWarning
+		evidence.Threshold = float64(thresholds.JamIndWarning)
+	default:
+		return nil
+	}
+
+	return evidence
+}
+
+// usedCNOs returns the C/N0 of the satellites used in the fix
+func usedCNOs(navSat *devices.GPSNavSat) []float64 {
+	cnos := make([]float64, 0, navSat.NumUsed)
+
+	for _, sat := range navSat.Satellites {
+		if sat.Used {
+			cnos = append(cnos, float64(sat.CNO))
+		}
+	}
+
+	return cnos
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+
+	return total / float64(len(values))
+}
+
+func stdDev(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	avg := mean(values)
+	total := 0.0
+
+	for _, value := range values {
+		total += (value - avg) * (value - avg)
+	}
+
+	return math.Sqrt(total / float64(len(values)))
+}
+
+// checkSignal looks at the C/N0 of the satellites used in the fix for signs of jamming and spoofing
+func checkSignal(recordID string, cnos []float64, thresholds *Thresholds) []*Evidence {
+	found := make([]*Evidence, 0)
+
+	if len(cnos) == 0 {
+		return found
+	}
+
+	if meanCNO := mean(cnos); meanCNO < thresholds.MinMeanCNO {
+		found = append(found, &Evidence{
+			Check:     CheckLowCNO,
+			RecordID:  recordID,
+			Kind:      KindJamming,
+			Severity:  SeverityWarning,
+			Value:     meanCNO,
+			Threshold: thresholds.MinMeanCNO,
+		})
+	}
+
+	if len(cnos) >= thresholds.MinUniformSatellites {
+		if spread := stdDev(cnos); spread < thresholds.UniformCNOStdDev {
+			found = append(found, &Evidence{
+				Check:     CheckUniformCNO,
+				RecordID:  recordID,
+				Detail:    fmt.Sprintf("%d satellites", len(cnos)),
+				Kind:      KindSpoofing,
+				Severity:  SeverityWarning,
+				Value:     spread,
+				Threshold: thresholds.UniformCNOStdDev,
+			})
+		}
+	}
+
+	return found
+}
+
+// Check returns the evidence of interference in a single read of the receiver.
+// It leaves out the checks which compare values against earlier reads.
+func Check(details *devices.GPSDetails, thresholds *Thresholds) []*Evidence {
+	found := make([]*Evidence, 0)
+
+	for _, block := range details.AntennaDetails {
+		if evidence := checkJamInd(rfMonID, blockDetail(block.BlockID), block.JamInd, thresholds); evidence != nil {
+			found = append(found, evidence)
+		}
+	}
+
+	if details.MonHW != nil {
+		if evidence := checkJamInd(hwMonID, "", details.MonHW.JamInd, thresholds); evidence != nil {
+			found = append(found, evidence)
+		}
+	}
+
+	if details.NavSat != nil {
+		found = append(found, checkSignal(satInfoID, usedCNOs(details.NavSat), thresholds)...)
+	}
+
+	return found
+}
+
+// HasKind returns true if any of the evidence is of kind
+func HasKind(evidence []*Evidence, kind string) bool {
+	for _, e := range evidence {
+		if e.Kind == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+func blockDetail(blockID int) string {
+	return fmt.Sprintf("block %d", blockID)
+}
+
+// distance returns the great circle distance in metres between two points given in degrees
+func distance(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := math.Pi / 180 //nolint:mnd // degrees in half a turn
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusM * math.Atan2(math.Sqrt(a), math.Sqrt(1-a)) //nolint:mnd // haversine formula
+}
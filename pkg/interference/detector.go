@@ -0,0 +1,456 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package interference
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+const (
+	interferenceTag = "interference"
+
+	// The first reads set the usual value, after that it follows slow changes
+	baselineSamples = 10
+	baselineWeight  = 0.05
+)
+
+var kinds = []string{KindJamming, KindSpoofing}
+
+// Event is emitted when the severity of jamming or spoofing seen on a target changes
+type Event struct {
+	Timestamp       string      `json:"timestamp"`
+	SourceTimestamp string      `json:"sourceTimestamp,omitempty"`
+	Kind            string      `json:"kind"`
+	Severity        string      `json:"severity"`
+	Node            string      `json:"node,omitempty"`
+	Interface       string      `json:"interface,omitempty"`
+	Evidence        []*Evidence `json:"evidence"`
+}
+
+// GetAnalyserFormat returns the json expected by the analysers
+func (event *Event) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{{
+		ID:   "gnss/interference",
+		Data: event,
+	}}, nil
+}
+
+type baseline struct {
+	usual   float64
+	samples int
+}
+
+func (b *baseline) add(value float64) {
+	if b.samples < baselineSamples {
+		b.samples++
+		b.usual += (value - b.usual) / float64(b.samples)
+
+		return
+	}
+
+	b.usual += baselineWeight * (value - b.usual)
+}
+
+type position struct {
+	lat float64
+	lon float64
+}
+
+type targetState struct {
+	baselines   map[string]*baseline
+	evidence    map[string][]*Evidence
+	severities  map[string]string
+	positions   map[string]position
+	timeOffsets map[string]time.Duration
+}
+
+func newTargetState() *targetState {
+	return &targetState{
+		baselines:   make(map[string]*baseline),
+		evidence:    make(map[string][]*Evidence),
+		severities:  make(map[string]string),
+		positions:   make(map[string]position),
+		timeOffsets: make(map[string]time.Duration),
+	}
+}
+
+// Detector looks for jamming and spoofing in every output it observes and
+// writes an Event to its callback whenever the severity seen on a target changes.
+type Detector struct {
+	callback   callbacks.Callback
+	targets    map[string]*targetState
+	thresholds Thresholds
+	mu         sync.Mutex
+	detected   bool
+}
+
+func NewDetector(thresholds Thresholds, callback callbacks.Callback) *Detector {
+	return &Detector{
+		callback:   callback,
+		thresholds: thresholds,
+		targets:    make(map[string]*targetState),
+	}
+}
+
+// HasDetected returns true if any interference has been seen since the detector was created
+func (detector *Detector) HasDetected() bool {
+	detector.mu.Lock()
+	defer detector.mu.Unlock()
+
+	return detector.detected
+}
+
+// usual returns the usual value for key once enough reads have been seen
+func (state *targetState) usual(key string) (float64, bool) {
+	b, ok := state.baselines[key]
+	if !ok || b.samples < baselineSamples {
+		return 0, false
+	}
+
+	return b.usual, true
+}
+
+// updateBaselines adds the values of a read to the baselines. Reads showing jamming
+// are left out so that a long period of jamming does not become the usual value.
+func (state *targetState) updateBaselines(values map[string]float64, found []*Evidence) {
+	if HasKind(found, KindJamming) {
+		return
+	}
+
+	for key, value := range values {
+		b, ok := state.baselines[key]
+		if !ok {
+			b = &baseline{}
+			state.baselines[key] = b
+		}
+
+		b.add(value)
+	}
+}
+
+func baselineEvidence(check, recordID, detail string, value, threshold, usual float64) *Evidence {
+	return &Evidence{
+		Check:     check,
+		RecordID:  recordID,
+		Detail:    detail,
+		Kind:      KindJamming,
+		Severity:  SeverityWarning,
+		Value:     value,
+		Threshold: threshold,
+		Baseline:  &usual,
+	}
+}
+
+// checkFrontEnd compares the AGC count and noise level against their usual values,
+// a jammer makes the receiver turn its gain down and raises the noise floor
+func (state *targetState) checkFrontEnd(
+	recordID, detail string,
+	agcCnt, noisePerMS int,
+	thresholds *Thresholds,
+	values map[string]float64,
+) []*Evidence {
+	found := make([]*Evidence, 0)
+	agcKey := recordID + "/agcCnt/" + detail
+	noiseKey := recordID + "/noisePerMS/" + detail
+
+	if usual, ok := state.usual(agcKey); ok {
+		threshold := usual * (1 - thresholds.AGCDropPercent/percent)
+		if float64(agcCnt) < threshold {
+			found = append(found, baselineEvidence(CheckAGCDrop, recordID, detail, float64(agcCnt), threshold, usual))
+		}
+	}
+
+	if usual, ok := state.usual(noiseKey); ok {
+		threshold := usual * (1 + thresholds.NoiseRisePercent/percent)
+		if float64(noisePerMS) > threshold {
+			found = append(found, baselineEvidence(CheckNoiseRise, recordID, detail, float64(noisePerMS), threshold, usual))
+		}
+	}
+
+	values[agcKey] = float64(agcCnt)
+	values[noiseKey] = float64(noisePerMS)
+
+	return found
+}
+
+// checkCNODrop compares the mean C/N0 of the satellites used in the fix against its usual value
+func (state *targetState) checkCNODrop(
+	recordID string,
+	cnos []float64,
+	thresholds *Thresholds,
+	values map[string]float64,
+) []*Evidence {
+	if len(cnos) == 0 {
+		return []*Evidence{}
+	}
+
+	key := recordID + "/meanUsedCno"
+	meanCNO := mean(cnos)
+	values[key] = meanCNO
+
+	if usual, ok := state.usual(key); ok {
+		if threshold := usual - thresholds.CNODropDB; meanCNO < threshold {
+			return []*Evidence{baselineEvidence(CheckCNODrop, recordID, "", meanCNO, threshold, usual)}
+		}
+	}
+
+	return []*Evidence{}
+}
+
+// checkTimeJump compares the offset between the receiver's time and the system clock with the last one
+func (state *targetState) checkTimeJump(
+	key, recordID, detail string,
+	offset time.Duration,
+	thresholds *Thresholds,
+) []*Evidence {
+	last, ok := state.timeOffsets[key]
+	state.timeOffsets[key] = offset
+
+	jump := offset - last
+	if !ok || jump.Abs() <= thresholds.TimeJump {
+		return []*Evidence{}
+	}
+
+	return []*Evidence{{
+		Check:     CheckTimeJump,
+		RecordID:  recordID,
+		Detail:    detail,
+		Kind:      KindSpoofing,
+		Severity:  SeverityWarning,
+		Value:     float64(jump.Nanoseconds()),
+		Threshold: float64(thresholds.TimeJump.Nanoseconds()),
+	}}
+}
+
+func (state *targetState) checkGPSDetails(details *devices.GPSDetails, thresholds *Thresholds) []*Evidence {
+	found := Check(details, thresholds)
+	values := make(map[string]float64)
+
+	for _, block := range details.AntennaDetails {
+		found = append(found,
+			state.checkFrontEnd(rfMonID, blockDetail(block.BlockID), block.AGCCnt, block.NoisePerMS, thresholds, values)...,
+		)
+	}
+
+	if details.MonHW != nil {
+		found = append(found,
+			state.checkFrontEnd(hwMonID, "", details.MonHW.AGCCnt, details.MonHW.NoisePerMS, thresholds, values)...,
+		)
+	}
+
+	if details.NavSat != nil {
+		found = append(found, state.checkCNODrop(satInfoID, usedCNOs(details.NavSat), thresholds, values)...)
+	}
+
+	if details.TimeUTC != nil && details.TimeUTC.ValidUTC {
+		utc, utcErr := time.Parse(time.RFC3339Nano, details.TimeUTC.UTC)
+		host, hostErr := time.Parse(time.RFC3339Nano, details.TimeUTC.Timestamp)
+
+		if utcErr == nil && hostErr == nil {
+			found = append(found, state.checkTimeJump(utcTimeID, utcTimeID, "", utc.Sub(host), thresholds)...)
+		}
+	}
+
+	state.updateBaselines(values, found)
+
+	return found
+}
+
+func (state *targetState) checkGPSDSky(sky *devices.GPSDSky, thresholds *Thresholds) []*Evidence {
+	cnos := make([]float64, 0, sky.USat)
+
+	for _, sat := range sky.Satellites {
+		if sat.Used {
+			cnos = append(cnos, sat.SS)
+		}
+	}
+
+	values := make(map[string]float64)
+	found := checkSignal(gpsdSkyID, cnos, thresholds)
+	found = append(found, state.checkCNODrop(gpsdSkyID+"/"+sky.Device, cnos, thresholds, values)...)
+
+	// The baseline is kept per device but the evidence is reported against the record
+	for _, evidence := range found {
+		evidence.RecordID = gpsdSkyID
+	}
+
+	state.updateBaselines(values, found)
+
+	return found
+}
+
+func (state *targetState) checkGPSDTPV(tpv *devices.GPSDTPV, thresholds *Thresholds) []*Evidence {
+	if tpv.Mode < gpsdFixMode3D {
+		return []*Evidence{}
+	}
+
+	current := position{lat: tpv.Lat, lon: tpv.Lon}
+	last, ok := state.positions[tpv.Device]
+	state.positions[tpv.Device] = current
+
+	if !ok {
+		return []*Evidence{}
+	}
+
+	moved := distance(last.lat, last.lon, current.lat, current.lon)
+	if moved <= thresholds.PositionJump {
+		return []*Evidence{}
+	}
+
+	return []*Evidence{{
+		Check:     CheckPositionJump,
+		RecordID:  gpsdTPVID,
+		Detail:    tpv.Device,
+		Kind:      KindSpoofing,
+		Severity:  SeverityWarning,
+		Value:     moved,
+		Threshold: thresholds.PositionJump,
+	}}
+}
+
+// check returns the source the evidence should be kept under, the evidence found in output
+// and the timestamp of output. The source is empty if output is not checked.
+func (state *targetState) check(output callbacks.OutputType, thresholds *Thresholds) (string, []*Evidence, string) {
+	switch out := output.(type) {
+	case *devices.GPSDetails:
+		return "ubx", state.checkGPSDetails(out, thresholds), out.NavClock.Timestamp
+	case *devices.GPSDSky:
+		return gpsdSkyID + "/" + out.Device, state.checkGPSDSky(out, thresholds), out.Time
+	case *devices.GPSDTPV:
+		return gpsdTPVID + "/" + out.Device, state.checkGPSDTPV(out, thresholds), out.Time
+	case *devices.GPSDClockOffset:
+		recordID := "gnss/gpsd-" + strings.ToLower(out.Class)
+		source := recordID + "/" + out.Device
+		offset := time.Duration(out.Offset())
+		timestamp := time.Unix(out.RealSec, out.RealNsec).UTC().Format(time.RFC3339Nano)
+
+		return source, state.checkTimeJump(source, recordID, out.Device, offset, thresholds), timestamp
+	default:
+		return "", nil, ""
+	}
+}
+
+// severity returns the severity of the evidence. Two different checks agreeing make it critical.
+func severity(evidence []*Evidence) string {
+	checks := make(map[string]bool)
+
+	for _, e := range evidence {
+		if e.Severity == SeverityCritical {
+			return SeverityCritical
+		}
+
+		checks[e.Check] = true
+	}
+
+	switch {
+	case len(checks) > 1:
+		return SeverityCritical
+	case len(checks) == 1:
+		return SeverityWarning
+	default:
+		return ""
+	}
+}
+
+// changes returns an event for each kind of interference whose severity has changed
+func (state *targetState) changes() []*Event {
+	sources := make([]string, 0, len(state.evidence))
+	for source := range state.evidence {
+		sources = append(sources, source)
+	}
+
+	sort.Strings(sources)
+
+	events := make([]*Event, 0)
+
+	for _, kind := range kinds {
+		evidence := make([]*Evidence, 0)
+
+		for _, source := range sources {
+			for _, e := range state.evidence[source] {
+				if e.Kind == kind {
+					evidence = append(evidence, e)
+				}
+			}
+		}
+
+		current := severity(evidence)
+		if current == state.severities[kind] {
+			continue
+		}
+
+		state.severities[kind] = current
+
+		if current == "" {
+			current = SeverityCleared
+		}
+
+		events = append(events, &Event{Kind: kind, Severity: current, Evidence: evidence})
+	}
+
+	return events
+}
+
+// Observe checks the GNSS and gpsd outputs for signs of interference
+func (detector *Detector) Observe(output callbacks.OutputType, _ string) {
+	var node, iface string
+
+	if targeted, ok := output.(*callbacks.TargetedOutput); ok {
+		output = targeted.Output
+		node = targeted.Node
+		iface = targeted.Interface
+	}
+
+	detector.mu.Lock()
+
+	key := node + "/" + iface
+
+	state, ok := detector.targets[key]
+	if !ok {
+		state = newTargetState()
+		detector.targets[key] = state
+	}
+
+	source, evidence, timestamp := state.check(output, &detector.thresholds)
+	if source == "" {
+		detector.mu.Unlock()
+		return
+	}
+
+	state.evidence[source] = evidence
+	events := state.changes()
+
+	for _, event := range events {
+		event.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+		event.SourceTimestamp = timestamp
+		event.Node = node
+		event.Interface = iface
+
+		if event.Severity != SeverityCleared {
+			detector.detected = true
+		}
+	}
+
+	detector.mu.Unlock()
+
+	for _, event := range events {
+		if event.Severity == SeverityCleared {
+			log.Infof("GNSS %s cleared", event.Kind)
+		} else {
+			log.Warnf("GNSS %s %s with %d pieces of evidence", event.Severity, event.Kind, len(event.Evidence))
+		}
+
+		err := detector.callback.Call(event, interferenceTag)
+		if err != nil {
+			log.Errorf("failed to write interference event: %s", err.Error())
+		}
+	}
+}
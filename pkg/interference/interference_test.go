@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package interference_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/interference"
+)
+
+type testBuffer struct {
+	bytes.Buffer
+}
+
+func (b *testBuffer) Close() error {
+	return nil
+}
+
+type otherOutput struct{}
+
+func (o *otherOutput) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{{ID: "dpll/time-error", Data: map[string]any{}}}, nil
+}
+
+type writtenEvent struct {
+	ID   string             `json:"id"`
+	Data interference.Event `json:"data"`
+}
+
+func readEvents(buffer *testBuffer) []writtenEvent {
+	events := make([]writtenEvent, 0)
+
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		event := writtenEvent{}
+		Expect(json.Unmarshal([]byte(line), &event)).To(Succeed())
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func newDetails(jamInd, agcCnt, noisePerMS int, cnos ...int) *devices.GPSDetails {
+	satellites := make([]*devices.GPSSatellite, 0, len(cnos))
+	total := 0
+
+	for i, cno := range cnos {
+		satellites = append(satellites, &devices.GPSSatellite{GNSSID: 0, SVID: i + 1, CNO: cno, Used: true})
+		total += cno
+	}
+
+	navSat := &devices.GPSNavSat{
+		Timestamp:  "2023-06-16T11:49:47.0584Z",
+		Satellites: satellites,
+		NumSVs:     len(satellites),
+		NumUsed:    len(satellites),
+	}
+	if len(cnos) > 0 {
+		navSat.MeanUsedCN = float64(total) / float64(len(cnos))
+	}
+
+	return &devices.GPSDetails{
+		NavStatus: devices.GPSNavStatus{GPSFix: 3},
+		NavClock:  devices.GPSNavClock{Timestamp: "2023-06-16T11:49:47.0584Z"},
+		AntennaDetails: []*devices.GPSAntennaDetails{{
+			BlockID:    0,
+			Status:     2,
+			Power:      1,
+			JamInd:     jamInd,
+			AGCCnt:     agcCnt,
+			NoisePerMS: noisePerMS,
+		}},
+		NavSat: navSat,
+	}
+}
+
+func checks(evidence []*interference.Evidence) []string {
+	names := make([]string, 0, len(evidence))
+	for _, e := range evidence {
+		names = append(names, e.Check)
+	}
+
+	return names
+}
+
+var _ = Describe("Check", func() {
+	thresholds := interference.DefaultThresholds()
+
+	It("should find nothing in a clean read", func() {
+		Expect(interference.Check(newDetails(5, 5000, 80, 40, 44, 38, 46, 35), &thresholds)).To(BeEmpty())
+	})
+
+	It("should grade the jamming indicator", func() {
+		evidence := interference.Check(newDetails(60, 5000, 80), &thresholds)
+		Expect(evidence).To(HaveLen(1))
+		Expect(evidence[0].Check).To(Equal(interference.CheckJamInd))
+		Expect(evidence[0].Severity).To(Equal(interference.SeverityWarning))
+		Expect(evidence[0].Detail).To(Equal("block 0"))
+
+		evidence = interference.Check(newDetails(200, 5000, 80), &thresholds)
+		Expect(evidence).To(HaveLen(1))
+		Expect(evidence[0].Severity).To(Equal(interference.SeverityCritical))
+		Expect(evidence[0].Threshold).To(BeNumerically("==", thresholds.JamIndCritical))
+	})
+
+	It("should report low and uniform C/N0", func() {
+		evidence := interference.Check(newDetails(5, 5000, 80, 20, 25, 22), &thresholds)
+		Expect(checks(evidence)).To(Equal([]string{interference.CheckLowCNO}))
+		Expect(interference.HasKind(evidence, interference.KindJamming)).To(BeTrue())
+
+		evidence = interference.Check(newDetails(5, 5000, 80, 45, 45, 45, 45, 46), &thresholds)
+		Expect(checks(evidence)).To(Equal([]string{interference.CheckUniformCNO}))
+		Expect(interference.HasKind(evidence, interference.KindJamming)).To(BeFalse())
+		Expect(interference.HasKind(evidence, interference.KindSpoofing)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Detector", func() {
+	var (
+		buffer   *testBuffer
+		detector *interference.Detector
+	)
+
+	BeforeEach(func() {
+		buffer = &testBuffer{}
+		detector = interference.NewDetector(
+			interference.DefaultThresholds(),
+			callbacks.NewFileCallback(buffer, callbacks.AnalyserJSON),
+		)
+	})
+
+	learnBaseline := func() {
+		for range 10 {
+			detector.Observe(newDetails(5, 5000, 80, 40, 44, 38, 46, 35), "gnss")
+		}
+	}
+
+	It("should ignore outputs it does not check", func() {
+		detector.Observe(&otherOutput{}, "dpll")
+		Expect(buffer.Len()).To(Equal(0))
+		Expect(detector.HasDetected()).To(BeFalse())
+	})
+
+	It("should raise, escalate and clear jamming", func() {
+		learnBaseline()
+		Expect(buffer.Len()).To(Equal(0))
+
+		detector.Observe(newDetails(5, 3000, 80, 40, 44, 38, 46, 35), "gnss")
+		detector.Observe(newDetails(5, 3000, 120, 40, 44, 38, 46, 35), "gnss")
+		detector.Observe(newDetails(5, 5000, 80, 40, 44, 38, 46, 35), "gnss")
+
+		events := readEvents(buffer)
+		Expect(events).To(HaveLen(3))
+		Expect(events[0].ID).To(Equal("gnss/interference"))
+		Expect(events[0].Data.Kind).To(Equal(interference.KindJamming))
+		Expect(events[0].Data.Severity).To(Equal(interference.SeverityWarning))
+		Expect(checks(events[0].Data.Evidence)).To(Equal([]string{interference.CheckAGCDrop}))
+		Expect(*events[0].Data.Evidence[0].Baseline).To(BeNumerically("==", 5000))
+		Expect(events[1].Data.Severity).To(Equal(interference.SeverityCritical))
+		Expect(checks(events[1].Data.Evidence)).To(Equal([]string{interference.CheckAGCDrop, interference.CheckNoiseRise}))
+		Expect(events[2].Data.Severity).To(Equal(interference.SeverityCleared))
+		Expect(events[2].Data.Evidence).To(BeEmpty())
+		Expect(detector.HasDetected()).To(BeTrue())
+	})
+
+	It("should not learn jammed reads as the baseline", func() {
+		learnBaseline()
+
+		for range 20 {
+			detector.Observe(newDetails(100, 2000, 80, 40, 44, 38, 46, 35), "gnss")
+		}
+
+		detector.Observe(newDetails(5, 3000, 80, 40, 44, 38, 46, 35), "gnss")
+
+		events := readEvents(buffer)
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Data.Severity).To(Equal(interference.SeverityCritical))
+		Expect(events[1].Data.Severity).To(Equal(interference.SeverityWarning))
+		Expect(checks(events[1].Data.Evidence)).To(Equal([]string{interference.CheckAGCDrop}))
+	})
+
+	It("should report position jumps against the target", func() {
+		targeted := callbacks.NewTargetedCallback(callbacks.NewObservedCallback(
+			callbacks.NewFileCallback(&testBuffer{}, callbacks.AnalyserJSON), detector,
+		), "node-a", "ens1f0")
+
+		for _, tpv := range []*devices.GPSDTPV{
+			{Device: "/dev/gnss0", Mode: 3, Lat: 52.0, Lon: -1.0},
+			{Device: "/dev/gnss0", Mode: 3, Lat: 52.0, Lon: -1.0005},
+			{Device: "/dev/gnss0", Mode: 3, Lat: 52.01, Lon: -1.0005},
+			{Device: "/dev/gnss0", Mode: 3, Lat: 52.01, Lon: -1.0005},
+		} {
+			Expect(targeted.Call(tpv, "gpsd")).To(Succeed())
+		}
+
+		events := readEvents(buffer)
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Data.Kind).To(Equal(interference.KindSpoofing))
+		Expect(events[0].Data.Severity).To(Equal(interference.SeverityWarning))
+		Expect(events[0].Data.Node).To(Equal("node-a"))
+		Expect(events[0].Data.Interface).To(Equal("ens1f0"))
+		Expect(events[0].Data.Evidence).To(HaveLen(1))
+		Expect(events[0].Data.Evidence[0].Check).To(Equal(interference.CheckPositionJump))
+		Expect(events[0].Data.Evidence[0].Value).To(BeNumerically("~", 1112, 1))
+		Expect(events[1].Data.Severity).To(Equal(interference.SeverityCleared))
+	})
+
+	It("should report time jumps from gpsd", func() {
+		for _, clockSec := range []int64{1700000000, 1700000001, 1700000010} {
+			detector.Observe(&devices.GPSDClockOffset{
+				Class: "TOFF", Device: "/dev/gnss0",
+				RealSec: 1700000000, ClockSec: clockSec,
+			}, "gpsd")
+		}
+
+		events := readEvents(buffer)
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Data.Kind).To(Equal(interference.KindSpoofing))
+		Expect(events[0].Data.Evidence[0].Check).To(Equal(interference.CheckTimeJump))
+		Expect(events[0].Data.Evidence[0].RecordID).To(Equal("gnss/gpsd-toff"))
+	})
+})
+
+func TestInterference(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Interference Suite")
+}
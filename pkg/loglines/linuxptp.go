@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package loglines
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+var (
+	// linuxPTPLineRegEx matches the process, its monotonic time and the config it was started with, such as
+	// ptp4l[357138.013]: [ptp4l.0.config:6] master offset -2 s2 freq -3259 path delay 1069
+	linuxPTPLineRegEx = regexp.MustCompile(`^(ptp4l|phc2sys|ts2phc)\[[\d.]+\]: (?:\[([^\]:]+)(?::\d+)?\] )?(.*)$`)
+
+	// linuxPTPOffsetRegEx matches the servo updates of each process, the clock is left out by ptp4l:
+	// master offset         -3 s2 freq   -3259 path delay      1069
+	// CLOCK_REALTIME phc offset        -7 s2 freq  -10108 delay    505
+	// ens6f0 master offset          0 s2 freq      +0
+	linuxPTPOffsetRegEx = regexp.MustCompile(
+		`^(?:(\S+) )??(?:(?:master|phc|sys) )?offset\s+(-?\d+)\s+(s\d)\s+freq\s+([+-]?\d+)` +
+			`(?:\s+(?:path )?delay\s+(-?\d+))?\s*$`,
+	)
+
+	// linuxPTPSummaryRegEx matches the summaries logged every summary_interval in place of the servo updates:
+	// rms    2 max    4 freq  -3266 +/-   2 delay  1069 +/-   0
+	// CLOCK_REALTIME rms    4 max    8 freq -15378 +/-   3 delay   518 +/-   1
+	linuxPTPSummaryRegEx = regexp.MustCompile(
+		`^(?:(\S+) )??rms\s+(\d+)\s+max\s+(\d+)\s+freq\s+([+-]?\d+)\s+\+/-\s+(\d+)` +
+			`(?:\s+delay\s+(\d+)\s+\+/-\s+(\d+))?\s*$`,
+	)
+)
+
+// LinuxPTPOffset is a servo update of ptp4l, phc2sys or ts2phc
+type LinuxPTPOffset struct {
+	// PathDelay is left out by ts2phc
+	PathDelay *int64 `json:"pathDelay,omitempty"`
+	Timestamp string `json:"timestamp"`
+	Process   string `json:"-"`
+	Config    string `json:"config,omitempty"`
+	// Clock is the clock being steered, it is left out by ptp4l
+	Clock string `json:"clock,omitempty"`
+	// ServoState is s0 (unlocked), s1 (stepped), s2 (locked) or s3 (locked stable)
+	ServoState string `json:"servoState"`
+	Offset     int64  `json:"offset"`
+	Freq       int64  `json:"freq"`
+}
+
+// GetAnalyserFormat returns the update as a <process>/offset record
+func (offset *LinuxPTPOffset) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{{ID: offset.Process + "/offset", Data: offset}}, nil
+}
+
+// LinuxPTPSummary is the rms and max offset of ptp4l or phc2sys over its summary_interval
+type LinuxPTPSummary struct {
+	// Delay is left out if the process did not measure it
+	Delay       *int64 `json:"delay,omitempty"`
+	DelayStdDev *int64 `json:"delayStdDev,omitempty"`
+	Timestamp   string `json:"timestamp"`
+	Process     string `json:"-"`
+	Config      string `json:"config,omitempty"`
+	Clock       string `json:"clock,omitempty"`
+	RMS         int64  `json:"rms"`
+	Max         int64  `json:"max"`
+	Freq        int64  `json:"freq"`
+	FreqStdDev  int64  `json:"freqStdDev"`
+}
+
+// GetAnalyserFormat returns the summary as a <process>/summary record
+func (summary *LinuxPTPSummary) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{{ID: summary.Process + "/summary", Data: summary}}, nil
+}
+
+// parseInt parses a value matched by one of the regexes, so it can only fail on overflow
+func parseInt(value string) int64 {
+	parsed, _ := strconv.ParseInt(value, 10, 64) //nolint:errcheck // the regex only matches integers
+	return parsed
+}
+
+func parseOptionalInt(value string) *int64 {
+	if value == "" {
+		return nil
+	}
+
+	parsed := parseInt(value)
+
+	return &parsed
+}
+
+// ParseLinuxPTPLine returns the offset or summary record of a ptp4l, phc2sys or ts2phc line,
+// or false if the line is not one of them
+func ParseLinuxPTPLine(line *ProcessedLine) (callbacks.OutputType, bool) {
+	match := linuxPTPLineRegEx.FindStringSubmatch(line.Content)
+	if match == nil {
+		return nil, false
+	}
+
+	process, config, content := match[1], match[2], match[3]
+	timestamp := line.Timestamp.UTC().Format(time.RFC3339Nano)
+
+	if offset := linuxPTPOffsetRegEx.FindStringSubmatch(content); offset != nil {
+		return &LinuxPTPOffset{
+			Timestamp:  timestamp,
+			Process:    process,
+			Config:     config,
+			Clock:      offset[1],
+			Offset:     parseInt(offset[2]),
+			ServoState: offset[3],
+			Freq:       parseInt(offset[4]),
+			PathDelay:  parseOptionalInt(offset[5]),
+		}, true
+	}
+
+	if summary := linuxPTPSummaryRegEx.FindStringSubmatch(content); summary != nil {
+		return &LinuxPTPSummary{
+			Timestamp:   timestamp,
+			Process:     process,
+			Config:      config,
+			Clock:       summary[1],
+			RMS:         parseInt(summary[2]),
+			Max:         parseInt(summary[3]),
+			Freq:        parseInt(summary[4]),
+			FreqStdDev:  parseInt(summary[5]),
+			Delay:       parseOptionalInt(summary[6]),
+			DelayStdDev: parseOptionalInt(summary[7]),
+		}, true
+	}
+
+	return nil, false
+}
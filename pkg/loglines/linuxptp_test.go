@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package loglines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/loglines"
+)
+
+func parseLinuxPTPLine(line string) (callbacks.OutputType, bool) {
+	processed, err := loglines.ProcessLine(line)
+	Expect(err).NotTo(HaveOccurred())
+
+	return loglines.ParseLinuxPTPLine(processed)
+}
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
+
+var _ = Describe("ParseLinuxPTPLine", func() {
+	It("should parse a ptp4l servo update", func() {
+		record, ok := parseLinuxPTPLine("2023-09-12T20:45:30.577901600Z ptp4l[357138.013]: [ptp4l.0.config:6] " +
+			"master offset         -3 s2 freq   -3259 path delay      1069")
+		Expect(ok).To(BeTrue())
+		Expect(record).To(Equal(&loglines.LinuxPTPOffset{
+			Timestamp:  "2023-09-12T20:45:30.5779016Z",
+			Process:    "ptp4l",
+			Config:     "ptp4l.0.config",
+			ServoState: "s2",
+			Offset:     -3,
+			Freq:       -3259,
+			PathDelay:  int64Ptr(1069),
+		}))
+
+		formatted, err := record.GetAnalyserFormat()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(formatted[0].ID).To(Equal("ptp4l/offset"))
+	})
+
+	It("should parse a phc2sys servo update with the clock it steers", func() {
+		record, ok := parseLinuxPTPLine("2023-09-12T20:45:30.577901600Z phc2sys[357138.013]: [ptp4l.0.config] " +
+			"CLOCK_REALTIME phc offset        -7 s0 freq  -10108 delay    505")
+		Expect(ok).To(BeTrue())
+
+		offset := record.(*loglines.LinuxPTPOffset)
+		Expect(offset.Clock).To(Equal("CLOCK_REALTIME"))
+		Expect(offset.ServoState).To(Equal("s0"))
+		Expect(offset.Offset).To(BeNumerically("==", -7))
+		Expect(offset.PathDelay).To(Equal(int64Ptr(505)))
+
+		formatted, err := record.GetAnalyserFormat()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(formatted[0].ID).To(Equal("phc2sys/offset"))
+	})
+
+	It("should parse a ts2phc servo update which has no delay", func() {
+		record, ok := parseLinuxPTPLine("2023-09-12T20:45:31.000120915Z ts2phc[357138.435]: [ts2phc.0.config] " +
+			"ens6f0 master offset          0 s2 freq      +0")
+		Expect(ok).To(BeTrue())
+
+		offset := record.(*loglines.LinuxPTPOffset)
+		Expect(offset.Clock).To(Equal("ens6f0"))
+		Expect(offset.Freq).To(BeNumerically("==", 0))
+		Expect(offset.PathDelay).To(BeNil())
+
+		record, ok = parseLinuxPTPLine("2023-09-12T20:45:31.000120915Z ts2phc[357138.435]: [ts2phc.0.config:6] " +
+			"/dev/ptp2 offset         12 s1 freq    +350")
+		Expect(ok).To(BeTrue())
+		Expect(record.(*loglines.LinuxPTPOffset).Clock).To(Equal("/dev/ptp2"))
+		Expect(record.(*loglines.LinuxPTPOffset).ServoState).To(Equal("s1"))
+	})
+
+	It("should parse the summaries of ptp4l and phc2sys", func() {
+		record, ok := parseLinuxPTPLine("2023-09-12T20:45:31.000120915Z ptp4l[357138.435]: [ptp4l.1.config] " +
+			"rms    2 max    4 freq  -3266 +/-   2 delay  1069 +/-   0")
+		Expect(ok).To(BeTrue())
+		Expect(record).To(Equal(&loglines.LinuxPTPSummary{
+			Timestamp:   "2023-09-12T20:45:31.000120915Z",
+			Process:     "ptp4l",
+			Config:      "ptp4l.1.config",
+			RMS:         2,
+			Max:         4,
+			Freq:        -3266,
+			FreqStdDev:  2,
+			Delay:       int64Ptr(1069),
+			DelayStdDev: int64Ptr(0),
+		}))
+
+		record, ok = parseLinuxPTPLine("2023-09-12T20:45:31.000120915Z phc2sys[357138.435]: " +
+			"CLOCK_REALTIME rms    4 max    8 freq -15378 +/-   3")
+		Expect(ok).To(BeTrue())
+
+		summary := record.(*loglines.LinuxPTPSummary)
+		Expect(summary.Clock).To(Equal("CLOCK_REALTIME"))
+		Expect(summary.Config).To(BeEmpty())
+		Expect(summary.Delay).To(BeNil())
+
+		formatted, err := record.GetAnalyserFormat()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(formatted[0].ID).To(Equal("phc2sys/summary"))
+	})
+
+	It("should skip other lines", func() {
+		for _, line := range []string{
+			"2023-09-12T20:45:31.000120915Z ts2phc[357138.435]: [ts2phc.0.config] nmea delay: 120642630 ns",
+			"2023-09-12T20:45:31.000120915Z ptp4l[357138.435]: [ptp4l.0.config] port 1: UNCALIBRATED to SLAVE",
+			"2023-09-12T20:45:31.000182560Z I0912 20:45:31.000148  161357 event.go:362] dpll State s2, gnss State s2",
+		} {
+			_, ok := parseLinuxPTPLine(line)
+			Expect(ok).To(BeFalse(), line)
+		}
+	})
+
+	It("should find the servo updates in a captured log", func() {
+		lines, err := loadLinesFromFile("test_files/all.log", 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		counts := make(map[string]int)
+
+		for _, line := range lines.Lines {
+			record, ok := loglines.ParseLinuxPTPLine(line)
+			if !ok {
+				continue
+			}
+
+			formatted, formatErr := record.GetAnalyserFormat()
+			Expect(formatErr).NotTo(HaveOccurred())
+
+			counts[formatted[0].ID]++
+		}
+
+		Expect(counts).To(Equal(map[string]int{"phc2sys/offset": 457, "ts2phc/offset": 29}))
+	})
+})
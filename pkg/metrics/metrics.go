@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Metrics exposes the latest collected values as prometheus gauges
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+const (
+	namespace         = "vse_sync"
+	metricsPath       = "/metrics"
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 5 * time.Second
+
+	PollSuccess = "success"
+	PollFailure = "failure"
+)
+
+// Sample is a single value which will be exposed as a gauge
+type Sample struct {
+	Labels map[string]string
+	Name   string
+	Help   string
+	Value  float64
+}
+
+// Provider is implemented by collected values which can be exposed as metrics
+type Provider interface {
+	GetMetrics() []*Sample
+}
+
+// Exporter keeps the latest value of every Sample it has
+// observed and serves them on an HTTP endpoint
+type Exporter struct {
+	registry *prometheus.Registry
+	gauges   map[string]*prometheus.GaugeVec
+	polls    *prometheus.CounterVec
+	server   *http.Server
+	mu       sync.Mutex
+}
+
+func NewExporter(address string) *Exporter {
+	registry := prometheus.NewRegistry()
+
+	polls := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "collector_polls_total",
+			Help:      "Number of polls made by each collector by target and result",
+		},
+		[]string{"collector", "node", "interface", "result"},
+	)
+	registry.MustRegister(polls)
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	return &Exporter{
+		registry: registry,
+		gauges:   make(map[string]*prometheus.GaugeVec),
+		polls:    polls,
+		server: &http.Server{
+			Addr:              address,
+			Handler:           mux,
+			ReadHeaderTimeout: readHeaderTimeout,
+		},
+	}
+}
+
+// Handler returns the handler which serves the metrics endpoint
+func (exp *Exporter) Handler() http.Handler {
+	return exp.server.Handler
+}
+
+// Start begins serving metrics, it returns once the listener is running
+func (exp *Exporter) Start() error {
+	errChan := make(chan error, 1)
+
+	go func() {
+		err := exp.server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errChan <- err
+		}
+	}()
+
+	// Give the listener a moment to fail so that a bad address is reported to the user
+	select {
+	case err := <-errChan:
+		return fmt.Errorf("failed to start metrics listener on %s: %w", exp.server.Addr, err)
+	case <-time.After(time.Second):
+	}
+
+	log.Infof("serving metrics on %s%s", exp.server.Addr, metricsPath)
+
+	return nil
+}
+
+// Stop shuts down the metrics listener
+func (exp *Exporter) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := exp.server.Shutdown(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to stop metrics listener: %w", err)
+	}
+
+	return nil
+}
+
+func labelNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+func (exp *Exporter) getGauge(sample *Sample) (*prometheus.GaugeVec, error) {
+	gauge, ok := exp.gauges[sample.Name]
+	if ok {
+		return gauge, nil
+	}
+
+	gauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      sample.Name,
+			Help:      sample.Help,
+		},
+		labelNames(sample.Labels),
+	)
+
+	err := exp.registry.Register(gauge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register metric %s: %w", sample.Name, err)
+	}
+
+	exp.gauges[sample.Name] = gauge
+
+	return gauge, nil
+}
+
+// Set updates the gauges with the values in samples
+func (exp *Exporter) Set(samples []*Sample) {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+
+	for _, sample := range samples {
+		gauge, err := exp.getGauge(sample)
+		if err != nil {
+			log.Warning(err)
+			continue
+		}
+
+		metric, err := gauge.GetMetricWith(sample.Labels)
+		if err != nil {
+			log.Warningf("failed to set metric %s: %s", sample.Name, err.Error())
+			continue
+		}
+
+		metric.Set(sample.Value)
+	}
+}
+
+// Observe updates the gauges from output if it provides metrics,
+// outputs labelled with a target have its node and interface added to their labels
+func (exp *Exporter) Observe(output callbacks.OutputType, _ string) {
+	targetLabels := map[string]string{}
+
+	if targeted, ok := output.(*callbacks.TargetedOutput); ok {
+		output = targeted.Output
+		targetLabels["node"] = targeted.Node
+		targetLabels["interface"] = targeted.Interface
+	}
+
+	provider, ok := output.(Provider)
+	if !ok {
+		return
+	}
+
+	samples := provider.GetMetrics()
+
+	if len(targetLabels) > 0 {
+		for _, sample := range samples {
+			labels := maps.Clone(targetLabels)
+			maps.Copy(labels, sample.Labels)
+			sample.Labels = labels
+		}
+	}
+
+	exp.Set(samples)
+}
+
+// RecordPoll counts the result of a poll for a collector on the node and interface of its target,
+// which are empty for collectors without a target
+func (exp *Exporter) RecordPoll(collectorName, node, iface string, errs []error) {
+	result := PollSuccess
+	if len(errs) > 0 {
+		result = PollFailure
+	}
+
+	exp.polls.WithLabelValues(collectorName, node, iface, result).Inc()
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package metrics_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
+)
+
+type testOutput struct {
+	offset float64
+}
+
+func (t *testOutput) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{}, nil
+}
+
+func (t *testOutput) GetMetrics() []*metrics.Sample {
+	return []*metrics.Sample{
+		{
+			Name:   "test_offset_ns",
+			Help:   "A test offset",
+			Labels: map[string]string{"pin": "GNSS-1PPS"},
+			Value:  t.offset,
+		},
+	}
+}
+
+type noMetricsOutput struct{}
+
+func (t *noMetricsOutput) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{}, nil
+}
+
+func scrape(exporter *metrics.Exporter) string {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody)
+	exporter.Handler().ServeHTTP(recorder, request)
+	Expect(recorder.Code).To(Equal(http.StatusOK))
+
+	return recorder.Body.String()
+}
+
+var _ = Describe("Exporter", func() {
+	var exporter *metrics.Exporter
+
+	BeforeEach(func() {
+		exporter = metrics.NewExporter("localhost:0")
+	})
+
+	When("an output which provides metrics is observed", func() {
+		It("should expose the latest value", func() {
+			exporter.Observe(&testOutput{offset: 1.5}, "test")
+			exporter.Observe(&testOutput{offset: -3}, "test")
+			exporter.Observe(&noMetricsOutput{}, "test")
+
+			body := scrape(exporter)
+			Expect(body).To(ContainSubstring(`vse_sync_test_offset_ns{pin="GNSS-1PPS"} -3`))
+		})
+	})
+
+	When("an output labelled with a target is observed", func() {
+		It("should add the node and interface labels", func() {
+			exporter.Observe(&callbacks.TargetedOutput{Output: &testOutput{offset: 2}, Node: "node-1", Interface: "ens1f0"}, "test")
+
+			body := scrape(exporter)
+			Expect(body).To(ContainSubstring(`vse_sync_test_offset_ns{interface="ens1f0",node="node-1",pin="GNSS-1PPS"} 2`))
+		})
+	})
+
+	When("polls are recorded", func() {
+		It("should count successes and failures per collector", func() {
+			exporter.RecordPoll("DPLL", "", "", nil)
+			exporter.RecordPoll("DPLL", "", "", []error{})
+			exporter.RecordPoll("DPLL", "", "", []error{errors.New("failed")})
+
+			body := scrape(exporter)
+			Expect(body).To(ContainSubstring(
+				`vse_sync_collector_polls_total{collector="DPLL",interface="",node="",result="success"} 2`))
+			Expect(body).To(ContainSubstring(
+				`vse_sync_collector_polls_total{collector="DPLL",interface="",node="",result="failure"} 1`))
+		})
+
+		It("should count the polls of each target separately", func() {
+			exporter.RecordPoll("DPLL", "node-1", "ens1f0", nil)
+			exporter.RecordPoll("DPLL", "node-2", "ens1f0", []error{errors.New("failed")})
+
+			body := scrape(exporter)
+			Expect(body).To(ContainSubstring(
+				`vse_sync_collector_polls_total{collector="DPLL",interface="ens1f0",node="node-1",result="success"} 1`))
+			Expect(body).To(ContainSubstring(
+				`vse_sync_collector_polls_total{collector="DPLL",interface="ens1f0",node="node-2",result="failure"} 1`))
+			Expect(body).NotTo(ContainSubstring(
+				`vse_sync_collector_polls_total{collector="DPLL",interface="ens1f0",node="node-1",result="failure"}`))
+		})
+	})
+})
+
+func TestMetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Metrics Suite")
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package ptpmgmt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrReceiveTimeout is returned by Conn.Receive when nothing arrived before its timeout
+	ErrReceiveTimeout = errors.New("timed out waiting for a management message")
+	// ErrManagementError is returned when ptp4l answers with a MANAGEMENT_ERROR_STATUS
+	ErrManagementError = errors.New("management error")
+)
+
+// Conn is a socket to a PTP clock's management port
+type Conn interface {
+	// Send writes a single management message
+	Send(data []byte) error
+	// Receive returns the next message, or ErrReceiveTimeout
+	Receive() ([]byte, error)
+	Close() error
+}
+
+// Response is the dataset of one port, or of the clock, from a RESPONSE
+type Response struct {
+	// Data is the decoded dataset, such as *CurrentDS, or the dataField as hex if its layout is not known
+	Data         any          `json:"data"`
+	PortIdentity string       `json:"portIdentity"`
+	ManagementID ManagementID `json:"managementId"`
+}
+
+// UnmarshalJSON decodes the data of a Response as the dataset of its management ID
+func (response *Response) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Data         json.RawMessage `json:"data"`
+		PortIdentity string          `json:"portIdentity"`
+		ManagementID ManagementID    `json:"managementId"`
+	}{}
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to decode management response: %w", err)
+	}
+
+	response.PortIdentity = raw.PortIdentity
+	response.ManagementID = raw.ManagementID
+
+	response.Data, err = decodeDataSetJSON(raw.ManagementID, raw.Data)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", raw.ManagementID, err)
+	}
+
+	return nil
+}
+
+// Client makes management requests over a Conn
+type Client struct {
+	conn         Conn
+	mu           sync.Mutex
+	sequence     uint16
+	domainNumber uint8
+}
+
+// NewClient returns a Client which makes requests in domainNumber over conn
+func NewClient(conn Conn, domainNumber uint8) *Client {
+	return &Client{conn: conn, domainNumber: domainNumber}
+}
+
+// Close closes the underlying Conn
+func (client *Client) Close() error {
+	return client.conn.Close() //nolint:wrapcheck // the error is returned as is to the caller
+}
+
+// Get returns the responses to a GET of id. A port scoped ID such as PORT_DATA_SET
+// has a response from every port, which are read until the Conn times out.
+func (client *Client) Get(id ManagementID) ([]*Response, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	client.sequence++
+	request := &Message{
+		TargetPortIdentity: AllPorts,
+		SequenceID:         client.sequence,
+		ManagementID:       id,
+		DomainNumber:       client.domainNumber,
+		Action:             ActionGet,
+	}
+
+	err := client.conn.Send(EncodeMessage(request))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send GET %s: %w", id, err)
+	}
+
+	responses := make([]*Response, 0)
+
+	for {
+		data, err := client.conn.Receive()
+		if errors.Is(err, ErrReceiveTimeout) && len(responses) > 0 {
+			return responses, nil
+		}
+
+		if err != nil {
+			return responses, fmt.Errorf("failed to receive response to GET %s: %w", id, err)
+		}
+
+		msg, err := ParseMessage(data)
+		if err != nil {
+			return responses, err
+		}
+
+		if msg.SequenceID != request.SequenceID || msg.Action != ActionResponse {
+			// a response to an earlier request which was abandoned
+			continue
+		}
+
+		if msg.ErrorStatus != 0 {
+			return responses, fmt.Errorf("%w %s for GET %s", ErrManagementError, msg.ErrorStatus, id)
+		}
+
+		dataSet, err := DecodeDataSet(msg.ManagementID, msg.Data)
+		if err != nil {
+			return responses, err
+		}
+
+		responses = append(responses, &Response{
+			Data:         dataSet,
+			PortIdentity: msg.SourcePortIdentity.String(),
+			ManagementID: msg.ManagementID,
+		})
+
+		if !id.IsPortScoped() {
+			return responses, nil
+		}
+	}
+}
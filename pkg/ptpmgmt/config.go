@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package ptpmgmt
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultUDSAddress is where ptp4l listens when its config does not set uds_address
+const DefaultUDSAddress = "/var/run/ptp4l"
+
+// Config is the part of a ptp4l config needed to talk to it
+type Config struct {
+	UDSAddress   string
+	DomainNumber uint8
+}
+
+// ParseConfig returns the uds_address and domainNumber from the [global] section of a ptp4l config
+func ParseConfig(config string) (*Config, error) {
+	parsed := &Config{UDSAddress: DefaultUDSAddress}
+	section := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(config))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[] ")
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if section != "global" || len(fields) != 2 { //nolint:mnd // an option and its value
+			continue
+		}
+
+		switch fields[0] {
+		case "uds_address":
+			parsed.UDSAddress = fields[1]
+		case "domainNumber":
+			domain, err := strconv.ParseUint(fields[1], 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse domainNumber: %w", err)
+			}
+
+			parsed.DomainNumber = uint8(domain)
+		}
+	}
+
+	return parsed, nil
+}
+
+// ReadConfig reads a ptp4l config file, see ParseConfig
+func ReadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return ParseConfig(string(data))
+}
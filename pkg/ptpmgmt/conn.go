@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package ptpmgmt
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// receiveBufferSize is larger than any management message
+	receiveBufferSize = 1500
+	// receiveTimeout is how long to wait for each response, as pmc does
+	receiveTimeout = 100 * time.Millisecond
+)
+
+// udsConn is a datagram socket bound next to ptp4l's so that ptp4l can reply to it
+type udsConn struct {
+	conn      *net.UnixConn
+	server    *net.UnixAddr
+	buffer    []byte
+	localPath string
+}
+
+// DialUDS returns a Client for the ptp4l listening on the UNIX socket at serverPath
+func DialUDS(serverPath string, domainNumber uint8) (*Client, error) {
+	localPath := filepath.Join(filepath.Dir(serverPath), fmt.Sprintf("vse-pmc.%d", os.Getpid()))
+	// a socket left by an earlier process with the same pid would stop the bind
+	_ = os.Remove(localPath)
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: localPath, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind %s: %w", localPath, err)
+	}
+
+	return NewClient(&udsConn{
+		conn:      conn,
+		server:    &net.UnixAddr{Name: serverPath, Net: "unixgram"},
+		buffer:    make([]byte, receiveBufferSize),
+		localPath: localPath,
+	}, domainNumber), nil
+}
+
+func (conn *udsConn) Send(data []byte) error {
+	_, err := conn.conn.WriteToUnix(data, conn.server)
+
+	return err //nolint:wrapcheck // wrapped by the client
+}
+
+func (conn *udsConn) Receive() ([]byte, error) {
+	err := conn.conn.SetReadDeadline(time.Now().Add(receiveTimeout))
+	if err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by the client
+	}
+
+	n, _, err := conn.conn.ReadFromUnix(conn.buffer)
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return nil, ErrReceiveTimeout
+	}
+
+	if err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by the client
+	}
+
+	data := make([]byte, n)
+	copy(data, conn.buffer[:n])
+
+	return data, nil
+}
+
+func (conn *udsConn) Close() error {
+	err := conn.conn.Close()
+	_ = os.Remove(conn.localPath)
+
+	return err //nolint:wrapcheck // the error is returned as is to the caller
+}
@@ -0,0 +1,343 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package ptpmgmt
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// The json names are those pmc prints so the output can be compared with it
+
+const (
+	// TimeInterval is nanoseconds scaled by 2^16
+	timeIntervalScale = 1 << 16
+	// cumulativeScaledRateOffset is the rate ratio minus one scaled by 2^41
+	rateOffsetScale = 1 << 41
+
+	flagLeap61         = 1 << 0
+	flagLeap59         = 1 << 1
+	flagUTCOffsetValid = 1 << 2
+	flagPTPTimescale   = 1 << 3
+	flagTimeTraceable  = 1 << 4
+	flagFreqTraceable  = 1 << 5
+
+	flagTwoStep   = 1 << 0
+	flagSlaveOnly = 1 << 1
+
+	versionNumberMask = 0x0f
+	// maxDataSetLength is longer than any of the datasets with a known layout
+	maxDataSetLength = 64
+)
+
+// portStates are the values of the IEEE 1588 portState enumeration, linuxptp adds GRAND_MASTER
+var portStates = []string{
+	"NONE", "INITIALIZING", "FAULTY", "DISABLED", "LISTENING", "PRE_MASTER",
+	"MASTER", "PASSIVE", "UNCALIBRATED", "SLAVE", "GRAND_MASTER",
+}
+
+// ClockQuality is the class, accuracy and variance of a clock
+type ClockQuality struct {
+	ClockClass              uint8  `json:"clockClass"`
+	ClockAccuracy           uint8  `json:"clockAccuracy"`
+	OffsetScaledLogVariance uint16 `json:"offsetScaledLogVariance"`
+}
+
+// TimeProperties are the flags and UTC offset of the time a clock distributes
+type TimeProperties struct {
+	CurrentUtcOffset      int16 `json:"currentUtcOffset"`
+	Leap61                bool  `json:"leap61"`
+	Leap59                bool  `json:"leap59"`
+	CurrentUtcOffsetValid bool  `json:"currentUtcOffsetValid"`
+	PtpTimescale          bool  `json:"ptpTimescale"`
+	TimeTraceable         bool  `json:"timeTraceable"`
+	FrequencyTraceable    bool  `json:"frequencyTraceable"`
+	TimeSource            uint8 `json:"timeSource"`
+}
+
+// DefaultDS is the response to DEFAULT_DATA_SET
+type DefaultDS struct {
+	ClockIdentity string       `json:"clockIdentity"`
+	ClockQuality  ClockQuality `json:"clockQuality"`
+	NumberPorts   uint16       `json:"numberPorts"`
+	TwoStepFlag   bool         `json:"twoStepFlag"`
+	SlaveOnly     bool         `json:"slaveOnly"`
+	Priority1     uint8        `json:"priority1"`
+	Priority2     uint8        `json:"priority2"`
+	DomainNumber  uint8        `json:"domainNumber"`
+}
+
+// CurrentDS is the response to CURRENT_DATA_SET, the offset and delay are in nanoseconds
+type CurrentDS struct {
+	OffsetFromMaster float64 `json:"offsetFromMaster"`
+	MeanPathDelay    float64 `json:"meanPathDelay"`
+	StepsRemoved     uint16  `json:"stepsRemoved"`
+}
+
+// ParentDS is the response to PARENT_DATA_SET
+type ParentDS struct {
+	ParentPortIdentity                    string       `json:"parentPortIdentity"`
+	GrandmasterIdentity                   string       `json:"grandmasterIdentity"`
+	GrandmasterClockQuality               ClockQuality `json:"grandmasterClockQuality"`
+	ObservedParentClockPhaseChangeRate    int32        `json:"observedParentClockPhaseChangeRate"`
+	ObservedParentOffsetScaledLogVariance uint16       `json:"observedParentOffsetScaledLogVariance"`
+	ParentStats                           uint8        `json:"parentStats"`
+	GrandmasterPriority1                  uint8        `json:"grandmasterPriority1"`
+	GrandmasterPriority2                  uint8        `json:"grandmasterPriority2"`
+}
+
+// TimePropertiesDS is the response to TIME_PROPERTIES_DATA_SET
+type TimePropertiesDS struct {
+	TimeProperties
+}
+
+// PortDS is the response of one port to PORT_DATA_SET, the delay is in nanoseconds
+type PortDS struct {
+	PortIdentity            string  `json:"portIdentity"`
+	PortState               string  `json:"portState"`
+	PeerMeanPathDelay       float64 `json:"peerMeanPathDelay"`
+	LogMinDelayReqInterval  int8    `json:"logMinDelayReqInterval"`
+	LogAnnounceInterval     int8    `json:"logAnnounceInterval"`
+	AnnounceReceiptTimeout  uint8   `json:"announceReceiptTimeout"`
+	LogSyncInterval         int8    `json:"logSyncInterval"`
+	DelayMechanism          uint8   `json:"delayMechanism"`
+	LogMinPdelayReqInterval int8    `json:"logMinPdelayReqInterval"`
+	VersionNumber           uint8   `json:"versionNumber"`
+}
+
+// TimeStatus is the response to TIME_STATUS_NP
+//
+//nolint:tagliatelle // the names are those pmc prints
+type TimeStatus struct {
+	GMIdentity                 string  `json:"gmIdentity"`
+	LastGmPhaseChange          string  `json:"lastGmPhaseChange"`
+	MasterOffset               int64   `json:"master_offset"`
+	IngressTime                int64   `json:"ingress_time"`
+	CumulativeScaledRateOffset float64 `json:"cumulativeScaledRateOffset"`
+	ScaledLastGmPhaseChange    int32   `json:"scaledLastGmPhaseChange"`
+	GMTimeBaseIndicator        uint16  `json:"gmTimeBaseIndicator"`
+	GMPresent                  bool    `json:"gmPresent"`
+}
+
+// GrandmasterSettings is the response to GRANDMASTER_SETTINGS_NP
+type GrandmasterSettings struct {
+	ClockQuality
+	TimeProperties
+}
+
+// fieldReader reads the fields of a dataset in order, keeping the first error
+type fieldReader struct {
+	err  error
+	data []byte
+}
+
+func (reader *fieldReader) next(length int) []byte {
+	if len(reader.data) < length {
+		if reader.err == nil {
+			reader.err = ErrTruncatedMessage
+		}
+
+		return make([]byte, length)
+	}
+
+	field := reader.data[:length]
+	reader.data = reader.data[length:]
+
+	return field
+}
+
+func (reader *fieldReader) uint8() uint8 {
+	return reader.next(1)[0]
+}
+
+func (reader *fieldReader) int8() int8 {
+	return int8(reader.uint8()) //nolint:gosec // the field is signed
+}
+
+func (reader *fieldReader) uint16() uint16 {
+	return byteOrder.Uint16(reader.next(2)) //nolint:mnd // the size of the field
+}
+
+func (reader *fieldReader) int16() int16 {
+	return int16(reader.uint16()) //nolint:gosec // the field is signed
+}
+
+func (reader *fieldReader) uint32() uint32 {
+	return byteOrder.Uint32(reader.next(4)) //nolint:mnd // the size of the field
+}
+
+func (reader *fieldReader) int32() int32 {
+	return int32(reader.uint32()) //nolint:gosec // the field is signed
+}
+
+func (reader *fieldReader) uint64() uint64 {
+	return byteOrder.Uint64(reader.next(8)) //nolint:mnd // the size of the field
+}
+
+func (reader *fieldReader) int64() int64 {
+	return int64(reader.uint64()) //nolint:gosec // the field is signed
+}
+
+func (reader *fieldReader) timeInterval() float64 {
+	return float64(reader.int64()) / timeIntervalScale
+}
+
+func (reader *fieldReader) clockIdentity() string {
+	identity := ClockIdentity{}
+	copy(identity[:], reader.next(len(identity)))
+
+	return identity.String()
+}
+
+func (reader *fieldReader) portIdentity() string {
+	return decodePortIdentity(reader.next(10)).String() //nolint:mnd // the clock identity and port number
+}
+
+func (reader *fieldReader) clockQuality() ClockQuality {
+	return ClockQuality{
+		ClockClass:              reader.uint8(),
+		ClockAccuracy:           reader.uint8(),
+		OffsetScaledLogVariance: reader.uint16(),
+	}
+}
+
+func (reader *fieldReader) timeProperties() TimeProperties {
+	properties := TimeProperties{CurrentUtcOffset: reader.int16()}
+	flags := reader.uint8()
+	properties.Leap61 = flags&flagLeap61 != 0
+	properties.Leap59 = flags&flagLeap59 != 0
+	properties.CurrentUtcOffsetValid = flags&flagUTCOffsetValid != 0
+	properties.PtpTimescale = flags&flagPTPTimescale != 0
+	properties.TimeTraceable = flags&flagTimeTraceable != 0
+	properties.FrequencyTraceable = flags&flagFreqTraceable != 0
+	properties.TimeSource = reader.uint8()
+
+	return properties
+}
+
+func portStateName(state uint8) string {
+	if int(state) < len(portStates) {
+		return portStates[state]
+	}
+
+	return fmt.Sprintf("STATE_%d", state)
+}
+
+type dataSetDecoder func(reader *fieldReader) any
+
+// dataSetDecoders decode the dataField of the management IDs whose layout is known,
+// the data of any other ID is kept as hex
+var dataSetDecoders = map[ManagementID]dataSetDecoder{
+	DefaultDataSet: func(reader *fieldReader) any {
+		flags := reader.uint8()
+		reader.uint8()
+
+		return &DefaultDS{
+			TwoStepFlag:   flags&flagTwoStep != 0,
+			SlaveOnly:     flags&flagSlaveOnly != 0,
+			NumberPorts:   reader.uint16(),
+			Priority1:     reader.uint8(),
+			ClockQuality:  reader.clockQuality(),
+			Priority2:     reader.uint8(),
+			ClockIdentity: reader.clockIdentity(),
+			DomainNumber:  reader.uint8(),
+		}
+	},
+	CurrentDataSet: func(reader *fieldReader) any {
+		return &CurrentDS{
+			StepsRemoved:     reader.uint16(),
+			OffsetFromMaster: reader.timeInterval(),
+			MeanPathDelay:    reader.timeInterval(),
+		}
+	},
+	ParentDataSet: func(reader *fieldReader) any {
+		parent := &ParentDS{
+			ParentPortIdentity: reader.portIdentity(),
+			ParentStats:        reader.uint8(),
+		}
+		reader.uint8()
+		parent.ObservedParentOffsetScaledLogVariance = reader.uint16()
+		parent.ObservedParentClockPhaseChangeRate = reader.int32()
+		parent.GrandmasterPriority1 = reader.uint8()
+		parent.GrandmasterClockQuality = reader.clockQuality()
+		parent.GrandmasterPriority2 = reader.uint8()
+		parent.GrandmasterIdentity = reader.clockIdentity()
+
+		return parent
+	},
+	TimePropertiesDataSet: func(reader *fieldReader) any {
+		return &TimePropertiesDS{TimeProperties: reader.timeProperties()}
+	},
+	PortDataSet: func(reader *fieldReader) any {
+		return &PortDS{
+			PortIdentity:            reader.portIdentity(),
+			PortState:               portStateName(reader.uint8()),
+			LogMinDelayReqInterval:  reader.int8(),
+			PeerMeanPathDelay:       reader.timeInterval(),
+			LogAnnounceInterval:     reader.int8(),
+			AnnounceReceiptTimeout:  reader.uint8(),
+			LogSyncInterval:         reader.int8(),
+			DelayMechanism:          reader.uint8(),
+			LogMinPdelayReqInterval: reader.int8(),
+			VersionNumber:           reader.uint8() & versionNumberMask,
+		}
+	},
+	TimeStatusNP: func(reader *fieldReader) any {
+		timeStatus := &TimeStatus{
+			MasterOffset:               reader.int64(),
+			IngressTime:                reader.int64(),
+			CumulativeScaledRateOffset: float64(reader.int32()) / rateOffsetScale,
+			ScaledLastGmPhaseChange:    reader.int32(),
+			GMTimeBaseIndicator:        reader.uint16(),
+		}
+		// a ScaledNs, formatted as pmc does
+		msb, lsb, fractional := reader.uint16(), reader.uint64(), reader.uint16()
+		timeStatus.LastGmPhaseChange = fmt.Sprintf("0x%04x'%016x.%04x", msb, lsb, fractional)
+		timeStatus.GMPresent = reader.int32() != 0
+		timeStatus.GMIdentity = reader.clockIdentity()
+
+		return timeStatus
+	},
+	GrandmasterSettingsNP: func(reader *fieldReader) any {
+		return &GrandmasterSettings{
+			ClockQuality:   reader.clockQuality(),
+			TimeProperties: reader.timeProperties(),
+		}
+	},
+}
+
+// DecodeDataSet returns the dataField of id as its dataset type, such as *CurrentDS for CURRENT_DATA_SET,
+// or as a hex string if its layout is not known
+func DecodeDataSet(id ManagementID, data []byte) (any, error) {
+	decoder, ok := dataSetDecoders[id]
+	if !ok {
+		return hex.EncodeToString(data), nil
+	}
+
+	reader := &fieldReader{data: data}
+	dataSet := decoder(reader)
+
+	if reader.err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", id, reader.err)
+	}
+
+	return dataSet, nil
+}
+
+// decodeDataSetJSON is the inverse of marshalling a dataset decoded by DecodeDataSet
+func decodeDataSetJSON(id ManagementID, data json.RawMessage) (any, error) {
+	decoder, ok := dataSetDecoders[id]
+	if !ok {
+		var raw string
+		err := json.Unmarshal(data, &raw)
+
+		return raw, err //nolint:wrapcheck // wrapped by the caller
+	}
+
+	// decoding empty data gives a value of the dataset's type to unmarshal into
+	dataSet := decoder(&fieldReader{data: make([]byte, maxDataSetLength)})
+	err := json.Unmarshal(data, dataSet)
+
+	return dataSet, err //nolint:wrapcheck // wrapped by the caller
+}
@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package ptpmgmt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ManagementID identifies the dataset or command of a management TLV
+type ManagementID uint16
+
+// The IDs from IEEE 1588 and the linuxptp implementation specific (_NP) ones
+const (
+	NullManagement             ManagementID = 0x0000
+	ClockDescription           ManagementID = 0x0001
+	UserDescription            ManagementID = 0x0002
+	DefaultDataSet             ManagementID = 0x2000
+	CurrentDataSet             ManagementID = 0x2001
+	ParentDataSet              ManagementID = 0x2002
+	TimePropertiesDataSet      ManagementID = 0x2003
+	PortDataSet                ManagementID = 0x2004
+	Priority1                  ManagementID = 0x2005
+	Priority2                  ManagementID = 0x2006
+	Domain                     ManagementID = 0x2007
+	SlaveOnly                  ManagementID = 0x2008
+	LogAnnounceInterval        ManagementID = 0x2009
+	AnnounceReceiptTimeout     ManagementID = 0x200a
+	LogSyncInterval            ManagementID = 0x200b
+	VersionNumber              ManagementID = 0x200c
+	Time                       ManagementID = 0x200f
+	ClockAccuracy              ManagementID = 0x2010
+	UTCProperties              ManagementID = 0x2011
+	TraceabilityProperties     ManagementID = 0x2012
+	TimescaleProperties        ManagementID = 0x2013
+	MasterOnly                 ManagementID = 0x3001
+	DelayMechanism             ManagementID = 0x6000
+	LogMinPdelayReqInterval    ManagementID = 0x6001
+	TimeStatusNP               ManagementID = 0xc000
+	GrandmasterSettingsNP      ManagementID = 0xc001
+	PortDataSetNP              ManagementID = 0xc002
+	SubscribeEventsNP          ManagementID = 0xc003
+	PortPropertiesNP           ManagementID = 0xc004
+	PortStatsNP                ManagementID = 0xc005
+	SynchronizationUncertainNP ManagementID = 0xc006
+	PortServiceStatsNP         ManagementID = 0xc007
+	UnicastMasterTableNP       ManagementID = 0xc008
+	PortHWClockNP              ManagementID = 0xc009
+	PowerProfileSettingsNP     ManagementID = 0xc00a
+)
+
+// managementIDs are named as pmc names them
+var managementIDs = map[ManagementID]string{
+	NullManagement:             "NULL_MANAGEMENT",
+	ClockDescription:           "CLOCK_DESCRIPTION",
+	UserDescription:            "USER_DESCRIPTION",
+	DefaultDataSet:             "DEFAULT_DATA_SET",
+	CurrentDataSet:             "CURRENT_DATA_SET",
+	ParentDataSet:              "PARENT_DATA_SET",
+	TimePropertiesDataSet:      "TIME_PROPERTIES_DATA_SET",
+	PortDataSet:                "PORT_DATA_SET",
+	Priority1:                  "PRIORITY1",
+	Priority2:                  "PRIORITY2",
+	Domain:                     "DOMAIN",
+	SlaveOnly:                  "SLAVE_ONLY",
+	LogAnnounceInterval:        "LOG_ANNOUNCE_INTERVAL",
+	AnnounceReceiptTimeout:     "ANNOUNCE_RECEIPT_TIMEOUT",
+	LogSyncInterval:            "LOG_SYNC_INTERVAL",
+	VersionNumber:              "VERSION_NUMBER",
+	Time:                       "TIME",
+	ClockAccuracy:              "CLOCK_ACCURACY",
+	UTCProperties:              "UTC_PROPERTIES",
+	TraceabilityProperties:     "TRACEABILITY_PROPERTIES",
+	TimescaleProperties:        "TIMESCALE_PROPERTIES",
+	MasterOnly:                 "MASTER_ONLY",
+	DelayMechanism:             "DELAY_MECHANISM",
+	LogMinPdelayReqInterval:    "LOG_MIN_PDELAY_REQ_INTERVAL",
+	TimeStatusNP:               "TIME_STATUS_NP",
+	GrandmasterSettingsNP:      "GRANDMASTER_SETTINGS_NP",
+	PortDataSetNP:              "PORT_DATA_SET_NP",
+	SubscribeEventsNP:          "SUBSCRIBE_EVENTS_NP",
+	PortPropertiesNP:           "PORT_PROPERTIES_NP",
+	PortStatsNP:                "PORT_STATS_NP",
+	SynchronizationUncertainNP: "SYNCHRONIZATION_UNCERTAIN_NP",
+	PortServiceStatsNP:         "PORT_SERVICE_STATS_NP",
+	UnicastMasterTableNP:       "UNICAST_MASTER_TABLE_NP",
+	PortHWClockNP:              "PORT_HWCLOCK_NP",
+	PowerProfileSettingsNP:     "POWER_PROFILE_SETTINGS_NP",
+}
+
+// portManagementIDs are answered by every port rather than once by the clock
+var portManagementIDs = map[ManagementID]bool{
+	NullManagement:          true,
+	PortDataSet:             true,
+	LogAnnounceInterval:     true,
+	AnnounceReceiptTimeout:  true,
+	LogSyncInterval:         true,
+	VersionNumber:           true,
+	MasterOnly:              true,
+	DelayMechanism:          true,
+	LogMinPdelayReqInterval: true,
+	PortDataSetNP:           true,
+	PortPropertiesNP:        true,
+	PortStatsNP:             true,
+	PortServiceStatsNP:      true,
+	UnicastMasterTableNP:    true,
+	PortHWClockNP:           true,
+}
+
+func (id ManagementID) String() string {
+	if name, ok := managementIDs[id]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("0x%04x", uint16(id))
+}
+
+// IsPortScoped returns true if each port answers a request for the ID
+func (id ManagementID) IsPortScoped() bool {
+	return portManagementIDs[id]
+}
+
+// ParseManagementID returns the ID with the name pmc uses, or the ID given as a number such as 0xc001
+func ParseManagementID(name string) (ManagementID, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+
+	for id, idName := range managementIDs {
+		if idName == name {
+			return id, nil
+		}
+	}
+
+	var id uint16
+
+	_, err := fmt.Sscanf(strings.ToLower(name), "0x%x", &id)
+	if err != nil {
+		return 0, fmt.Errorf("unknown management ID %s", name)
+	}
+
+	return ManagementID(id), nil
+}
+
+// ErrorStatus is the managementErrorId of a MANAGEMENT_ERROR_STATUS TLV
+type ErrorStatus uint16
+
+const (
+	ErrorResponseTooBig ErrorStatus = 0x0001
+	ErrorNoSuchID       ErrorStatus = 0x0002
+	ErrorWrongLength    ErrorStatus = 0x0003
+	ErrorWrongValue     ErrorStatus = 0x0004
+	ErrorNotSetable     ErrorStatus = 0x0005
+	ErrorNotSupported   ErrorStatus = 0x0006
+	ErrorGeneralError   ErrorStatus = 0xfffe
+)
+
+var errorStatuses = map[ErrorStatus]string{
+	ErrorResponseTooBig: "RESPONSE_TOO_BIG",
+	ErrorNoSuchID:       "NO_SUCH_ID",
+	ErrorWrongLength:    "WRONG_LENGTH",
+	ErrorWrongValue:     "WRONG_VALUE",
+	ErrorNotSetable:     "NOT_SETABLE",
+	ErrorNotSupported:   "NOT_SUPPORTED",
+	ErrorGeneralError:   "GENERAL_ERROR",
+}
+
+func (status ErrorStatus) String() string {
+	if name, ok := errorStatuses[status]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("0x%04x", uint16(status))
+}
+
+// MarshalText names the ID as pmc does
+func (id ManagementID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText parses the name or number of an ID
+func (id *ManagementID) UnmarshalText(text []byte) error {
+	parsed, err := ParseManagementID(string(text))
+	if err != nil {
+		return err
+	}
+
+	*id = parsed
+
+	return nil
+}
@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Package ptpmgmt encodes and decodes IEEE 1588 management messages, as sent by pmc to ptp4l over its UNIX socket
+package ptpmgmt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// the common header of every PTP message
+	headerLength = 34
+	// the target port identity, boundary hops and action of a management message
+	managementLength   = 14
+	tlvHeaderLength    = 4
+	managementIDLength = 2
+
+	messageTypeManagement = 0xd
+	versionPTP            = 2
+	controlManagement     = 0x04
+	logIntervalUnused     = 0x7f
+
+	tlvManagement            = 0x0001
+	tlvManagementErrorStatus = 0x0002
+
+	actionMask = 0x0f
+	// errorStatusLength is the error ID, the management ID and four reserved bytes before the display data
+	errorStatusLength = 8
+)
+
+// Action is the actionField of a management message
+type Action uint8
+
+const (
+	ActionGet Action = iota
+	ActionSet
+	ActionResponse
+	ActionCommand
+	ActionAcknowledge
+)
+
+var actionNames = map[Action]string{
+	ActionGet:         "GET",
+	ActionSet:         "SET",
+	ActionResponse:    "RESPONSE",
+	ActionCommand:     "COMMAND",
+	ActionAcknowledge: "ACKNOWLEDGE",
+}
+
+func (action Action) String() string {
+	if name, ok := actionNames[action]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("ACTION_%d", uint8(action))
+}
+
+var (
+	// PTP messages are in network byte order
+	byteOrder = binary.BigEndian
+
+	ErrTruncatedMessage = errors.New("truncated PTP management message")
+	ErrNotManagement    = errors.New("not a PTP management message")
+)
+
+// ClockIdentity is the EUI-64 of a PTP clock
+type ClockIdentity [8]byte
+
+// String formats the identity as pmc does, such as 507c6f.fffe.30fbe8
+func (identity ClockIdentity) String() string {
+	return fmt.Sprintf("%02x%02x%02x.%02x%02x.%02x%02x%02x",
+		identity[0], identity[1], identity[2], identity[3], identity[4], identity[5], identity[6], identity[7])
+}
+
+// PortIdentity is a port of a PTP clock
+type PortIdentity struct {
+	ClockIdentity ClockIdentity
+	PortNumber    uint16
+}
+
+// AllPorts is the wildcard target which every port of the clock answers
+var AllPorts = PortIdentity{
+	ClockIdentity: ClockIdentity{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	PortNumber:    0xffff,
+}
+
+// String formats the identity as pmc does, such as 507c6f.fffe.30fbe8-1
+func (identity PortIdentity) String() string {
+	return fmt.Sprintf("%s-%d", identity.ClockIdentity, identity.PortNumber)
+}
+
+func decodePortIdentity(data []byte) PortIdentity {
+	identity := PortIdentity{PortNumber: byteOrder.Uint16(data[8:])}
+	copy(identity.ClockIdentity[:], data)
+
+	return identity
+}
+
+func encodePortIdentity(data []byte, identity PortIdentity) {
+	copy(data, identity.ClockIdentity[:])
+	byteOrder.PutUint16(data[8:], identity.PortNumber)
+}
+
+// Message is a management message with a single management or error status TLV
+type Message struct {
+	// Data is the dataField of the TLV, empty for an error status
+	Data               []byte
+	SourcePortIdentity PortIdentity
+	TargetPortIdentity PortIdentity
+	// ErrorStatus is the managementErrorId of an error status TLV, zero otherwise
+	ErrorStatus  ErrorStatus
+	SequenceID   uint16
+	ManagementID ManagementID
+	DomainNumber uint8
+	Action       Action
+}
+
+// EncodeMessage lays out msg as it is sent on the wire
+func EncodeMessage(msg *Message) []byte {
+	tlvOffset := headerLength + managementLength
+	length := tlvOffset + tlvHeaderLength + managementIDLength + len(msg.Data)
+	data := make([]byte, length)
+
+	data[0] = messageTypeManagement
+	data[1] = versionPTP
+	byteOrder.PutUint16(data[2:], uint16(length)) //nolint:gosec // a management message is far smaller than 64k
+	data[4] = msg.DomainNumber
+	encodePortIdentity(data[20:], msg.SourcePortIdentity)
+	byteOrder.PutUint16(data[30:], msg.SequenceID)
+	data[32] = controlManagement
+	data[33] = logIntervalUnused
+
+	encodePortIdentity(data[headerLength:], msg.TargetPortIdentity)
+	data[headerLength+12] = uint8(msg.Action) & actionMask
+
+	byteOrder.PutUint16(data[tlvOffset:], tlvManagement)
+	byteOrder.PutUint16(data[tlvOffset+2:], uint16(managementIDLength+len(msg.Data))) //nolint:gosec // as above
+	byteOrder.PutUint16(data[tlvOffset+4:], uint16(msg.ManagementID))
+	copy(data[tlvOffset+tlvHeaderLength+managementIDLength:], msg.Data)
+
+	return data
+}
+
+// ParseMessage decodes a management message and its first TLV
+func ParseMessage(data []byte) (*Message, error) {
+	if len(data) < headerLength+managementLength+tlvHeaderLength {
+		return nil, ErrTruncatedMessage
+	}
+
+	if data[0]&0x0f != messageTypeManagement {
+		return nil, ErrNotManagement
+	}
+
+	length := int(byteOrder.Uint16(data[2:]))
+	if length > len(data) || length < headerLength+managementLength+tlvHeaderLength {
+		return nil, ErrTruncatedMessage
+	}
+
+	data = data[:length]
+	msg := &Message{
+		DomainNumber:       data[4],
+		SourcePortIdentity: decodePortIdentity(data[20:]),
+		SequenceID:         byteOrder.Uint16(data[30:]),
+		TargetPortIdentity: decodePortIdentity(data[headerLength:]),
+		Action:             Action(data[headerLength+12] & actionMask),
+	}
+
+	tlv := data[headerLength+managementLength:]
+	tlvType := byteOrder.Uint16(tlv)
+	tlvLength := int(byteOrder.Uint16(tlv[2:]))
+
+	tlv = tlv[tlvHeaderLength:]
+	if tlvLength > len(tlv) {
+		return nil, ErrTruncatedMessage
+	}
+
+	tlv = tlv[:tlvLength]
+
+	switch tlvType {
+	case tlvManagement:
+		if tlvLength < managementIDLength {
+			return nil, ErrTruncatedMessage
+		}
+
+		msg.ManagementID = ManagementID(byteOrder.Uint16(tlv))
+		msg.Data = tlv[managementIDLength:]
+	case tlvManagementErrorStatus:
+		if tlvLength < errorStatusLength {
+			return nil, ErrTruncatedMessage
+		}
+
+		msg.ErrorStatus = ErrorStatus(byteOrder.Uint16(tlv))
+		msg.ManagementID = ManagementID(byteOrder.Uint16(tlv[2:]))
+	default:
+		return nil, fmt.Errorf("unexpected TLV type 0x%04x", tlvType)
+	}
+
+	return msg, nil
+}
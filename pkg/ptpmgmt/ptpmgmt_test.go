@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package ptpmgmt_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/ptpmgmt"
+)
+
+// fakeConn replays messages laid out as ptp4l sends them and keeps the requests it is sent
+type fakeConn struct {
+	sent     [][]byte
+	messages [][]byte
+}
+
+func newFakeConn(fixtures ...string) *fakeConn {
+	conn := &fakeConn{}
+
+	for _, fixture := range fixtures {
+		conn.messages = append(conn.messages, readFixture(fixture))
+	}
+
+	return conn
+}
+
+func readFixture(fixture string) []byte {
+	data, err := os.ReadFile("test_files/" + fixture)
+	Expect(err).NotTo(HaveOccurred())
+
+	return data
+}
+
+func (conn *fakeConn) Send(data []byte) error {
+	conn.sent = append(conn.sent, data)
+	return nil
+}
+
+func (conn *fakeConn) Receive() ([]byte, error) {
+	if len(conn.messages) == 0 {
+		return nil, ptpmgmt.ErrReceiveTimeout
+	}
+
+	data := conn.messages[0]
+	conn.messages = conn.messages[1:]
+
+	return data, nil
+}
+
+func (conn *fakeConn) Close() error {
+	return nil
+}
+
+var _ = Describe("Messages", func() {
+	It("should encode a GET as pmc does", func() {
+		data := ptpmgmt.EncodeMessage(&ptpmgmt.Message{
+			TargetPortIdentity: ptpmgmt.AllPorts,
+			SequenceID:         1,
+			ManagementID:       ptpmgmt.CurrentDataSet,
+			Action:             ptpmgmt.ActionGet,
+		})
+		Expect(data).To(Equal(readFixture("get_current_data_set.bin")))
+
+		msg, err := ptpmgmt.ParseMessage(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg.ManagementID).To(Equal(ptpmgmt.CurrentDataSet))
+		Expect(msg.Action).To(Equal(ptpmgmt.ActionGet))
+		Expect(msg.TargetPortIdentity).To(Equal(ptpmgmt.AllPorts))
+		Expect(msg.Data).To(BeEmpty())
+	})
+
+	It("should parse an error status", func() {
+		msg, err := ptpmgmt.ParseMessage(readFixture("error_not_supported.bin"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg.ErrorStatus).To(Equal(ptpmgmt.ErrorNotSupported))
+		Expect(msg.ManagementID).To(Equal(ptpmgmt.ClockDescription))
+	})
+
+	It("should reject a truncated message", func() {
+		data := readFixture("current_data_set.bin")
+		_, err := ptpmgmt.ParseMessage(data[:len(data)-4])
+		Expect(err).To(MatchError(ptpmgmt.ErrTruncatedMessage))
+	})
+
+	It("should name management IDs as pmc does", func() {
+		id, err := ptpmgmt.ParseManagementID("time_status_np")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal(ptpmgmt.TimeStatusNP))
+
+		id, err = ptpmgmt.ParseManagementID("0xC0FF")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id.String()).To(Equal("0xc0ff"))
+
+		_, err = ptpmgmt.ParseManagementID("NOT_AN_ID")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Client", func() {
+	It("should decode the dataset of the clock", func() {
+		conn := newFakeConn("current_data_set.bin")
+		responses, err := ptpmgmt.NewClient(conn, 0).Get(ptpmgmt.CurrentDataSet)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.sent).To(Equal([][]byte{readFixture("get_current_data_set.bin")}))
+
+		Expect(responses).To(HaveLen(1))
+		Expect(responses[0].PortIdentity).To(Equal("507c6f.fffe.30fbe8-0"))
+		Expect(responses[0].Data).To(Equal(&ptpmgmt.CurrentDS{
+			StepsRemoved:     1,
+			OffsetFromMaster: -2.5,
+			MeanPathDelay:    112,
+		}))
+	})
+
+	It("should decode the dataset of every port", func() {
+		conn := newFakeConn("port_data_set_1.bin", "port_data_set_2.bin")
+		responses, err := ptpmgmt.NewClient(conn, 0).Get(ptpmgmt.PortDataSet)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(responses).To(HaveLen(2))
+
+		Expect(responses[0].Data).To(Equal(&ptpmgmt.PortDS{
+			PortIdentity:           "507c6f.fffe.30fbe8-1",
+			PortState:              "SLAVE",
+			LogMinDelayReqInterval: -4,
+			LogAnnounceInterval:    1,
+			AnnounceReceiptTimeout: 3,
+			LogSyncInterval:        -4,
+			DelayMechanism:         1,
+			VersionNumber:          2,
+		}))
+		Expect(responses[1].Data.(*ptpmgmt.PortDS).PortState).To(Equal("MASTER"))
+	})
+
+	It("should decode the parent dataset", func() {
+		responses, err := ptpmgmt.NewClient(newFakeConn("parent_data_set.bin"), 0).Get(ptpmgmt.ParentDataSet)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(responses[0].Data).To(Equal(&ptpmgmt.ParentDS{
+			ParentPortIdentity:  "ec4670.fffe.0a7fe1-1",
+			GrandmasterIdentity: "507c6f.fffe.1fb1a8",
+			GrandmasterClockQuality: ptpmgmt.ClockQuality{
+				ClockClass: 6, ClockAccuracy: 0x21, OffsetScaledLogVariance: 0x4e5d,
+			},
+			ObservedParentClockPhaseChangeRate:    0x7fffffff,
+			ObservedParentOffsetScaledLogVariance: 0xffff,
+			GrandmasterPriority1:                  128,
+			GrandmasterPriority2:                  128,
+		}))
+	})
+
+	It("should decode the linuxptp time status and grandmaster settings", func() {
+		conn := newFakeConn("time_status_np.bin")
+		responses, err := ptpmgmt.NewClient(conn, 0).Get(ptpmgmt.TimeStatusNP)
+		Expect(err).NotTo(HaveOccurred())
+
+		timeStatus := responses[0].Data.(*ptpmgmt.TimeStatus)
+		Expect(timeStatus.MasterOffset).To(BeNumerically("==", -2))
+		Expect(timeStatus.IngressTime).To(BeNumerically("==", 1686916187058400000))
+		Expect(timeStatus.CumulativeScaledRateOffset).To(BeNumerically("~", 1e-6, 1e-12))
+		Expect(timeStatus.LastGmPhaseChange).To(Equal("0x0000'0000000000000000.0000"))
+		Expect(timeStatus.GMPresent).To(BeTrue())
+		Expect(timeStatus.GMIdentity).To(Equal("507c6f.fffe.1fb1a8"))
+
+		conn = newFakeConn("grandmaster_settings_np.bin")
+		responses, err = ptpmgmt.NewClient(conn, 0).Get(ptpmgmt.GrandmasterSettingsNP)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(responses[0].Data).To(Equal(&ptpmgmt.GrandmasterSettings{
+			ClockQuality: ptpmgmt.ClockQuality{ClockClass: 248, ClockAccuracy: 0xfe, OffsetScaledLogVariance: 0xffff},
+			TimeProperties: ptpmgmt.TimeProperties{
+				CurrentUtcOffset:      37,
+				CurrentUtcOffsetValid: true,
+				PtpTimescale:          true,
+				TimeSource:            0xa0,
+			},
+		}))
+	})
+
+	It("should return an error status as an error", func() {
+		_, err := ptpmgmt.NewClient(newFakeConn("error_not_supported.bin"), 0).Get(ptpmgmt.ClockDescription)
+		Expect(err).To(MatchError(ptpmgmt.ErrManagementError))
+		Expect(err.Error()).To(ContainSubstring("NOT_SUPPORTED"))
+	})
+
+	It("should skip responses to an abandoned request", func() {
+		conn := newFakeConn("current_data_set.bin", "current_data_set.bin")
+		client := ptpmgmt.NewClient(conn, 0)
+
+		// the first response is to sequence 1 so the second request only gets a timeout
+		_, err := client.Get(ptpmgmt.CurrentDataSet)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.Get(ptpmgmt.CurrentDataSet)
+		Expect(err).To(MatchError(ptpmgmt.ErrReceiveTimeout))
+	})
+
+	It("should keep the data of an ID without a known layout as hex", func() {
+		dataSet, err := ptpmgmt.DecodeDataSet(ptpmgmt.PortStatsNP, []byte{0x01, 0xff})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dataSet).To(Equal("01ff"))
+	})
+})
+
+var _ = Describe("Response", func() {
+	It("should round trip through JSON as its dataset", func() {
+		responses, err := ptpmgmt.NewClient(newFakeConn("port_data_set_1.bin"), 0).Get(ptpmgmt.PortDataSet)
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := json.Marshal(responses[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"managementId":"PORT_DATA_SET"`))
+
+		decoded := &ptpmgmt.Response{}
+		Expect(json.Unmarshal(data, decoded)).To(Succeed())
+		Expect(decoded).To(Equal(responses[0]))
+	})
+})
+
+var _ = Describe("ParseConfig", func() {
+	It("should read the socket and domain from the global section", func() {
+		config, err := ptpmgmt.ParseConfig(`[global]
+# the instance's own socket
+uds_address /var/run/ptp4l.1.socket
+domainNumber 24
+[ens7f0]
+domainNumber 0
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(Equal(&ptpmgmt.Config{UDSAddress: "/var/run/ptp4l.1.socket", DomainNumber: 24}))
+	})
+
+	It("should default to ptp4l's socket", func() {
+		config, err := ptpmgmt.ParseConfig("[ens7f0]\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.UDSAddress).To(Equal(ptpmgmt.DefaultUDSAddress))
+	})
+})
+
+func TestPTPManagement(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "PTP Management Suite")
+}
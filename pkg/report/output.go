@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package report
+
+import (
+	"cmp"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	tabPadding = 2
+	percent    = 100
+)
+
+// ReadFile adds the records in the file at path to the report,
+// files compressed by a rotating output (.gz or .zst) are decompressed
+func (builder *Builder) ReadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		gzipReader, gzErr := gzip.NewReader(file)
+		if gzErr != nil {
+			return fmt.Errorf("failed to decompress %s: %w", path, gzErr)
+		}
+		defer gzipReader.Close()
+
+		reader = gzipReader
+	case strings.HasSuffix(path, ".zst"):
+		zstdReader, zstdErr := zstd.NewReader(file)
+		if zstdErr != nil {
+			return fmt.Errorf("failed to decompress %s: %w", path, zstdErr)
+		}
+		defer zstdReader.Close()
+
+		reader = zstdReader
+	}
+
+	err = builder.Read(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// WriteJSON writes the report as indented JSON
+func (report *Report) WriteJSON(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(report)
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
+// WriteText writes the report in a human readable form
+func (report *Report) WriteText(writer io.Writer) error {
+	tab := tabwriter.NewWriter(writer, 0, 0, tabPadding, ' ', 0)
+
+	fmt.Fprintf(tab, "%d records\n", report.Records)
+
+	for _, summary := range report.Summaries {
+		if target := summary.Target(); target != "" {
+			fmt.Fprintf(tab, "\n%s\t%s\n", summary.ID, target)
+		} else {
+			fmt.Fprintf(tab, "\n%s\n", summary.ID)
+		}
+
+		summary.writeText(tab)
+	}
+
+	err := tab.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
+func (summary *Summary) writeText(writer io.Writer) {
+	fmt.Fprintf(writer, "  samples\t%d", summary.Samples)
+
+	if summary.First != nil {
+		fmt.Fprintf(writer, " from %s to %s (%s)",
+			summary.First.Format(time.RFC3339), summary.Last.Format(time.RFC3339), summary.Last.Sub(*summary.First))
+	}
+
+	if summary.UntimedSamples > 0 {
+		fmt.Fprintf(writer, ", %d without a timestamp", summary.UntimedSamples)
+	}
+
+	fmt.Fprintln(writer)
+
+	if stats := summary.TimeError; stats != nil {
+		fmt.Fprintf(writer, "  terror\tmin %.3f max %.3f mean %.3f stddev %.3f p50 %.3f p90 %.3f p99 %.3f\n",
+			stats.Min, stats.Max, stats.Mean, stats.StdDev, stats.P50, stats.P90, stats.P99)
+	}
+
+	if len(summary.StateSeconds) > 0 {
+		fmt.Fprintf(writer, "  states\t%s, %d transitions\n", summary.stateText(), summary.StateTransitions)
+	}
+
+	if summary.IntervalSeconds > 0 {
+		fmt.Fprintf(writer, "  gaps\t%d with a usual interval of %s%s\n",
+			len(summary.Gaps), seconds(summary.IntervalSeconds), summary.longestGapText())
+	}
+
+	if len(summary.ClockClassHistory) > 0 {
+		history := make([]string, 0, len(summary.ClockClassHistory))
+		for _, change := range summary.ClockClassHistory {
+			history = append(history, fmt.Sprintf("%s at %s", change.Value, change.Timestamp.Format(time.RFC3339)))
+		}
+
+		fmt.Fprintf(writer, "  clockClass\t%s\n", strings.Join(history, ", "))
+	}
+
+	for _, field := range slices.Sorted(maps.Keys(summary.Latest)) {
+		fmt.Fprintf(writer, "  %s\t%v\n", field, summary.Latest[field])
+	}
+}
+
+func (summary *Summary) stateText() string {
+	total := 0.0
+	for _, secs := range summary.StateSeconds {
+		total += secs
+	}
+
+	states := make([]string, 0, len(summary.StateSeconds))
+
+	for _, state := range slices.Sorted(maps.Keys(summary.StateSeconds)) {
+		share := 0.0
+		if total > 0 {
+			share = summary.StateSeconds[state] / total * percent
+		}
+
+		states = append(states, fmt.Sprintf("%s: %s (%.1f%%)", state, seconds(summary.StateSeconds[state]), share))
+	}
+
+	return strings.Join(states, ", ")
+}
+
+func (summary *Summary) longestGapText() string {
+	if len(summary.Gaps) == 0 {
+		return ""
+	}
+
+	longest := slices.MaxFunc(summary.Gaps, func(a, b Gap) int {
+		return cmp.Compare(a.DurationSeconds, b.DurationSeconds)
+	})
+
+	return fmt.Sprintf(", longest %s at %s", seconds(longest.DurationSeconds), longest.Start.Format(time.RFC3339))
+}
+
+func seconds(secs float64) string {
+	return time.Duration(secs * float64(time.Second)).Round(time.Millisecond).String()
+}
@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Package report summarises the AnalyserJSON output of a collection
+package report
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// A gap is an interval between samples this many times longer than the median interval
+	gapFactor = 2
+
+	maxLineSize = 1024 * 1024
+)
+
+// recordSpec says which fields of a record are summarised
+type recordSpec struct {
+	terrorField     string
+	stateField      string
+	clockClassField string
+	instanceField   string
+	latestFields    []string
+}
+
+// specFor returns the fields to summarise for a record ID
+func specFor(id string) recordSpec {
+	switch {
+	case strings.HasSuffix(id, "/time-error"):
+		return recordSpec{terrorField: "terror", stateField: "state"}
+	case id == "gnss/rf-mon":
+		return recordSpec{stateField: "status", instanceField: "blockId"}
+	case id == "phc/gm-settings":
+		return recordSpec{clockClassField: "clock_class"}
+	case id == "devInfo":
+		return recordSpec{latestFields: []string{"vendorID", "devID", "gnss", "firmwareVersion", "driverVersion"}}
+	default:
+		return recordSpec{}
+	}
+}
+
+type record struct {
+	Data      any    `json:"data"`
+	ID        string `json:"id"`
+	Node      string `json:"node"`
+	Interface string `json:"interface"`
+}
+
+type sample struct {
+	timestamp time.Time
+	data      map[string]any
+	hasTime   bool
+}
+
+// series is every sample of one record ID from one target
+type series struct {
+	spec     recordSpec
+	id       string
+	node     string
+	iface    string
+	instance string
+	samples  []sample
+	untimed  int
+}
+
+// Builder reads AnalyserJSON records and builds a Report from them
+type Builder struct {
+	series  map[string]*series
+	records int
+}
+
+func NewBuilder() *Builder {
+	return &Builder{series: make(map[string]*series)}
+}
+
+// Read adds every record read from reader to the report
+func (builder *Builder) Read(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		rec := record{}
+
+		err := json.Unmarshal([]byte(line), &rec)
+		if err != nil || rec.ID == "" {
+			return fmt.Errorf(
+				"line %d is not an AnalyserJSON record, was the output written with --use-analyser-format?",
+				lineNumber,
+			)
+		}
+
+		builder.add(&rec)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return fmt.Errorf("failed to read records: %w", err)
+	}
+
+	return nil
+}
+
+func (builder *Builder) add(rec *record) {
+	builder.records++
+
+	spec := specFor(rec.ID)
+	data, _ := rec.Data.(map[string]any) //nolint:errcheck // records without a data object are only counted
+
+	instance := ""
+	if spec.instanceField != "" {
+		if value, ok := data[spec.instanceField]; ok {
+			instance = fmt.Sprintf("%s %v", spec.instanceField, value)
+		}
+	}
+
+	key := strings.Join([]string{rec.ID, rec.Node, rec.Interface, instance}, "\x00")
+
+	ser, ok := builder.series[key]
+	if !ok {
+		ser = &series{
+			spec:     spec,
+			id:       rec.ID,
+			node:     rec.Node,
+			iface:    rec.Interface,
+			instance: instance,
+		}
+		builder.series[key] = ser
+	}
+
+	smp := sample{data: data}
+	if value, isString := data["timestamp"].(string); isString {
+		timestamp, err := time.Parse(time.RFC3339Nano, value)
+		if err == nil {
+			smp.timestamp = timestamp
+			smp.hasTime = true
+		}
+	}
+
+	if !smp.hasTime {
+		ser.untimed++
+	}
+
+	ser.samples = append(ser.samples, smp)
+}
+
+// Report returns the summaries of everything read so far
+func (builder *Builder) Report() *Report {
+	report := &Report{Records: builder.records}
+
+	all := make([]*series, 0, len(builder.series))
+	for _, ser := range builder.series {
+		all = append(all, ser)
+	}
+
+	slices.SortFunc(all, func(a, b *series) int {
+		return cmp.Or(
+			cmp.Compare(a.id, b.id),
+			cmp.Compare(a.node, b.node),
+			cmp.Compare(a.iface, b.iface),
+			cmp.Compare(a.instance, b.instance),
+		)
+	})
+
+	for _, ser := range all {
+		report.Summaries = append(report.Summaries, ser.summarise())
+	}
+
+	return report
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package report_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/report"
+)
+
+const records = `{"data":{"timestamp":"2023-06-16T11:49:47Z","eecstate":"locked","state":"locked","terror":1},"id":"dpll/time-error"}
+{"data":{"timestamp":"2023-06-16T11:49:48Z","eecstate":"locked","state":"locked","terror":-3},"id":"dpll/time-error"}
+{"data":{"timestamp":"2023-06-16T11:49:49Z","eecstate":"locked","state":"holdover","terror":5},"id":"dpll/time-error"}
+{"data":{"timestamp":"2023-06-16T11:49:55Z","eecstate":"locked","state":"locked","terror":1},"id":"dpll/time-error"}
+{"data":{"timestamp":"2023-06-16T11:49:56Z","eecstate":"locked","state":"locked","terror":1},"id":"dpll/time-error"}
+{"data":{"timestamp":"2023-06-16T11:49:47Z","clock_class":6},"id":"phc/gm-settings","node":"node-1"}
+{"data":{"timestamp":"2023-06-16T11:49:50Z","clock_class":7},"id":"phc/gm-settings","node":"node-1"}
+{"data":{"timestamp":"2023-06-16T11:49:53Z","clock_class":7},"id":"phc/gm-settings","node":"node-1"}
+{"data":{"timestamp":"2023-06-16T11:49:47Z","firmwareVersion":"4.20","driverVersion":"1.11"},"id":"devInfo"}
+`
+
+var _ = Describe("Builder", func() {
+	var summaries map[string]*report.Summary
+	var built *report.Report
+
+	BeforeEach(func() {
+		builder := report.NewBuilder()
+		Expect(builder.Read(strings.NewReader(records))).To(Succeed())
+
+		built = builder.Report()
+		summaries = make(map[string]*report.Summary)
+		for _, summary := range built.Summaries {
+			summaries[summary.ID] = summary
+		}
+	})
+
+	When("reading time error records", func() {
+		It("should summarise the time error", func() {
+			Expect(built.Records).To(Equal(9))
+			dpll := summaries["dpll/time-error"]
+			Expect(dpll.Samples).To(Equal(5))
+			Expect(dpll.TimeError.Min).To(Equal(-3.0))
+			Expect(dpll.TimeError.Max).To(Equal(5.0))
+			Expect(dpll.TimeError.Mean).To(Equal(1.0))
+			Expect(dpll.TimeError.P50).To(Equal(1.0))
+		})
+		It("should count time in each state and transitions", func() {
+			dpll := summaries["dpll/time-error"]
+			Expect(dpll.StateSeconds).To(Equal(map[string]float64{"locked": 3, "holdover": 6}))
+			Expect(dpll.StateTransitions).To(Equal(2))
+		})
+		It("should find gaps in sampling", func() {
+			dpll := summaries["dpll/time-error"]
+			Expect(dpll.IntervalSeconds).To(Equal(1.0))
+			Expect(dpll.Gaps).To(HaveLen(1))
+			Expect(dpll.Gaps[0].DurationSeconds).To(Equal(6.0))
+		})
+	})
+	When("reading grandmaster settings", func() {
+		It("should record the clockClass history", func() {
+			gm := summaries["phc/gm-settings"]
+			Expect(gm.Node).To(Equal("node-1"))
+			Expect(gm.ClockClassHistory).To(HaveLen(2))
+			Expect(gm.ClockClassHistory[0].Value).To(Equal("6"))
+			Expect(gm.ClockClassHistory[1].Value).To(Equal("7"))
+		})
+	})
+	When("reading device info", func() {
+		It("should keep the latest values", func() {
+			Expect(summaries["devInfo"].Latest).To(HaveKeyWithValue("firmwareVersion", "4.20"))
+		})
+	})
+	When("writing the report as text", func() {
+		It("should include each record ID", func() {
+			var out bytes.Buffer
+			Expect(built.WriteText(&out)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("dpll/time-error"))
+			Expect(out.String()).To(ContainSubstring("holdover: 6s (66.7%)"))
+			Expect(out.String()).To(ContainSubstring("6 at 2023-06-16T11:49:47Z, 7 at 2023-06-16T11:49:50Z"))
+		})
+	})
+	When("reading raw output", func() {
+		It("should return an error", func() {
+			builder := report.NewBuilder()
+			err := builder.Read(strings.NewReader("*devices.PMCInfo:pmc-info, {}\n"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+func TestReport(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Report Suite")
+}
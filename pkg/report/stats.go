@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package report
+
+import (
+	"math"
+	"slices"
+)
+
+// Stats summarises a set of values
+type Stats struct {
+	Count  int     `json:"count"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	P50    float64 `json:"p50"`
+	P90    float64 `json:"p90"`
+	P99    float64 `json:"p99"`
+}
+
+// percentile returns the nearest rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted)))) //nolint:mnd // percent
+	rank = max(rank, 1)
+
+	return sorted[rank-1]
+}
+
+// newStats returns the Stats of values, or nil if there are none
+func newStats(values []float64) *Stats {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	sum := 0.0
+	for _, value := range sorted {
+		sum += value
+	}
+
+	mean := sum / float64(len(sorted))
+
+	squares := 0.0
+	for _, value := range sorted {
+		squares += (value - mean) * (value - mean)
+	}
+
+	return &Stats{
+		Count:  len(sorted),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Mean:   mean,
+		StdDev: math.Sqrt(squares / float64(len(sorted))),
+		P50:    percentile(sorted, 50), //nolint:mnd // median
+		P90:    percentile(sorted, 90), //nolint:mnd // 90th percentile
+		P99:    percentile(sorted, 99), //nolint:mnd // 99th percentile
+	}
+}
+
+// median returns the median of values which must not be empty
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	return percentile(sorted, 50) //nolint:mnd // median
+}
@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package report
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Report is the summary of every record ID from every target in a collection
+type Report struct {
+	Summaries []*Summary `json:"summaries"`
+	Records   int        `json:"records"`
+}
+
+// Gap is an interval between samples much longer than the usual interval
+type Gap struct {
+	Start           time.Time `json:"start"`
+	DurationSeconds float64   `json:"durationSeconds"`
+}
+
+// StateChange is the time a value such as the clock class changed
+type StateChange struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     string    `json:"value"`
+}
+
+// Summary describes the samples of one record ID from one target
+type Summary struct {
+	First             *time.Time         `json:"first,omitempty"`
+	Last              *time.Time         `json:"last,omitempty"`
+	TimeError         *Stats             `json:"terror,omitempty"`
+	StateSeconds      map[string]float64 `json:"stateSeconds,omitempty"`
+	Latest            map[string]any     `json:"latest,omitempty"`
+	ID                string             `json:"id"`
+	Node              string             `json:"node,omitempty"`
+	Interface         string             `json:"interface,omitempty"`
+	Instance          string             `json:"instance,omitempty"`
+	Gaps              []Gap              `json:"gaps,omitempty"`
+	ClockClassHistory []StateChange      `json:"clockClassHistory,omitempty"`
+	Samples           int                `json:"samples"`
+	UntimedSamples    int                `json:"untimedSamples,omitempty"`
+	StateTransitions  int                `json:"stateTransitions"`
+	IntervalSeconds   float64            `json:"intervalSeconds,omitempty"`
+}
+
+// Target returns the node, interface and instance the summary is for
+func (summary *Summary) Target() string {
+	target := summary.Node
+	if summary.Interface != "" {
+		if target != "" {
+			target += "/"
+		}
+
+		target += summary.Interface
+	}
+
+	if summary.Instance != "" {
+		if target != "" {
+			target += " "
+		}
+
+		target += summary.Instance
+	}
+
+	return target
+}
+
+// timed returns the samples which have a timestamp in time order
+func (ser *series) timed() []sample {
+	timed := make([]sample, 0, len(ser.samples))
+
+	for _, smp := range ser.samples {
+		if smp.hasTime {
+			timed = append(timed, smp)
+		}
+	}
+
+	slices.SortStableFunc(timed, func(a, b sample) int {
+		return a.timestamp.Compare(b.timestamp)
+	})
+
+	return timed
+}
+
+func (ser *series) summarise() *Summary {
+	summary := &Summary{
+		ID:             ser.id,
+		Node:           ser.node,
+		Interface:      ser.iface,
+		Instance:       ser.instance,
+		Samples:        len(ser.samples),
+		UntimedSamples: ser.untimed,
+	}
+
+	timed := ser.timed()
+	if len(timed) > 0 {
+		summary.First = &timed[0].timestamp
+		summary.Last = &timed[len(timed)-1].timestamp
+	}
+
+	summary.IntervalSeconds, summary.Gaps = findGaps(timed)
+
+	if ser.spec.terrorField != "" {
+		summary.TimeError = newStats(numbers(ser.samples, ser.spec.terrorField))
+	}
+
+	if ser.spec.stateField != "" {
+		summary.StateSeconds, summary.StateTransitions = stateTimes(timed, ser.spec.stateField)
+	}
+
+	if ser.spec.clockClassField != "" {
+		summary.ClockClassHistory = changes(timed, ser.spec.clockClassField)
+	}
+
+	if len(ser.spec.latestFields) > 0 && len(ser.samples) > 0 {
+		summary.Latest = latest(ser.samples[len(ser.samples)-1], ser.spec.latestFields)
+	}
+
+	return summary
+}
+
+// numbers returns every numeric value of field
+func numbers(samples []sample, field string) []float64 {
+	values := make([]float64, 0, len(samples))
+
+	for _, smp := range samples {
+		if value, ok := smp.data[field].(float64); ok {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// findGaps returns the usual interval between samples and the intervals which were much longer
+func findGaps(timed []sample) (float64, []Gap) {
+	if len(timed) < 2 { //nolint:mnd // an interval needs two samples
+		return 0, nil
+	}
+
+	intervals := make([]float64, 0, len(timed)-1)
+	for i := 1; i < len(timed); i++ {
+		intervals = append(intervals, timed[i].timestamp.Sub(timed[i-1].timestamp).Seconds())
+	}
+
+	usual := median(intervals)
+	gaps := make([]Gap, 0)
+
+	for i, interval := range intervals {
+		if usual > 0 && interval > gapFactor*usual {
+			gaps = append(gaps, Gap{Start: timed[i].timestamp, DurationSeconds: interval})
+		}
+	}
+
+	return usual, gaps
+}
+
+// stateTimes returns how long was spent in each state and how many times the state changed.
+// The time until the next sample is counted against the state of each sample.
+func stateTimes(timed []sample, field string) (map[string]float64, int) {
+	seconds := make(map[string]float64)
+	transitions := 0
+	previous := ""
+
+	for i, smp := range timed {
+		value, ok := smp.data[field]
+		if !ok {
+			continue
+		}
+
+		state := fmt.Sprint(value)
+		if previous != "" && state != previous {
+			transitions++
+		}
+
+		previous = state
+
+		duration := 0.0
+		if i+1 < len(timed) {
+			duration = timed[i+1].timestamp.Sub(smp.timestamp).Seconds()
+		}
+
+		seconds[state] += duration
+	}
+
+	if len(seconds) == 0 {
+		return nil, 0
+	}
+
+	return seconds, transitions
+}
+
+// changes returns the times the value of field changed, starting with its first value
+func changes(timed []sample, field string) []StateChange {
+	history := make([]StateChange, 0)
+
+	for _, smp := range timed {
+		value, ok := smp.data[field]
+		if !ok {
+			continue
+		}
+
+		text := fmt.Sprint(value)
+		if len(history) == 0 || history[len(history)-1].Value != text {
+			history = append(history, StateChange{Timestamp: smp.timestamp, Value: text})
+		}
+	}
+
+	return history
+}
+
+func latest(smp sample, fields []string) map[string]any {
+	values := make(map[string]any)
+
+	for _, field := range fields {
+		if value, ok := smp.data[field]; ok {
+			values[field] = value
+		}
+	}
+
+	return values
+}
@@ -3,6 +3,7 @@
 package runner
 
 import (
+	"fmt"
 	"slices"
 	"strings"
 
@@ -65,3 +66,27 @@ func GetCollectorsToRun(selectedCollectors []string) []string {
 
 	return collectorNames
 }
+
+// WithoutCollectors returns the collectors to be run for selectedCollectors
+// excluding those in disabledCollectors. Required collectors can not be disabled.
+func WithoutCollectors(selectedCollectors, disabledCollectors []string) ([]string, error) {
+	for _, name := range disabledCollectors {
+		if isIn(name, RequiredCollectorNames) {
+			return nil, fmt.Errorf("collector %s is required so can not be disabled", name)
+		}
+
+		if !isIn(name, OptionalCollectorNames) {
+			return nil, fmt.Errorf("unknown collector %s can not be disabled", name)
+		}
+	}
+
+	collectorNames := make([]string, 0)
+
+	for _, name := range GetCollectorsToRun(selectedCollectors) {
+		if !isIn(name, disabledCollectors) {
+			collectorNames = append(collectorNames, name)
+		}
+	}
+
+	return collectorNames, nil
+}
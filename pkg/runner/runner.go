@@ -13,6 +13,7 @@ import (
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/constants"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/metrics"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
@@ -38,6 +39,7 @@ type CollectorRunner struct {
 	pollResults            chan collectors.PollResult
 	erroredPolls           chan collectors.PollResult
 	collectorInstances     map[string]collectors.Collector
+	collectorTargets       map[string]*collectors.Target
 	collectorNames         []string
 	runningCollectorsWG    utils.WaitGroupCount
 	runningAnnouncersWG    utils.WaitGroupCount
@@ -49,6 +51,7 @@ type CollectorRunner struct {
 func NewCollectorRunner(selectedCollectors []string) *CollectorRunner {
 	return &CollectorRunner{
 		collectorInstances:   make(map[string]collectors.Collector),
+		collectorTargets:     make(map[string]*collectors.Target),
 		collectorNames:       GetCollectorsToRun(selectedCollectors),
 		quit:                 getQuitChannel(),
 		pollResults:          make(chan collectors.PollResult, pollResultsQueueSize),
@@ -70,31 +73,37 @@ func (runner *CollectorRunner) initialise( //nolint:funlen // allow a slightly l
 
 	registry := collectors.GetRegistry()
 
-	for _, collectorName := range runner.collectorNames {
-		// Skip GPS/GNSS collectors for Boundary Clock
-		if constructor.ClockType == constants.ClockTypeBC && collectorName == collectors.GPSCollectorName {
-			log.Infof("Skipping GPS collector '%s' for Boundary Clock configuration", collectorName)
-			continue
-		}
+	for _, targetConstructor := range constructor.ForTargets() {
+		for _, collectorName := range runner.collectorNames {
+			instanceName := targetConstructor.InstanceName(collectorName)
 
-		builderFunc, err := registry.GetBuilderFunc(collectorName)
-		if err != nil {
-			log.Error(err)
-			continue
-		}
+			// Skip GPS/GNSS collectors for Boundary Clock
+			if targetConstructor.ClockType == constants.ClockTypeBC &&
+				(collectorName == collectors.GPSCollectorName || collectorName == collectors.GPSDCollectorName) {
+				log.Infof("Skipping GPS collector '%s' for Boundary Clock configuration", instanceName)
+				continue
+			}
 
-		newCollector, err := builderFunc(constructor)
+			builderFunc, err := registry.GetBuilderFunc(collectorName)
+			if err != nil {
+				log.Error(err)
+				continue
+			}
 
-		var missingRequirements *utils.RequirementsNotMetError
-		if errors.As(err, &missingRequirements) {
-			// Requirements are missing so don't add the collector to collectorInstance
-			// so that it doesn't get ran
-			log.Warning(err.Error())
-		} else {
-			utils.IfErrorExitOrPanic(err)
+			newCollector, err := builderFunc(targetConstructor)
 
-			runner.collectorInstances[collectorName] = newCollector
-			log.Debugf("Added collector %T, %v", newCollector, newCollector)
+			var missingRequirements *utils.RequirementsNotMetError
+			if errors.As(err, &missingRequirements) {
+				// Requirements are missing so don't add the collector to collectorInstance
+				// so that it doesn't get ran
+				log.Warning(err.Error())
+			} else {
+				utils.IfErrorExitOrPanic(err)
+
+				runner.collectorInstances[instanceName] = newCollector
+				runner.collectorTargets[instanceName] = targetConstructor.Target
+				log.Debugf("Added collector %T, %v", newCollector, newCollector)
+			}
 		}
 	}
 
@@ -135,12 +144,33 @@ func (runner *CollectorRunner) poller(
 
 	var lastPoll time.Time
 
-	pollInterval := collector.GetPollInterval()
 	runningPolls := utils.WaitGroupCount{}
 
-	log.Debugf("Collector with poll interval %f ", pollInterval.Seconds())
+	// Results are forwarded with the instance name so polls of the same collector on different targets can be
+	// told apart, this is stopped once the running polls have finished so before the poller is marked done
+	results := make(chan collectors.PollResult)
+	forwarded := make(chan struct{})
+
+	go func() {
+		defer close(forwarded)
+
+		for pollRes := range results {
+			pollRes.InstanceName = collectorName
+			runner.pollResults <- pollRes
+		}
+	}()
+
+	defer func() {
+		close(results)
+		<-forwarded
+	}()
+
+	log.Debugf("Collector with poll interval %f ", collector.GetPollInterval().Seconds())
 
 	for runner.shouldKeepPolling(collector) {
+		// The interval is read on every loop as adaptive collectors change it
+		pollInterval := collector.GetPollInterval()
+
 		// If pollResults were to block we do not want to keep spawning polls
 		// so we shouldn't allow too many polls to be running simultaneously
 		if runningPolls.GetCount() >= maxRunningPolls {
@@ -165,16 +195,15 @@ func (runner *CollectorRunner) poller(
 				time.Since(lastPoll) > pollInterval,
 				lastPoll.IsZero() || time.Since(lastPoll) > pollInterval,
 			)
-			// Not using a ticker as the idea was to make
-			// pollInterval dynamic to be able to respond
-			// to events triggered the action tool
+			// Not using a ticker as pollInterval is dynamic
+			// so that collectors can respond to events
 			if lastPoll.IsZero() || time.Since(lastPoll) > pollInterval {
 				lastPoll = time.Now()
 
 				log.Debugf("poll %s", collectorName)
 				runningPolls.Add(1)
 
-				go collector.Poll(runner.pollResults, &runningPolls)
+				go collector.Poll(results, &runningPolls)
 			}
 
 			time.Sleep(10 * time.Nanosecond) //nolint:mnd // no point in making this its own var
@@ -185,6 +214,16 @@ func (runner *CollectorRunner) poller(
 	log.Debugf("Collector finished %s", collectorName)
 }
 
+// recordPoll counts a poll result against the node and interface of the instance it came from
+func (runner *CollectorRunner) recordPoll(exporter *metrics.Exporter, pollRes collectors.PollResult) {
+	node, iface := "", ""
+	if target := runner.collectorTargets[pollRes.InstanceName]; target != nil {
+		node, iface = target.NodeName, target.Interface
+	}
+
+	exporter.RecordPoll(pollRes.CollectorName, node, iface, pollRes.Errors)
+}
+
 // start configures all collectors to start collecting all their data keys
 func (runner *CollectorRunner) start() {
 	collectorsNames := make([]string, 0)
@@ -264,8 +303,12 @@ func (runner *CollectorRunner) Run( //nolint:funlen // allow a slightly long fun
 		case pollRes := <-runner.pollResults:
 			log.Infof("Received %v", pollRes)
 
+			if constuctor.Metrics != nil {
+				runner.recordPoll(constuctor.Metrics, pollRes)
+			}
+
 			if len(pollRes.Errors) > 0 {
-				log.Warnf("Poll %s had issues: %v. Will retry next poll", pollRes.CollectorName, pollRes.Errors)
+				log.Warnf("Poll %s had issues: %v. Will retry next poll", pollRes.InstanceName, pollRes.Errors)
 				// If erroredPolls blocks it could cause pollResults to fill and
 				// block the execution of the collectors.
 				runner.erroredPolls <- pollRes
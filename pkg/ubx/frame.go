@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Package ubx decodes the u-blox UBX binary protocol
+package ubx
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	SyncChar1 = 0xb5
+	SyncChar2 = 0x62
+
+	// headerLength is the sync chars, class, ID and the two byte payload length
+	headerLength   = 6
+	checksumLength = 2
+	maxFrameLength = headerLength + 0xffff + checksumLength
+)
+
+// Message classes
+const (
+	ClassNAV = 0x01
+	ClassMON = 0x0a
+	ClassTIM = 0x0d
+)
+
+// ErrTruncatedFrame is returned when the input ends part way through a frame
+var ErrTruncatedFrame = errors.New("truncated UBX frame")
+
+// Frame is a single UBX message with a valid checksum
+type Frame struct {
+	Payload []byte
+	Class   byte
+	ID      byte
+}
+
+// Name returns the name of the message, such as NAV-STATUS
+func (frame *Frame) Name() string {
+	if name, ok := messageNames[messageKey{frame.Class, frame.ID}]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("0x%02x-0x%02x", frame.Class, frame.ID)
+}
+
+// Checksum returns the 8-bit Fletcher checksum of data, which for a frame
+// is everything between the sync chars and the checksum itself
+func Checksum(data []byte) (ckA, ckB byte) {
+	for _, b := range data {
+		ckA += b
+		ckB += ckA
+	}
+
+	return ckA, ckB
+}
+
+// MarshalBinary returns the frame with its sync chars, length and checksum
+func (frame *Frame) MarshalBinary() ([]byte, error) {
+	if len(frame.Payload) > 0xffff {
+		return nil, fmt.Errorf("payload of %d bytes is too long for a UBX frame", len(frame.Payload))
+	}
+
+	data := make([]byte, 0, headerLength+len(frame.Payload)+checksumLength)
+	data = append(data, SyncChar1, SyncChar2, frame.Class, frame.ID)
+	data = binary.LittleEndian.AppendUint16(data, uint16(len(frame.Payload))) //nolint:gosec // checked above
+	data = append(data, frame.Payload...)
+	ckA, ckB := Checksum(data[2:])
+
+	return append(data, ckA, ckB), nil
+}
+
+// Reader reads UBX frames from a stream which may also contain other data, such as NMEA sentences
+type Reader struct {
+	reader       *bufio.Reader
+	skipped      int
+	badChecksums int
+}
+
+func NewReader(reader io.Reader) *Reader {
+	return &Reader{reader: bufio.NewReaderSize(reader, maxFrameLength)}
+}
+
+// Skipped returns the number of bytes which were not part of a valid frame
+func (r *Reader) Skipped() int {
+	return r.skipped
+}
+
+// BadChecksums returns the number of frames dropped because their checksum did not match
+func (r *Reader) BadChecksums() int {
+	return r.badChecksums
+}
+
+// Next returns the next valid frame. It returns io.EOF at the end of the input
+// and ErrTruncatedFrame if the input ends part way through a frame.
+func (r *Reader) Next() (*Frame, error) {
+	for {
+		err := r.findSync()
+		if err != nil {
+			return nil, err
+		}
+
+		header, err := r.reader.Peek(headerLength)
+		if err != nil {
+			return nil, r.truncated(err)
+		}
+
+		payloadLength := int(binary.LittleEndian.Uint16(header[4:]))
+
+		data, err := r.reader.Peek(headerLength + payloadLength + checksumLength)
+		if err != nil {
+			return nil, r.truncated(err)
+		}
+
+		ckA, ckB := Checksum(data[2 : headerLength+payloadLength])
+		if ckA != data[headerLength+payloadLength] || ckB != data[headerLength+payloadLength+1] {
+			// Resynchronise from the byte after the sync chars as they may not have been the start of a frame
+			r.badChecksums++
+			r.skipped++
+			r.discard(1)
+
+			continue
+		}
+
+		frame := &Frame{
+			Class:   data[2],
+			ID:      data[3],
+			Payload: append([]byte(nil), data[headerLength:headerLength+payloadLength]...),
+		}
+		r.discard(len(data))
+
+		return frame, nil
+	}
+}
+
+// findSync skips forward until the reader is at the sync chars
+func (r *Reader) findSync() error {
+	for {
+		sync, err := r.reader.Peek(2) //nolint:mnd // two sync chars
+		if len(sync) == 2 && sync[0] == SyncChar1 && sync[1] == SyncChar2 {
+			return nil
+		}
+
+		if errors.Is(err, io.EOF) {
+			r.skipped += len(sync)
+
+			return io.EOF
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read UBX frame: %w", err)
+		}
+
+		r.skipped++
+		r.discard(1)
+	}
+}
+
+func (r *Reader) discard(n int) {
+	// Only called with n bytes which have already been peeked so it can not fail
+	_, _ = r.reader.Discard(n) //nolint:errcheck // see above
+}
+
+func (r *Reader) truncated(err error) error {
+	if errors.Is(err, io.EOF) {
+		return ErrTruncatedFrame
+	}
+
+	return fmt.Errorf("failed to read UBX frame: %w", err)
+}
+
+// ReadFrames returns every valid frame in reader, a frame truncated by the end of the input is ignored
+func ReadFrames(reader io.Reader) ([]*Frame, error) {
+	ubxReader := NewReader(reader)
+	frames := make([]*Frame, 0)
+
+	for {
+		frame, err := ubxReader.Next()
+		if errors.Is(err, io.EOF) || errors.Is(err, ErrTruncatedFrame) {
+			return frames, nil
+		}
+
+		if err != nil {
+			return frames, err
+		}
+
+		frames = append(frames, frame)
+	}
+}
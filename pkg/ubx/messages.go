@@ -0,0 +1,334 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package ubx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Message IDs
+const (
+	IDNavStatus  = 0x03
+	IDNavTimeUTC = 0x21
+	IDNavClock   = 0x22
+	IDNavTimeLS  = 0x26
+	IDNavSat     = 0x35
+	IDMonVer     = 0x04
+	IDMonHW      = 0x09
+	IDMonRF      = 0x38
+	IDTimTP      = 0x01
+)
+
+const (
+	navSatHeaderLength = 8
+	navSatSVLength     = 12
+	monRFHeaderLength  = 4
+	monRFBlockLength   = 24
+	monVerSWLength     = 30
+	monVerHWLength     = 10
+	monVerExtLength    = 30
+)
+
+// ErrUnknownMessage is returned by Decode for messages which do not have a payload type
+var ErrUnknownMessage = errors.New("unknown UBX message")
+
+type messageKey struct {
+	class byte
+	id    byte
+}
+
+var messageNames = map[messageKey]string{
+	{ClassNAV, IDNavStatus}:  "NAV-STATUS",
+	{ClassNAV, IDNavTimeUTC}: "NAV-TIMEUTC",
+	{ClassNAV, IDNavClock}:   "NAV-CLOCK",
+	{ClassNAV, IDNavTimeLS}:  "NAV-TIMELS",
+	{ClassNAV, IDNavSat}:     "NAV-SAT",
+	{ClassMON, IDMonVer}:     "MON-VER",
+	{ClassMON, IDMonHW}:      "MON-HW",
+	{ClassMON, IDMonRF}:      "MON-RF",
+	{ClassTIM, IDTimTP}:      "TIM-TP",
+}
+
+// NavStatus is the receiver navigation status (UBX-NAV-STATUS)
+type NavStatus struct {
+	ITOW    uint32
+	GPSFix  uint8
+	Flags   uint8
+	FixStat uint8
+	Flags2  uint8
+	TTFF    uint32
+	MSSS    uint32
+}
+
+// NavClock is the clock solution (UBX-NAV-CLOCK)
+type NavClock struct {
+	ITOW uint32
+	ClkB int32
+	ClkD int32
+	TAcc uint32
+	FAcc uint32
+}
+
+// NavTimeUTC is the UTC time solution (UBX-NAV-TIMEUTC)
+type NavTimeUTC struct {
+	ITOW  uint32
+	TAcc  uint32
+	Nano  int32
+	Year  uint16
+	Month uint8
+	Day   uint8
+	Hour  uint8
+	Min   uint8
+	Sec   uint8
+	Valid uint8
+}
+
+// NavTimeLS is the leap second event information (UBX-NAV-TIMELS)
+type NavTimeLS struct {
+	ITOW          uint32
+	Version       uint8
+	_             [3]byte
+	SrcOfCurrLs   uint8
+	CurrLs        int8
+	SrcOfLsChange uint8
+	LsChange      int8
+	TimeToLsEvent int32
+	DateOfLsGpsWn uint16
+	DateOfLsGpsDn uint16
+	_             [3]byte
+	Valid         uint8
+}
+
+// NavSatSV is the information for one satellite in UBX-NAV-SAT
+type NavSatSV struct {
+	GNSSID uint8
+	SVID   uint8
+	CNO    uint8
+	Elev   int8
+	Azim   int16
+	PrRes  int16
+	Flags  uint32
+}
+
+// NavSat is the satellite information (UBX-NAV-SAT)
+type NavSat struct {
+	Satellites []NavSatSV
+	ITOW       uint32
+	Version    uint8
+	NumSvs     uint8
+}
+
+type navSatHeader struct {
+	ITOW    uint32
+	Version uint8
+	NumSvs  uint8
+	_       [2]byte
+}
+
+// TimTP is the time pulse time data (UBX-TIM-TP)
+type TimTP struct {
+	TowMS    uint32
+	TowSubMS uint32
+	QErr     int32
+	Week     uint16
+	Flags    uint8
+	RefInfo  uint8
+}
+
+// MonHW is the hardware status (UBX-MON-HW)
+type MonHW struct {
+	PinSel     uint32
+	PinBank    uint32
+	PinDir     uint32
+	PinVal     uint32
+	NoisePerMS uint16
+	AGCCnt     uint16
+	AStatus    uint8
+	APower     uint8
+	Flags      uint8
+	_          uint8
+	UsedMask   uint32
+	VP         [17]uint8
+	JamInd     uint8
+	_          [2]byte
+	PinIrq     uint32
+	PullH      uint32
+	PullL      uint32
+}
+
+// MonRFBlock is the information for one RF block in UBX-MON-RF
+type MonRFBlock struct {
+	BlockID    uint8
+	Flags      uint8
+	AntStatus  uint8
+	AntPower   uint8
+	PostStatus uint32
+	_          [4]byte
+	NoisePerMS uint16
+	AGCCnt     uint16
+	JamInd     uint8
+	OfsI       int8
+	MagI       uint8
+	OfsQ       int8
+	MagQ       uint8
+	_          [3]byte
+}
+
+// MonRF is the RF information (UBX-MON-RF)
+type MonRF struct {
+	Blocks  []MonRFBlock
+	Version uint8
+	NBlocks uint8
+}
+
+type monRFHeader struct {
+	Version uint8
+	NBlocks uint8
+	_       [2]byte
+}
+
+// MonVer is the receiver and software version (UBX-MON-VER)
+type MonVer struct {
+	SWVersion  string
+	HWVersion  string
+	Extensions []string
+}
+
+// Extension returns the value of the first extension starting with prefix, for example "FWVER="
+func (monVer *MonVer) Extension(prefix string) (string, bool) {
+	for _, extension := range monVer.Extensions {
+		if value, ok := strings.CutPrefix(extension, prefix); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// Decode returns the typed payload of frame, for example a *NavStatus for NAV-STATUS
+func Decode(frame *Frame) (any, error) {
+	switch (messageKey{frame.Class, frame.ID}) {
+	case messageKey{ClassNAV, IDNavStatus}:
+		return decodeFixed[NavStatus](frame)
+	case messageKey{ClassNAV, IDNavClock}:
+		return decodeFixed[NavClock](frame)
+	case messageKey{ClassNAV, IDNavTimeUTC}:
+		return decodeFixed[NavTimeUTC](frame)
+	case messageKey{ClassNAV, IDNavTimeLS}:
+		return decodeFixed[NavTimeLS](frame)
+	case messageKey{ClassNAV, IDNavSat}:
+		return decodeNavSat(frame)
+	case messageKey{ClassTIM, IDTimTP}:
+		return decodeFixed[TimTP](frame)
+	case messageKey{ClassMON, IDMonHW}:
+		return decodeFixed[MonHW](frame)
+	case messageKey{ClassMON, IDMonRF}:
+		return decodeMonRF(frame)
+	case messageKey{ClassMON, IDMonVer}:
+		return decodeMonVer(frame)
+	default:
+		return nil, fmt.Errorf("%w %s", ErrUnknownMessage, frame.Name())
+	}
+}
+
+func payloadLengthError(frame *Frame, expected int) error {
+	return fmt.Errorf("UBX-%s payload is %d bytes, expected %d", frame.Name(), len(frame.Payload), expected)
+}
+
+// decodeFixed decodes a message whose payload is always the size of T
+func decodeFixed[T any](frame *Frame) (*T, error) {
+	msg := new(T)
+
+	if size := binary.Size(msg); len(frame.Payload) != size {
+		return nil, payloadLengthError(frame, size)
+	}
+
+	err := binary.Read(bytes.NewReader(frame.Payload), binary.LittleEndian, msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode UBX-%s: %w", frame.Name(), err)
+	}
+
+	return msg, nil
+}
+
+// decodeRepeated decodes a header of type H followed by count blocks of type B
+func decodeRepeated[H, B any](frame *Frame, headerLen, blockLen int, count func(*H) int) (*H, []B, error) {
+	header := new(H)
+	reader := bytes.NewReader(frame.Payload)
+
+	if len(frame.Payload) < headerLen {
+		return nil, nil, payloadLengthError(frame, headerLen)
+	}
+
+	err := binary.Read(reader, binary.LittleEndian, header)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode UBX-%s: %w", frame.Name(), err)
+	}
+
+	n := count(header)
+	if expected := headerLen + n*blockLen; len(frame.Payload) != expected {
+		return nil, nil, payloadLengthError(frame, expected)
+	}
+
+	blocks := make([]B, n)
+
+	err = binary.Read(reader, binary.LittleEndian, blocks)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode UBX-%s: %w", frame.Name(), err)
+	}
+
+	return header, blocks, nil
+}
+
+func decodeNavSat(frame *Frame) (*NavSat, error) {
+	header, satellites, err := decodeRepeated[navSatHeader, NavSatSV](
+		frame, navSatHeaderLength, navSatSVLength, func(h *navSatHeader) int { return int(h.NumSvs) },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NavSat{ITOW: header.ITOW, Version: header.Version, NumSvs: header.NumSvs, Satellites: satellites}, nil
+}
+
+func decodeMonRF(frame *Frame) (*MonRF, error) {
+	header, blocks, err := decodeRepeated[monRFHeader, MonRFBlock](
+		frame, monRFHeaderLength, monRFBlockLength, func(h *monRFHeader) int { return int(h.NBlocks) },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MonRF{Version: header.Version, NBlocks: header.NBlocks, Blocks: blocks}, nil
+}
+
+func decodeMonVer(frame *Frame) (*MonVer, error) {
+	payload := frame.Payload
+	if len(payload) < monVerSWLength+monVerHWLength || (len(payload)-monVerSWLength-monVerHWLength)%monVerExtLength != 0 {
+		return nil, fmt.Errorf("UBX-%s payload of %d bytes is not a whole number of extensions", frame.Name(), len(payload))
+	}
+
+	monVer := &MonVer{
+		SWVersion:  nullTerminated(payload[:monVerSWLength]),
+		HWVersion:  nullTerminated(payload[monVerSWLength : monVerSWLength+monVerHWLength]),
+		Extensions: make([]string, 0),
+	}
+
+	for ext := payload[monVerSWLength+monVerHWLength:]; len(ext) > 0; ext = ext[monVerExtLength:] {
+		monVer.Extensions = append(monVer.Extensions, nullTerminated(ext[:monVerExtLength]))
+	}
+
+	return monVer, nil
+}
+
+func nullTerminated(data []byte) string {
+	if end := bytes.IndexByte(data, 0); end >= 0 {
+		data = data[:end]
+	}
+
+	return string(data)
+}
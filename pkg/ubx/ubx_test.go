@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package ubx_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/ubx"
+)
+
+// readFixture returns the frames in a capture along with the reader used to decode them
+func readFixture(path string) ([]*ubx.Frame, *ubx.Reader, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader := ubx.NewReader(bytes.NewReader(data))
+	frames := make([]*ubx.Frame, 0)
+
+	for {
+		frame, err := reader.Next()
+		if err != nil {
+			return frames, reader, err
+		}
+
+		frames = append(frames, frame)
+	}
+}
+
+func decodeName(frames []*ubx.Frame, name string) any {
+	for _, frame := range frames {
+		if frame.Name() == name {
+			msg, err := ubx.Decode(frame)
+			Expect(err).NotTo(HaveOccurred())
+
+			return msg
+		}
+	}
+
+	Fail("no " + name + " frame")
+
+	return nil
+}
+
+var _ = Describe("Reader", func() {
+	It("should find the frames amongst other data", func() {
+		frames, reader, err := readFixture("test_files/nav.ubx")
+		Expect(err).To(MatchError(ubx.ErrTruncatedFrame))
+
+		names := make([]string, 0, len(frames))
+		for _, frame := range frames {
+			names = append(names, frame.Name())
+		}
+
+		Expect(names).To(Equal([]string{
+			"NAV-STATUS", "NAV-CLOCK", "MON-RF", "NAV-SAT", "NAV-TIMEUTC", "NAV-TIMELS", "TIM-TP", "0x01-0x99", "MON-HW",
+		}))
+		// Two NMEA sentences and a NAV-CLOCK with a corrupted payload
+		Expect(reader.BadChecksums()).To(Equal(1))
+		Expect(reader.Skipped()).To(Equal(42 + 42 + 28))
+	})
+	It("should ignore a truncated frame when reading every frame", func() {
+		data, err := os.ReadFile("test_files/nav.ubx")
+		Expect(err).NotTo(HaveOccurred())
+
+		frames, err := ubx.ReadFrames(bytes.NewReader(data))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(frames).To(HaveLen(9))
+	})
+	It("should return EOF at the end of the input", func() {
+		reader := ubx.NewReader(bytes.NewReader([]byte("$GNGGA,,*7E\r\n")))
+		_, err := reader.Next()
+		Expect(errors.Is(err, io.EOF)).To(BeTrue())
+		Expect(reader.Skipped()).To(Equal(13))
+	})
+	It("should read back a marshalled frame", func() {
+		frame := &ubx.Frame{Class: ubx.ClassTIM, ID: ubx.IDTimTP, Payload: []byte{1, 2, 3}}
+		data, err := frame.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data[:6]).To(Equal([]byte{ubx.SyncChar1, ubx.SyncChar2, ubx.ClassTIM, ubx.IDTimTP, 3, 0}))
+
+		frames, err := ubx.ReadFrames(bytes.NewReader(data))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(frames).To(Equal([]*ubx.Frame{frame}))
+	})
+})
+
+var _ = Describe("Decode", func() {
+	var frames []*ubx.Frame
+
+	BeforeEach(func() {
+		var err error
+		frames, _, err = readFixture("test_files/nav.ubx")
+		Expect(err).To(MatchError(ubx.ErrTruncatedFrame))
+	})
+
+	It("should decode NAV-STATUS and NAV-CLOCK", func() {
+		status, ok := decodeName(frames, "NAV-STATUS").(*ubx.NavStatus)
+		Expect(ok).To(BeTrue())
+		Expect(status.ITOW).To(Equal(uint32(474605000)))
+		Expect(status.GPSFix).To(Equal(uint8(3)))
+		Expect(status.Flags).To(Equal(uint8(0xdd)))
+		Expect(status.TTFF).To(Equal(uint32(25030)))
+
+		clock, ok := decodeName(frames, "NAV-CLOCK").(*ubx.NavClock)
+		Expect(ok).To(BeTrue())
+		Expect(clock.ClkB).To(Equal(int32(-61594)))
+		Expect(clock.ClkD).To(Equal(int32(-56)))
+		Expect(clock.TAcc).To(Equal(uint32(5)))
+		Expect(clock.FAcc).To(Equal(uint32(164)))
+	})
+	It("should decode the repeated blocks of NAV-SAT and MON-RF", func() {
+		navSat, ok := decodeName(frames, "NAV-SAT").(*ubx.NavSat)
+		Expect(ok).To(BeTrue())
+		Expect(navSat.Satellites).To(HaveLen(3))
+		Expect(navSat.Satellites[1]).To(Equal(ubx.NavSatSV{
+			GNSSID: 0, SVID: 12, CNO: 38, Elev: 31, Azim: 275, PrRes: 50, Flags: 0x191f,
+		}))
+		Expect(navSat.Satellites[2].Elev).To(Equal(int8(-5)))
+
+		monRF, ok := decodeName(frames, "MON-RF").(*ubx.MonRF)
+		Expect(ok).To(BeTrue())
+		Expect(monRF.Blocks).To(HaveLen(2))
+		Expect(monRF.Blocks[1].BlockID).To(Equal(uint8(1)))
+		Expect(monRF.Blocks[1].AntStatus).To(Equal(uint8(2)))
+		Expect(monRF.Blocks[1].NoisePerMS).To(Equal(uint16(49)))
+		Expect(monRF.Blocks[1].AGCCnt).To(Equal(uint16(6669)))
+		Expect(monRF.Blocks[1].JamInd).To(Equal(uint8(2)))
+		Expect(monRF.Blocks[1].MagQ).To(Equal(uint8(149)))
+	})
+	It("should decode the timing messages", func() {
+		timeUTC, ok := decodeName(frames, "NAV-TIMEUTC").(*ubx.NavTimeUTC)
+		Expect(ok).To(BeTrue())
+		Expect(timeUTC.Nano).To(Equal(int32(-2518)))
+		Expect(timeUTC.Year).To(Equal(uint16(2023)))
+		Expect(timeUTC.Sec).To(Equal(uint8(47)))
+		Expect(timeUTC.Valid).To(Equal(uint8(0x37)))
+
+		timeLS, ok := decodeName(frames, "NAV-TIMELS").(*ubx.NavTimeLS)
+		Expect(ok).To(BeTrue())
+		Expect(timeLS.CurrLs).To(Equal(int8(18)))
+		Expect(timeLS.TimeToLsEvent).To(Equal(int32(-184204797)))
+		Expect(timeLS.DateOfLsGpsWn).To(Equal(uint16(1929)))
+		Expect(timeLS.Valid).To(Equal(uint8(3)))
+
+		timTP, ok := decodeName(frames, "TIM-TP").(*ubx.TimTP)
+		Expect(ok).To(BeTrue())
+		Expect(timTP.QErr).To(Equal(int32(-1544)))
+		Expect(timTP.Week).To(Equal(uint16(2266)))
+		Expect(timTP.Flags).To(Equal(uint8(0x1b)))
+	})
+	It("should decode MON-HW", func() {
+		monHW, ok := decodeName(frames, "MON-HW").(*ubx.MonHW)
+		Expect(ok).To(BeTrue())
+		Expect(monHW.PinVal).To(Equal(uint32(0xef6f)))
+		Expect(monHW.NoisePerMS).To(Equal(uint16(88)))
+		Expect(monHW.AGCCnt).To(Equal(uint16(6041)))
+		Expect(monHW.AStatus).To(Equal(uint8(2)))
+		Expect(monHW.UsedMask).To(Equal(uint32(0xfffff)))
+		Expect(monHW.JamInd).To(Equal(uint8(12)))
+	})
+	It("should decode MON-VER", func() {
+		verFrames, _, err := readFixture("test_files/mon_ver.ubx")
+		Expect(errors.Is(err, io.EOF)).To(BeTrue())
+
+		monVer, ok := decodeName(verFrames, "MON-VER").(*ubx.MonVer)
+		Expect(ok).To(BeTrue())
+		Expect(monVer.SWVersion).To(Equal("EXT CORE 1.00 (3fda8e)"))
+		Expect(monVer.HWVersion).To(Equal("00190000"))
+		Expect(monVer.Extensions).To(HaveLen(5))
+
+		fwVer, ok := monVer.Extension("FWVER=")
+		Expect(ok).To(BeTrue())
+		Expect(fwVer).To(Equal("TIM 2.20"))
+	})
+	It("should reject unknown messages and payloads of the wrong length", func() {
+		_, err := ubx.Decode(&ubx.Frame{Class: 0x01, ID: 0x99})
+		Expect(err).To(MatchError(ubx.ErrUnknownMessage))
+
+		_, err = ubx.Decode(&ubx.Frame{Class: ubx.ClassNAV, ID: ubx.IDNavClock, Payload: make([]byte, 19)})
+		Expect(err).To(MatchError("UBX-NAV-CLOCK payload is 19 bytes, expected 20"))
+
+		_, err = ubx.Decode(&ubx.Frame{Class: ubx.ClassNAV, ID: ubx.IDNavSat, Payload: []byte{0, 0, 0, 0, 1, 2, 0, 0}})
+		Expect(err).To(MatchError("UBX-NAV-SAT payload is 8 bytes, expected 32"))
+	})
+})
+
+func TestUBX(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "UBX Suite")
+}
@@ -16,6 +16,7 @@ const (
 	InvalidEnv
 	MissingInput
 	NotHandled
+	AlarmRaised
 )
 
 type InvalidEnvError struct {
@@ -63,6 +64,21 @@ func NewRequirementsNotMetError(err error) *RequirementsNotMetError {
 	return &RequirementsNotMetError{err: err}
 }
 
+type AlarmRaisedError struct {
+	err error
+}
+
+func (err AlarmRaisedError) Error() string {
+	return err.err.Error()
+}
+func (err AlarmRaisedError) Unwrap() error {
+	return err.err
+}
+
+func NewAlarmRaisedError(err error) *AlarmRaisedError {
+	return &AlarmRaisedError{err: err}
+}
+
 func checkError(err error) (exitCode, bool) {
 	var invalidEnv *InvalidEnvError
 	if errors.As(err, &invalidEnv) {
@@ -74,6 +90,11 @@ func checkError(err error) (exitCode, bool) {
 		return MissingInput, true
 	}
 
+	var alarmRaised *AlarmRaisedError
+	if errors.As(err, &alarmRaised) {
+		return AlarmRaised, true
+	}
+
 	return NotHandled, false
 }
 
@@ -66,6 +66,16 @@ func ParseTimestamp(timestamp string) (time.Time, error) {
 	return Epoch.Add(duration).UTC(), nil
 }
 
+// ClockOffset returns how far the local clock at localAt is ahead of the RFC3339 remote timestamp
+func ClockOffset(remoteTimestamp string, localAt time.Time) (time.Duration, error) {
+	remote, err := time.Parse(time.RFC3339Nano, remoteTimestamp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse timestamp %w", err)
+	}
+
+	return localAt.Sub(remote), nil
+}
+
 func RemoveTempFiles(dir string, filenames []string) {
 	dir = filepath.Clean(dir)
 
@@ -32,6 +32,7 @@ const (
 	hasGNSSDevicesOrdering
 	gnssConnectedToAntOrdering
 	gnssReceivingDataOrdering
+	gnssInterferenceOrdering
 	configuredForGrandMasterOrdering
 )
 
@@ -44,13 +45,37 @@ type VersionCheck struct {
 	order        int    `json:"-"`
 }
 
+// toSemver returns version in the form expected by semver, the numbers have their
+// leading zeros removed so that versions such as protocol version 22.00 can be compared
+func toSemver(version string) string {
+	core, preRelease, hasPreRelease := strings.Cut(version, "-")
+	numbers := strings.Split(core, ".")
+
+	for i, number := range numbers {
+		if trimmed := strings.TrimLeft(number, "0"); trimmed != number {
+			if trimmed == "" {
+				trimmed = "0"
+			}
+
+			numbers[i] = trimmed
+		}
+	}
+
+	ver := "v" + strings.Join(numbers, ".")
+	if hasPreRelease {
+		ver += "-" + preRelease
+	}
+
+	return ver
+}
+
 func (verCheck *VersionCheck) Verify() error {
-	ver := "v" + strings.ReplaceAll(verCheck.checkVersion, "_", "-")
+	ver := toSemver(strings.ReplaceAll(verCheck.checkVersion, "_", "-"))
 	if !semver.IsValid(ver) {
 		return fmt.Errorf("could not parse version %s", ver)
 	}
 
-	if semver.Compare(ver, "v"+verCheck.MinVersion) < 0 {
+	if semver.Compare(ver, toSemver(verCheck.MinVersion)) < 0 {
 		return utils.NewInvalidEnvError(
 			fmt.Errorf("unexpected version: %s < %s", verCheck.checkVersion, verCheck.MinVersion),
 		)
@@ -3,9 +3,11 @@
 package validations
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
 const (
@@ -13,19 +15,35 @@ const (
 	gnssDescription = "Verify GNSS firmware version"
 )
 
-var (
-	MinGNSSVersion = "2.20"
-)
+// unknownModelError is returned by the version checks of a receiver model with no known minimum versions
+func unknownModelError(module string) error {
+	return utils.NewInvalidEnvError(fmt.Errorf("no minimum versions are known for gnss module %s", module))
+}
 
-func NewGNSS(gnss *devices.GPSVersions) *VersionCheck {
-	parts := strings.Split(gnss.FirmwareVersion, " ")
+func NewGNSS(gnss *devices.GPSVersions) *VersionWithErrorCheck {
+	// The firmware version is reported with the firmware's type, for example "TIM 2.20"
+	parts := strings.Fields(gnss.FirmwareVersion)
+	checkVersion := ""
+
+	if len(parts) > 0 {
+		checkVersion = parts[len(parts)-1]
+	}
 
-	return &VersionCheck{
-		id:           gnssID,
-		Version:      gnss.FirmwareVersion,
-		checkVersion: parts[1],
-		MinVersion:   MinGNSSVersion,
-		description:  gnssDescription,
-		order:        gnssVersionOrdering,
+	check := &VersionWithErrorCheck{
+		VersionCheck: VersionCheck{
+			id:           gnssID,
+			Version:      gnss.FirmwareVersion,
+			checkVersion: checkVersion,
+			description:  gnssDescription,
+			order:        gnssVersionOrdering,
+		},
 	}
+
+	if model, ok := devices.GetGNSSModel(gnss.Module); ok {
+		check.MinVersion = model.MinFirmwareVersion
+	} else {
+		check.Error = unknownModelError(gnss.Module)
+	}
+
+	return check
 }
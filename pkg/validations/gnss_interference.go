@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package validations
+
+import (
+	"fmt"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/interference"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	gnssInterferenceID          = TGMSyncEnvPath + "/gnss/no-jamming/wpc/"
+	gnssInterferenceDescription = "Verify GNSS receiver is not being jammed"
+)
+
+type GNSSInterference struct {
+	Evidence []*interference.Evidence `json:"evidence"`
+}
+
+// Verify fails on a high jamming indicator or critical jamming, the other checks
+// only warn as a low or falling C/N0 is also seen with a poor view of the sky
+func (gnss *GNSSInterference) Verify() error {
+	for _, evidence := range gnss.Evidence {
+		if evidence.Kind != interference.KindJamming {
+			continue
+		}
+
+		if evidence.Check == interference.CheckJamInd || evidence.Severity == interference.SeverityCritical {
+			return utils.NewInvalidEnvError(
+				fmt.Errorf("GNSS receiver shows signs of jamming: %s is %v", evidence.Check, evidence.Value),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (gnss *GNSSInterference) GetID() string {
+	return gnssInterferenceID
+}
+
+func (gnss *GNSSInterference) GetDescription() string {
+	return gnssInterferenceDescription
+}
+
+func (gnss *GNSSInterference) GetData() any { //nolint:ireturn // data will vary for each validation
+	return gnss
+}
+
+func (gnss *GNSSInterference) GetOrder() int {
+	return gnssInterferenceOrdering
+}
+
+func NewGNSSInterference(gpsDetails *devices.GPSDetails) *GNSSInterference {
+	thresholds := interference.DefaultThresholds()
+
+	return &GNSSInterference{Evidence: interference.Check(gpsDetails, &thresholds)}
+}
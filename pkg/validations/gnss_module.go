@@ -10,19 +10,19 @@ import (
 )
 
 const (
-	expectedModuleName             = "ZED-F9T"
 	gnssModuleIsCorrect            = TGMEnvModelPath + "/gnss/"
 	gnssModuleIsCorrectDescription = "Verify GNSS module model"
 )
 
 type GNSSModule struct {
 	Module string `json:"module"`
+	Vendor string `json:"vendor,omitempty"`
 }
 
 func (gnssModule *GNSSModule) Verify() error {
-	if gnssModule.Module != expectedModuleName {
+	if gnssModule.Vendor == "" {
 		return utils.NewInvalidEnvError(
-			fmt.Errorf("reported gnss module is not %s", expectedModuleName),
+			fmt.Errorf("reported gnss module %s is not a supported receiver model", gnssModule.Module),
 		)
 	}
 
@@ -46,5 +46,10 @@ func (gnssModule *GNSSModule) GetOrder() int {
 }
 
 func NewGNSSModule(gpsdVer *devices.GPSVersions) *GNSSModule {
-	return &GNSSModule{Module: gpsdVer.Module}
+	gnssModule := &GNSSModule{Module: gpsdVer.Module}
+	if model, ok := devices.GetGNSSModel(gpsdVer.Module); ok {
+		gnssModule.Vendor = model.Vendor
+	}
+
+	return gnssModule
 }
@@ -9,16 +9,24 @@ import (
 const (
 	gnssProtID           = TGMEnvVerPath + "/gnss-protocol/"
 	gnssProtIDescription = "Verify GNSS protocol version"
-	MinProtoVersion      = "29.20"
 )
 
-func NewGNSSProtocol(gnss *devices.GPSVersions) *VersionCheck {
-	return &VersionCheck{
-		id:           gnssProtID,
-		Version:      gnss.ProtoVersion,
-		checkVersion: gnss.ProtoVersion,
-		MinVersion:   MinProtoVersion,
-		description:  gnssProtIDescription,
-		order:        gnssProtOrdering,
+func NewGNSSProtocol(gnss *devices.GPSVersions) *VersionWithErrorCheck {
+	check := &VersionWithErrorCheck{
+		VersionCheck: VersionCheck{
+			id:           gnssProtID,
+			Version:      gnss.ProtoVersion,
+			checkVersion: gnss.ProtoVersion,
+			description:  gnssProtIDescription,
+			order:        gnssProtOrdering,
+		},
 	}
+
+	if model, ok := devices.GetGNSSModel(gnss.Module); ok {
+		check.MinVersion = model.MinProtocolVersion
+	} else {
+		check.Error = unknownModelError(gnss.Module)
+	}
+
+	return check
 }
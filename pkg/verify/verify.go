@@ -44,12 +44,10 @@ func getDevInfoValidations(
 }
 
 func getGPSVersionValidations(
-	clientset *clients.Clientset,
-	ptpNodeName string,
+	ctx clients.ExecContext,
+	receiver devices.GNSSReceiver,
 ) []validations.Validation {
-	ctx, err := contexts.GetPTPDaemonContext(clientset, ptpNodeName)
-	utils.IfErrorExitOrPanic(err)
-	gnssVersions, err := devices.GetGPSVersions(ctx)
+	gnssVersions, err := receiver.GetVersions(ctx)
 	utils.IfErrorExitOrPanic(err)
 
 	return []validations.Validation{
@@ -62,20 +60,19 @@ func getGPSVersionValidations(
 }
 
 func getGPSStatusValidation(
-	clientset *clients.Clientset,
-	ptpNodeName string,
+	ctx clients.ExecContext,
+	receiver devices.GNSSReceiver,
+	checkJamming bool,
 ) []validations.Validation {
-	ctx, err := contexts.GetPTPDaemonContext(clientset, ptpNodeName)
-	utils.IfErrorExitOrPanic(err)
-
 	// If we need to do this for more validations then consider a generic
 	var (
 		antCheck   *validations.GNSSAntStatus
 		gpsDetails *devices.GPSDetails
+		err        error
 	)
 
 	for range antPowerRetries {
-		gpsDetails, err = devices.GetGPSNav(ctx)
+		gpsDetails, err = receiver.GetNav(ctx)
 		if err != nil {
 			continue
 		}
@@ -89,25 +86,75 @@ func getGPSStatusValidation(
 
 	utils.IfErrorExitOrPanic(err)
 
-	return []validations.Validation{
+	checks := []validations.Validation{
 		antCheck,
 		validations.NewGNSSNavStatus(gpsDetails),
 	}
+
+	if checkJamming {
+		checks = append(checks, validations.NewGNSSInterference(gpsDetails))
+	}
+
+	return checks
 }
 
-func getValidations(interfaceName, ptpNodeName, kubeConfig, clockType string) []validations.Validation {
-	checks := make([]validations.Validation, 0)
-	clientset, err := clients.GetClientset(kubeConfig)
+// getGNSSValidations finds the receiver once for all of the GNSS validations. When it can not be found
+// the default receiver is used like the GNSS collector does, so recordings of older versions can be replayed.
+func getGNSSValidations(
+	clientset *clients.Clientset,
+	ptpNodeName string,
+	checkJamming bool,
+) []validations.Validation {
+	ctx, err := contexts.GetPTPDaemonContext(clientset, ptpNodeName)
 	utils.IfErrorExitOrPanic(err)
 
+	receiver, err := devices.DetectGNSSReceiver(ctx)
+	if err != nil {
+		log.Warnf("assuming a %s receiver using protocol version %s: %s",
+			devices.UBloxVendor, devices.DefaultUBXProtocolVersion, err.Error())
+
+		receiver, err = devices.DefaultGNSSReceiver()
+		utils.IfErrorExitOrPanic(err)
+	}
+
+	checks := getGPSVersionValidations(ctx, receiver)
+
+	return append(checks, getGPSStatusValidation(ctx, receiver, checkJamming)...)
+}
+
+func getValidations(
+	interfaceName, ptpNodeName, kubeConfig, clockType string,
+	checkJamming bool,
+) []validations.Validation {
+	checks := make([]validations.Validation, 0)
+	replaying := clients.IsReplaying()
+
+	var clientset *clients.Clientset
+
+	if !replaying {
+		var err error
+
+		clientset, err = clients.GetClientset(kubeConfig)
+		utils.IfErrorExitOrPanic(err)
+	}
+
 	checks = append(checks, getDevInfoValidations(clientset, interfaceName, ptpNodeName, clockType)...)
 
 	// Skip GPS/GNSS validations for Boundary Clock
 	if clockType == constants.ClockTypeGM {
-		checks = append(checks, getGPSVersionValidations(clientset, ptpNodeName)...)
-		checks = append(checks, getGPSStatusValidation(clientset, ptpNodeName)...)
+		checks = append(checks, getGNSSValidations(clientset, ptpNodeName, checkJamming)...)
+	}
+
+	// The remaining validations query the kube API which is not recorded
+	if replaying {
+		log.Warning("Skipping cluster API validations as there is no cluster when replaying")
+		return checks
+	}
+
+	if clockType == constants.ClockTypeGM {
 		checks = append(checks, validations.NewIsGrandMaster(clientset))
 	}
+
 	// Common validations for both GM and BC
 	checks = append(
 		checks,
@@ -119,7 +166,10 @@ func getValidations(interfaceName, ptpNodeName, kubeConfig, clockType string) []
 }
 
 func reportAnalyserJSON(results []*ValidationResult) {
-	callback, err := callbacks.SetupCallback("-", callbacks.AnalyserJSON)
+	callback, err := callbacks.SetupCallback(
+		[]callbacks.Sink{{Filename: "-", Format: callbacks.AnalyserJSON}},
+		callbacks.FileOptions{},
+	)
 	utils.IfErrorExitOrPanic(err)
 
 	sort.Slice(results, func(i, j int) bool {
@@ -191,8 +241,10 @@ func report(results []*ValidationResult, useAnalyserJSON bool) {
 	}
 }
 
-func Verify(interfaceName, kubeConfig string, useAnalyserJSON bool, nodeName, clockType string) {
-	checks := getValidations(interfaceName, nodeName, kubeConfig, clockType)
+// Verify runs the validations of the environment and reports their results,
+// checkJamming adds the validation that the GNSS receiver is not being jammed
+func Verify(interfaceName, kubeConfig string, useAnalyserJSON bool, nodeName, clockType string, checkJamming bool) {
+	checks := getValidations(interfaceName, nodeName, kubeConfig, clockType, checkJamming)
 
 	results := make([]*ValidationResult, 0)
 	for _, check := range checks {